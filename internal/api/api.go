@@ -0,0 +1,99 @@
+package api
+
+import (
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// API serves the versioned JSON API. Entities are read and written using the
+// json tags on the model types; persons are always returned as
+// model.PersonOutput so password hashes never leave the server.
+type API struct {
+	DB *db.FirestoreDB
+}
+
+// Page is the response body for list endpoints. NextPageToken is passed back
+// as the startAfter query parameter to fetch the following page.
+type Page[T any] struct {
+	Data          []T    `json:"data"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// Register adds the API routes to g, which is expected to be mounted at /api/v1
+// with authentication middleware already applied.
+func (api *API) Register(g *echo.Group) {
+	g.GET("/households", api.ListHouseholds)
+	g.POST("/households", api.CreateHousehold)
+	g.GET("/households/:id", api.GetHousehold)
+	g.PUT("/households/:id", api.UpdateHousehold)
+	g.DELETE("/households/:id", api.DeleteHousehold)
+
+	g.GET("/persons", api.ListPersons)
+	g.POST("/persons", api.CreatePerson)
+	g.GET("/persons/:id", api.GetPerson)
+	g.PUT("/persons/:id", api.UpdatePerson)
+	g.DELETE("/persons/:id", api.DeletePerson)
+
+	g.GET("/visits", api.ListVisits)
+	g.POST("/visits", api.CreateVisit)
+	g.GET("/visits/:id", api.GetVisit)
+	g.PUT("/visits/:id", api.UpdateVisit)
+	g.DELETE("/visits/:id", api.DeleteVisit)
+
+	g.GET("/items", api.ListItems)
+	g.POST("/items", api.CreateItem)
+	g.GET("/items/:id", api.GetItem)
+	g.PUT("/items/:id", api.UpdateItem)
+	g.DELETE("/items/:id", api.DeleteItem)
+
+	g.GET("/reports/served", api.GetServedCounts)
+}
+
+// pageParams reads the pageSize and startAfter query parameters.
+func pageParams(c echo.Context) (int, string, model.ValidationErrors) {
+	pageSize := defaultPageSize
+	if v := c.QueryParam("pageSize"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return 0, "", model.ValidationErrors{{Field: "pageSize", Type: "invalid", Message: "invalid_page_size"}}
+		}
+		pageSize = n
+	}
+	return pageSize, c.QueryParam("startAfter"), nil
+}
+
+// bind decodes the JSON request body into v.
+func bind(c echo.Context, v any) model.ValidationErrors {
+	if err := (&echo.DefaultBinder{}).BindBody(c, v); err != nil {
+		return model.ValidationErrors{{Type: "invalid", Message: "invalid_body"}}
+	}
+	return nil
+}
+
+func errorJSON(c echo.Context, status int, errs model.ValidationErrors) error {
+	return c.JSON(status, model.ErrorResponse{Errors: errs})
+}
+
+func validationFailed(c echo.Context, errs model.ValidationErrors) error {
+	return errorJSON(c, http.StatusUnprocessableEntity, errs)
+}
+
+// storageError maps an error from the db package to a response, hiding the
+// details of anything other than a missing document from the client.
+func storageError(c echo.Context, err error) error {
+	if db.IsNotFound(err) {
+		return c.JSON(http.StatusNotFound, model.NewErrorResponse("not_found", "not_found"))
+	}
+	log.Error().Err(err).Str("path", c.Path()).Msg("API storage error")
+	return c.JSON(http.StatusInternalServerError, model.NewErrorResponse("internal", "internal_error"))
+}
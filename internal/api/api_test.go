@@ -0,0 +1,276 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		os.Setenv("FIRESTORE_EMULATOR_HOST", "localhost:8080")
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// newTestServer serves the API at /api/v1 from the Firestore emulator, without
+// the auth middleware main adds.
+func newTestServer(t *testing.T) (*echo.Echo, *db.FirestoreDB) {
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "test-project")
+	if err != nil {
+		t.Fatalf("Failed to create Firestore client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	database := db.NewFirestoreDB(client)
+
+	e := echo.New()
+	(&API{DB: database}).Register(e.Group("/api/v1"))
+	return e, database
+}
+
+// do sends a request with body encoded as JSON, if not nil, and decodes the
+// response into out, if not nil.
+func do(t *testing.T, e *echo.Echo, method string, path string, body any, out any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatalf("Failed to encode request: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: failed to decode response %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func TestItemCRUD(t *testing.T) {
+	e, _ := newTestServer(t)
+
+	var created model.Item
+	rec := do(t, e, http.MethodPost, "/api/v1/items", model.Item{Name: "Rice", Points: 2}, &created)
+	if rec.Code != http.StatusCreated || created.Id == "" || created.Name != "Rice" {
+		t.Fatalf("Create: %d %s", rec.Code, rec.Body.String())
+	}
+	path := "/api/v1/items/" + created.Id
+
+	var got model.Item
+	if rec := do(t, e, http.MethodGet, path, nil, &got); rec.Code != http.StatusOK || got != created {
+		t.Errorf("Get: %d %+v, want %+v", rec.Code, got, created)
+	}
+
+	// the ID in the path wins over any ID in the body
+	update := model.Item{Id: "other", Name: "Brown rice", Points: 3}
+	var updated model.Item
+	if rec := do(t, e, http.MethodPut, path, update, &updated); rec.Code != http.StatusOK || updated.Id != created.Id {
+		t.Errorf("Update: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(t, e, http.MethodGet, path, nil, &got); rec.Code != http.StatusOK || got.Name != "Brown rice" || got.Points != 3 {
+		t.Errorf("Get after update: %d %+v", rec.Code, got)
+	}
+
+	var page Page[model.Item]
+	if rec := do(t, e, http.MethodGet, "/api/v1/items?pageSize=500", nil, &page); rec.Code != http.StatusOK {
+		t.Fatalf("List: %d %s", rec.Code, rec.Body.String())
+	}
+	found := false
+	for _, item := range page.Data {
+		found = found || item.Id == created.Id
+	}
+	if !found {
+		t.Errorf("List: item %s missing from %d items", created.Id, len(page.Data))
+	}
+
+	if rec := do(t, e, http.MethodDelete, path, nil, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("Delete: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(t, e, http.MethodGet, path, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Get after delete: %d, want 404", rec.Code)
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	e, _ := newTestServer(t)
+	for _, name := range []string{"Beans", "Oats"} {
+		if rec := do(t, e, http.MethodPost, "/api/v1/items", model.Item{Name: name}, nil); rec.Code != http.StatusCreated {
+			t.Fatalf("Create: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	var first, second Page[model.Item]
+	if rec := do(t, e, http.MethodGet, "/api/v1/items?pageSize=1", nil, &first); rec.Code != http.StatusOK {
+		t.Fatalf("List: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(first.Data) != 1 || first.NextPageToken != first.Data[0].Id {
+		t.Fatalf("First page: %+v", first)
+	}
+	if rec := do(t, e, http.MethodGet, "/api/v1/items?pageSize=1&startAfter="+first.NextPageToken, nil, &second); rec.Code != http.StatusOK {
+		t.Fatalf("List: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(second.Data) != 1 || second.Data[0].Id >= first.Data[0].Id {
+		t.Errorf("Second page %+v doesn't follow %+v", second.Data, first.Data)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	e, _ := newTestServer(t)
+	missing := ulid.Make().String()
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/households/" + missing},
+		{http.MethodGet, "/api/v1/persons/" + missing},
+		{http.MethodGet, "/api/v1/visits/" + missing},
+		{http.MethodGet, "/api/v1/items/" + missing},
+		{http.MethodPut, "/api/v1/items/" + missing},
+		{http.MethodDelete, "/api/v1/items/" + missing},
+	} {
+		var body model.ErrorResponse
+		rec := do(t, e, req.method, req.path, model.Item{Name: "Rice"}, &body)
+		if rec.Code != http.StatusNotFound || len(body.Errors) != 1 || body.Errors[0].Message != "not_found" {
+			t.Errorf("%s %s: %d %s, want 404 not_found", req.method, req.path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestValidationFailed(t *testing.T) {
+	e, _ := newTestServer(t)
+
+	for _, req := range []struct {
+		path  string
+		body  any
+		field string
+	}{
+		{"/api/v1/items", model.Item{Points: 1}, "name"},
+		{"/api/v1/persons", model.PersonInput{Person: model.Person{PersonCommon: model.PersonCommon{FirstName: "Ana", LastName: "Lopez"}}}, "email"},
+		{"/api/v1/households", model.Household{Head: model.Person{PersonCommon: model.PersonCommon{LastName: "Lopez"}}}, "head.firstName"},
+	} {
+		var body model.ErrorResponse
+		rec := do(t, e, http.MethodPost, req.path, req.body, &body)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("POST %s: %d %s, want 422", req.path, rec.Code, rec.Body.String())
+			continue
+		}
+		found := false
+		for _, err := range body.Errors {
+			found = found || err.Field == req.field
+		}
+		if !found {
+			t.Errorf("POST %s: no error for %s in %+v", req.path, req.field, body.Errors)
+		}
+	}
+}
+
+func TestUpdatePersonKeepsPassword(t *testing.T) {
+	e, database := newTestServer(t)
+	ctx := context.Background()
+
+	input := model.PersonInput{
+		Person:   model.Person{PersonCommon: model.PersonCommon{FirstName: "Ana", LastName: "Lopez", Email: "Ana.Lopez@example.com"}},
+		Password: "first-secret",
+	}
+	var created model.PersonOutput
+	rec := do(t, e, http.MethodPost, "/api/v1/persons", input, &created)
+	if rec.Code != http.StatusCreated || created.Id == "" || created.Email != "ana.lopez@example.com" {
+		t.Fatalf("Create: %d %s", rec.Code, rec.Body.String())
+	}
+	// Output leaves the hash out of every response
+	if strings.Contains(strings.ToLower(rec.Body.String()), "password") {
+		t.Errorf("Create response has a password field: %s", rec.Body.String())
+	}
+	stored, err := database.GetPerson(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Failed to get person: %v", err)
+	}
+	hash := stored.PasswordHash
+
+	input.Password = ""
+	input.Phone = "802-555-0100"
+	if rec := do(t, e, http.MethodPut, "/api/v1/persons/"+created.Id, input, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Update: %d %s", rec.Code, rec.Body.String())
+	}
+	stored, err = database.GetPerson(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Failed to get person: %v", err)
+	}
+	if stored.PasswordHash != hash || stored.Phone != "802-555-0100" {
+		t.Errorf("Update without a password: hash kept %v, phone %q", stored.PasswordHash == hash, stored.Phone)
+	}
+
+	input.Password = "second-secret"
+	if rec := do(t, e, http.MethodPut, "/api/v1/persons/"+created.Id, input, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Update: %d %s", rec.Code, rec.Body.String())
+	}
+	stored, err = database.GetPerson(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Failed to get person: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("second-secret")) != nil {
+		t.Error("Update with a password didn't replace the hash")
+	}
+}
+
+func TestPageParams(t *testing.T) {
+	for query, want := range map[string]int{
+		"":               defaultPageSize,
+		"pageSize=1":     1,
+		"pageSize=500":   maxPageSize,
+		"pageSize=0":     0,
+		"pageSize=501":   0,
+		"pageSize=-1":    0,
+		"pageSize=lots":  0,
+		"startAfter=abc": defaultPageSize,
+	} {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/items?"+query, nil), httptest.NewRecorder())
+		size, startAfter, errs := pageParams(c)
+		if size != want || errs.HasErrors() != (want == 0) {
+			t.Errorf("%q: pageParams() = %d, %v", query, size, errs)
+		}
+		if query == "startAfter=abc" && startAfter != "abc" {
+			t.Errorf("%q: startAfter = %q", query, startAfter)
+		}
+	}
+}
+
+func TestStorageError(t *testing.T) {
+	for _, tt := range []struct {
+		err    error
+		status int
+	}{
+		{status.Error(codes.NotFound, "no such document"), http.StatusNotFound},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	} {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/items/x", nil), rec)
+		if err := storageError(c, tt.err); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != tt.status || strings.Contains(rec.Body.String(), tt.err.Error()) {
+			t.Errorf("storageError(%v): %d %s", tt.err, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"foodbank/internal/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+func (api *API) ListHouseholds(c echo.Context) error {
+	pageSize, startAfter, errs := pageParams(c)
+	if errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	households, next, err := api.DB.GetHouseholds(c.Request().Context(), pageSize, startAfter)
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, Page[model.Household]{Data: nonNil(households), NextPageToken: next})
+}
+
+func (api *API) GetHousehold(c echo.Context) error {
+	household, err := api.DB.GetHouseholdByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, household)
+}
+
+func (api *API) CreateHousehold(c echo.Context) error {
+	var household model.Household
+	if errs := bind(c, &household); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+	if errs := household.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	household.Id = ulid.Make().String()
+	if err := api.DB.PutHousehold(c.Request().Context(), household); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusCreated, household)
+}
+
+func (api *API) UpdateHousehold(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetHouseholdByID(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+
+	var household model.Household
+	if errs := bind(c, &household); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+	household.Id = id
+	if errs := household.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	if err := api.DB.PutHousehold(ctx, household); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, household)
+}
+
+func (api *API) DeleteHousehold(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetHouseholdByID(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	if err := api.DB.DeleteHousehold(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// nonNil makes empty lists encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"foodbank/internal/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+func (api *API) ListItems(c echo.Context) error {
+	pageSize, startAfter, errs := pageParams(c)
+	if errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	items, next, err := api.DB.GetItems(c.Request().Context(), pageSize, startAfter)
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, Page[model.Item]{Data: nonNil(items), NextPageToken: next})
+}
+
+func (api *API) GetItem(c echo.Context) error {
+	item, err := api.DB.GetItem(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+func (api *API) CreateItem(c echo.Context) error {
+	var item model.Item
+	if errs := bind(c, &item); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+	if errs := item.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	item.Id = ulid.Make().String()
+	if err := api.DB.PutItem(c.Request().Context(), item); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusCreated, item)
+}
+
+func (api *API) UpdateItem(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetItem(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+
+	var item model.Item
+	if errs := bind(c, &item); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+	item.Id = id
+	if errs := item.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	if err := api.DB.PutItem(ctx, item); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+func (api *API) DeleteItem(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetItem(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	if err := api.DB.DeleteItem(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
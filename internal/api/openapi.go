@@ -0,0 +1,269 @@
+package api
+
+import (
+	"foodbank/internal/model"
+	"foodbank/internal/report"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// operation describes one API endpoint for the OpenAPI document. Request and
+// Response hold zero values of the body types; their schemas are derived from
+// the struct json tags so the document follows the model package.
+type operation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Request  any
+	Response any
+	List     bool
+	Status   int
+	Query    []queryParam
+}
+
+// queryParam is a string query parameter other than the paging parameters.
+type queryParam struct {
+	Name        string
+	Required    bool
+	Description string
+}
+
+// operations must list every route added by API.Register. TestOpenAPIMatchesRoutes
+// fails when the two disagree.
+var operations = []operation{
+	{Method: http.MethodGet, Path: "/households", Summary: "List households", Tag: "households", Response: model.Household{}, List: true, Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/households", Summary: "Create a household", Tag: "households", Request: model.Household{}, Response: model.Household{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/households/{id}", Summary: "Get a household", Tag: "households", Response: model.Household{}, Status: http.StatusOK},
+	{Method: http.MethodPut, Path: "/households/{id}", Summary: "Replace a household", Tag: "households", Request: model.Household{}, Response: model.Household{}, Status: http.StatusOK},
+	{Method: http.MethodDelete, Path: "/households/{id}", Summary: "Delete a household (restorable by staff until purged)", Tag: "households", Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/persons", Summary: "List persons", Tag: "persons", Response: model.PersonOutput{}, List: true, Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/persons", Summary: "Create a person", Tag: "persons", Request: model.PersonInput{}, Response: model.PersonOutput{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/persons/{id}", Summary: "Get a person", Tag: "persons", Response: model.PersonOutput{}, Status: http.StatusOK},
+	{Method: http.MethodPut, Path: "/persons/{id}", Summary: "Replace a person", Tag: "persons", Request: model.PersonInput{}, Response: model.PersonOutput{}, Status: http.StatusOK},
+	{Method: http.MethodDelete, Path: "/persons/{id}", Summary: "Delete a person", Tag: "persons", Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/visits", Summary: "List food bank visits", Tag: "visits", Response: model.FoodBankVisit{}, List: true, Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/visits", Summary: "Record a food bank visit", Tag: "visits", Request: model.FoodBankVisit{}, Response: model.FoodBankVisit{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/visits/{id}", Summary: "Get a food bank visit", Tag: "visits", Response: model.FoodBankVisit{}, Status: http.StatusOK},
+	{Method: http.MethodPut, Path: "/visits/{id}", Summary: "Replace a food bank visit", Tag: "visits", Request: model.FoodBankVisit{}, Response: model.FoodBankVisit{}, Status: http.StatusOK},
+	{Method: http.MethodDelete, Path: "/visits/{id}", Summary: "Delete a food bank visit", Tag: "visits", Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/items", Summary: "List items", Tag: "items", Response: model.Item{}, List: true, Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/items", Summary: "Create an item", Tag: "items", Request: model.Item{}, Response: model.Item{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/items/{id}", Summary: "Get an item", Tag: "items", Response: model.Item{}, Status: http.StatusOK},
+	{Method: http.MethodPut, Path: "/items/{id}", Summary: "Replace an item", Tag: "items", Request: model.Item{}, Response: model.Item{}, Status: http.StatusOK},
+	{Method: http.MethodDelete, Path: "/items/{id}", Summary: "Delete an item", Tag: "items", Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/reports/served", Summary: "Unduplicated households and individuals served", Tag: "reports",
+		Response: report.ServedCounts{}, Status: http.StatusOK, Query: []queryParam{
+			{Name: "from", Required: true, Description: "First day of the range, YYYY-MM-DD"},
+			{Name: "to", Required: true, Description: "Last day of the range, YYYY-MM-DD"},
+			{Name: "site", Description: "Limit to visits at this food bank ID"},
+		}},
+}
+
+// ServeOpenAPI returns the OpenAPI 3 document for the API.
+func ServeOpenAPI(c echo.Context) error {
+	return c.JSON(http.StatusOK, OpenAPISpec())
+}
+
+// OpenAPISpec builds the OpenAPI 3 document describing every operation.
+func OpenAPISpec() map[string]any {
+	g := &schemaGen{schemas: map[string]any{}}
+	errorRef := g.schema(reflect.TypeOf(model.ErrorResponse{}))
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content":     map[string]any{"application/json": map[string]any{"schema": errorRef}},
+		}
+	}
+
+	paths := map[string]any{}
+	for _, op := range operations {
+		item, ok := paths[op.Path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+
+		responses := map[string]any{
+			"401": errorResponse("Missing or invalid API token"),
+			"403": errorResponse("API token not scoped for this resource or method"),
+			"500": errorResponse("Internal error"),
+		}
+		if op.Response == nil {
+			responses[strconv.Itoa(op.Status)] = map[string]any{"description": http.StatusText(op.Status)}
+		} else {
+			schema := g.schema(reflect.TypeOf(op.Response))
+			if op.List {
+				schema = map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"data":          map[string]any{"type": "array", "items": schema},
+						"nextPageToken": map[string]any{"type": "string"},
+					},
+				}
+			}
+			responses[strconv.Itoa(op.Status)] = map[string]any{
+				"description": http.StatusText(op.Status),
+				"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+			}
+		}
+
+		var params []any
+		if strings.Contains(op.Path, "{id}") {
+			params = append(params, map[string]any{
+				"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+			})
+			responses["404"] = errorResponse("Not found")
+		}
+		for _, q := range op.Query {
+			params = append(params, map[string]any{
+				"name": q.Name, "in": "query", "required": q.Required, "description": q.Description,
+				"schema": map[string]any{"type": "string"},
+			})
+		}
+		if op.Query != nil {
+			responses["400"] = errorResponse("Invalid query parameters")
+		}
+		if op.List {
+			params = append(params,
+				map[string]any{"name": "pageSize", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "maximum": maxPageSize, "default": defaultPageSize}},
+				map[string]any{"name": "startAfter", "in": "query", "schema": map[string]any{"type": "string"}},
+			)
+			responses["400"] = errorResponse("Invalid query parameters")
+		}
+
+		o := map[string]any{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"tags":        []string{op.Tag},
+			"responses":   responses,
+		}
+		if params != nil {
+			o["parameters"] = params
+		}
+		if op.Request != nil {
+			o["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Request))}},
+			}
+			responses["400"] = errorResponse("Malformed request body")
+			responses["422"] = errorResponse("Validation failed")
+		}
+		item[strings.ToLower(op.Method)] = o
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Food Bank API",
+			"version": "1",
+		},
+		"servers":  []any{map[string]any{"url": "/api/v1"}},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "API token created by an admin at /admin/tokens",
+				},
+			},
+		},
+	}
+}
+
+func operationID(op operation) string {
+	verbs := map[string]string{
+		http.MethodGet:    "get",
+		http.MethodPost:   "create",
+		http.MethodPut:    "update",
+		http.MethodDelete: "delete",
+	}
+	verb := verbs[op.Method]
+	if op.List {
+		verb = "list"
+	}
+	// name operations on sub-resources after the last path segment
+	name := op.Tag
+	if segments := strings.Split(strings.Trim(op.Path, "/"), "/"); len(segments) > 1 && !strings.HasPrefix(segments[1], "{") {
+		name += strings.ToUpper(segments[len(segments)-1][:1]) + segments[len(segments)-1][1:]
+	}
+	return verb + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// schemaGen derives JSON schemas from Go types, registering named structs
+// under components/schemas and returning $ref objects for them.
+type schemaGen struct {
+	schemas map[string]any
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = map[string]any{} // guards against recursive types
+			g.schemas[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	g.addProperties(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+// addProperties follows encoding/json: unexported and "-" fields are skipped
+// and untagged embedded structs have their fields promoted.
+func (g *schemaGen) addProperties(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.addProperties(f.Type, props)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestOpenAPIMatchesRoutes fails when a route is registered without being
+// described in the OpenAPI document, or the document describes a route that
+// no longer exists.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	e := echo.New()
+	(&API{}).Register(e.Group("/api/v1"))
+
+	registered := map[string]bool{}
+	for _, r := range e.Routes() {
+		path, ok := strings.CutPrefix(r.Path, "/api/v1")
+		if !ok {
+			continue
+		}
+		registered[r.Method+" "+echoPathToOpenAPI(path)] = true
+	}
+
+	documented := map[string]bool{}
+	paths := OpenAPISpec()["paths"].(map[string]any)
+	for path, item := range paths {
+		for method := range item.(map[string]any) {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for _, route := range sortedKeys(registered) {
+		if !documented[route] {
+			t.Errorf("Route %s is registered but missing from the OpenAPI document", route)
+		}
+	}
+	for _, route := range sortedKeys(documented) {
+		if !registered[route] {
+			t.Errorf("Route %s is in the OpenAPI document but not registered", route)
+		}
+	}
+}
+
+func TestOpenAPISchemasResolve(t *testing.T) {
+	spec := OpenAPISpec()
+	b, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("Failed to marshal spec: %v", err)
+	}
+
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, ref := range findRefs(string(b)) {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("Unresolved schema reference %s", ref)
+		}
+	}
+
+	person := schemas["PersonOutput"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := person["passwordHash"]; ok {
+		t.Errorf("PersonOutput schema must not expose the password hash")
+	}
+	if _, ok := person["email"]; !ok {
+		t.Errorf("Expected PersonOutput schema to include promoted field email")
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	e := echo.New()
+	e.GET("/api/v1/openapi.json", ServeOpenAPI)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("Expected openapi version 3.0.3, got %v", doc["openapi"])
+	}
+}
+
+func echoPathToOpenAPI(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if name, ok := strings.CutPrefix(p, ":"); ok {
+			parts[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func findRefs(doc string) []string {
+	var refs []string
+	for _, part := range strings.Split(doc, `"$ref":"`)[1:] {
+		ref, _, _ := strings.Cut(part, `"`)
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
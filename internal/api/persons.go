@@ -0,0 +1,102 @@
+package api
+
+import (
+	"foodbank/internal/model"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (api *API) ListPersons(c echo.Context) error {
+	pageSize, startAfter, errs := pageParams(c)
+	if errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	persons, next, err := api.DB.GetPersonsPage(c.Request().Context(), pageSize, startAfter)
+	if err != nil {
+		return storageError(c, err)
+	}
+	out := make([]model.PersonOutput, len(persons))
+	for i, p := range persons {
+		out[i] = p.Output()
+	}
+	return c.JSON(http.StatusOK, Page[model.PersonOutput]{Data: out, NextPageToken: next})
+}
+
+func (api *API) GetPerson(c echo.Context) error {
+	person, err := api.DB.GetPerson(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, person.Output())
+}
+
+func (api *API) CreatePerson(c echo.Context) error {
+	var input model.PersonInput
+	if errs := bind(c, &input); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	person := input.Person
+	person.Id = ulid.Make().String()
+	return api.savePerson(c, person, input.Password, http.StatusCreated)
+}
+
+func (api *API) UpdatePerson(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	existing, err := api.DB.GetPerson(ctx, id)
+	if err != nil {
+		return storageError(c, err)
+	}
+
+	var input model.PersonInput
+	if errs := bind(c, &input); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	person := input.Person
+	person.Id = id
+	person.PasswordHash = existing.PasswordHash
+	return api.savePerson(c, person, input.Password, http.StatusOK)
+}
+
+// savePerson validates and stores person, replacing its password hash when a
+// new password is supplied.
+func (api *API) savePerson(c echo.Context, person model.Person, password string, status int) error {
+	person.Email = strings.ToLower(person.Email)
+	if errs := person.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return storageError(c, err)
+		}
+		person.PasswordHash = string(hash)
+	}
+
+	if err := api.DB.PutPerson(c.Request().Context(), person); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(status, person.Output())
+}
+
+func (api *API) DeletePerson(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetPerson(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	if err := api.DB.DeletePerson(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
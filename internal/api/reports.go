@@ -0,0 +1,35 @@
+package api
+
+import (
+	"foodbank/internal/model"
+	"foodbank/internal/report"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetServedCounts returns unduplicated households and individuals served
+// between the from and to dates, overall and per site.
+func (api *API) GetServedCounts(c echo.Context) error {
+	var errs model.ValidationErrors
+	from, err := time.Parse("2006-01-02", c.QueryParam("from"))
+	if err != nil {
+		errs = append(errs, model.ValidationError{Field: "from", Type: "invalid", Message: "invalid_date"})
+	}
+	to, err := time.Parse("2006-01-02", c.QueryParam("to"))
+	if err != nil {
+		errs = append(errs, model.ValidationError{Field: "to", Type: "invalid", Message: "invalid_date"})
+	} else if to.Before(from) {
+		errs = append(errs, model.ValidationError{Field: "to", Type: "invalid", Message: "date_before_from"})
+	}
+	if errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	data, err := report.Load(c.Request().Context(), api.DB, from, to, c.QueryParam("site"))
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, report.CountServed(data))
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"foodbank/internal/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+func (api *API) ListVisits(c echo.Context) error {
+	pageSize, startAfter, errs := pageParams(c)
+	if errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+
+	visits, next, err := api.DB.GetFoodBankVisits(c.Request().Context(), pageSize, startAfter)
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, Page[model.FoodBankVisit]{Data: nonNil(visits), NextPageToken: next})
+}
+
+func (api *API) GetVisit(c echo.Context) error {
+	visit, err := api.DB.GetFoodBankVisit(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, visit)
+}
+
+func (api *API) CreateVisit(c echo.Context) error {
+	var visit model.FoodBankVisit
+	if errs := bind(c, &visit); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+	if errs := visit.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	visit.Id = ulid.Make().String()
+	if err := api.DB.PutFoodBankVisit(c.Request().Context(), visit); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusCreated, visit)
+}
+
+func (api *API) UpdateVisit(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetFoodBankVisit(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+
+	var visit model.FoodBankVisit
+	if errs := bind(c, &visit); errs.HasErrors() {
+		return errorJSON(c, http.StatusBadRequest, errs)
+	}
+	visit.Id = id
+	if errs := visit.Validate(); errs.HasErrors() {
+		return validationFailed(c, errs)
+	}
+
+	if err := api.DB.PutFoodBankVisit(ctx, visit); err != nil {
+		return storageError(c, err)
+	}
+	return c.JSON(http.StatusOK, visit)
+}
+
+func (api *API) DeleteVisit(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if _, err := api.DB.GetFoodBankVisit(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	if err := api.DB.DeleteFoodBankVisit(ctx, id); err != nil {
+		return storageError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+func (db *FirestoreDB) PutAPIToken(ctx context.Context, token model.APIToken) error {
+	err := audited(ctx, db, "apitokens", []write[model.APIToken]{set(token.Id, token)})
+	if err != nil {
+		return fmt.Errorf("error saving API token: %w", err)
+	}
+	return nil
+}
+
+// GetAPITokens retrieves all API tokens, newest first.
+func (db *FirestoreDB) GetAPITokens(ctx context.Context) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	iter := db.Client.Collection("apitokens").OrderBy("Id", firestore.Desc).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving API tokens: %w", err)
+		}
+
+		var token model.APIToken
+		if err := doc.DataTo(&token); err != nil {
+			return nil, fmt.Errorf("error parsing API token data: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// GetAPITokenByHash retrieves the token with the given hash, or nil if none exists.
+func (db *FirestoreDB) GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	iter := db.Client.Collection("apitokens").Where("Hash", "==", hash).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving API token by hash: %w", err)
+	}
+
+	var token model.APIToken
+	if err := doc.DataTo(&token); err != nil {
+		return nil, fmt.Errorf("error parsing API token data: %w", err)
+	}
+
+	return &token, nil
+}
+
+// TouchAPIToken records that the token was used at t. Use is not written to
+// the audit log.
+func (db *FirestoreDB) TouchAPIToken(ctx context.Context, id string, t time.Time) error {
+	_, err := db.Client.Collection("apitokens").Doc(id).Update(ctx, []firestore.Update{{Path: "LastUsedAt", Value: t}})
+	if err != nil {
+		return fmt.Errorf("error updating API token with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// RevokeAPIToken marks the token as revoked. Revoked tokens are kept so they
+// still appear in the admin list.
+func (db *FirestoreDB) RevokeAPIToken(ctx context.Context, id string, t time.Time) error {
+	doc, err := db.Client.Collection("apitokens").Doc(id).Get(ctx)
+	if err != nil {
+		return fmt.Errorf("error revoking API token with ID %s: %w", id, err)
+	}
+	var token model.APIToken
+	if err := doc.DataTo(&token); err != nil {
+		return fmt.Errorf("error parsing API token data for ID %s: %w", id, err)
+	}
+	token.RevokedAt = t
+
+	err = audited(ctx, db, "apitokens", []write[model.APIToken]{set(id, token)})
+	if err != nil {
+		return fmt.Errorf("error revoking API token with ID %s: %w", id, err)
+	}
+	return nil
+}
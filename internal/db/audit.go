@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context whose writes are attributed to actor in the
+// audit log.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored by WithActor, or "anonymous".
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+
+// write is a pending change to one document. A nil value deletes it.
+type write[T any] struct {
+	id    string
+	value *T
+}
+
+func set[T any](id string, value T) write[T] {
+	return write[T]{id: id, value: &value}
+}
+
+func del[T any](id string) write[T] {
+	return write[T]{id: id}
+}
+
+// MaxAuditedWrites is the number of changes applied per transaction. Firestore
+// allows 500 writes per transaction and each change also writes an audit entry.
+const MaxAuditedWrites = 250
+
+// audited applies writes to collection name, appending an audit entry for each
+// document that actually changes. Each run of MaxAuditedWrites changes is
+// applied atomically together with its audit entries.
+func audited[T any](ctx context.Context, db *FirestoreDB, name string, writes []write[T]) error {
+	for start := 0; start < len(writes); start += MaxAuditedWrites {
+		end := min(start+MaxAuditedWrites, len(writes))
+		if err := auditedTx(ctx, db, name, writes[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func auditedTx[T any](ctx context.Context, db *FirestoreDB, name string, writes []write[T]) error {
+	coll := db.Client.Collection(name)
+	actor := ActorFrom(ctx)
+	now := time.Now()
+
+	return db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		refs := make([]*firestore.DocumentRef, len(writes))
+		for i, w := range writes {
+			refs[i] = coll.Doc(w.id)
+		}
+		docs, err := tx.GetAll(refs)
+		if err != nil {
+			return err
+		}
+
+		for i, w := range writes {
+			var before *T
+			if docs[i].Exists() {
+				before = new(T)
+				if err := db.decode(ctx, docs[i], before); err != nil {
+					return fmt.Errorf("error parsing %s data for ID %s: %w", name, w.id, err)
+				}
+			}
+
+			entry, changed := model.NewAuditEntry(actor, now, name, w.id, before, w.value)
+			if !changed {
+				continue
+			}
+			if w.value == nil {
+				err = tx.Delete(refs[i])
+			} else {
+				var sealed any
+				if sealed, err = db.seal(ctx, *w.value); err == nil {
+					err = tx.Set(refs[i], sealed)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if err := db.appendAudit(ctx, tx, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *FirestoreDB) appendAudit(ctx context.Context, tx *firestore.Transaction, entry model.AuditEntry) error {
+	sealed, err := db.seal(ctx, entry)
+	if err != nil {
+		return err
+	}
+	return tx.Create(db.Client.Collection("auditlog").Doc(entry.Id), sealed)
+}
+
+// GetAuditEntries retrieves the audit trail for one entity, newest first.
+func (db *FirestoreDB) GetAuditEntries(ctx context.Context, entityType string, entityID string) ([]model.AuditEntry, error) {
+	var entries []model.AuditEntry
+	err := each(ctx, db, db.Client.Collection("auditlog").Where("EntityID", "==", entityID).Documents(ctx), func(e model.AuditEntry) error {
+		if e.EntityType == entityType {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving audit log for %s %s: %w", entityType, entityID, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Id > entries[j].Id })
+	return entries, nil
+}
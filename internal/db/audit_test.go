@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"foodbank/internal/model"
+)
+
+func TestFirestoreDB_AuditLog(t *testing.T) {
+	dbInstance := newFirestoreDB(t)
+	ctx := WithActor(context.Background(), "tester")
+
+	item, err := model.GenerateItem()
+	if err != nil {
+		t.Fatalf("Failed to generate item: %v", err)
+	}
+	if err := dbInstance.PutItem(ctx, *item); err != nil {
+		t.Fatalf("Failed to put item: %v", err)
+	}
+	if err := dbInstance.PutItem(ctx, *item); err != nil {
+		t.Fatalf("Failed to put unchanged item: %v", err)
+	}
+	item.Points++
+	if err := dbInstance.PutItem(ctx, *item); err != nil {
+		t.Fatalf("Failed to update item: %v", err)
+	}
+	if err := dbInstance.DeleteItem(ctx, item.Id); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	entries, err := dbInstance.GetAuditEntries(ctx, "items", item.Id)
+	if err != nil {
+		t.Fatalf("Failed to get audit entries: %v", err)
+	}
+
+	want := []string{model.AuditDelete, model.AuditUpdate, model.AuditCreate}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d audit entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i, entry := range entries {
+		if entry.Action != want[i] {
+			t.Errorf("Entry %d: expected action %s, got %s", i, want[i], entry.Action)
+		}
+		if entry.Actor != "tester" {
+			t.Errorf("Entry %d: expected actor tester, got %s", i, entry.Actor)
+		}
+	}
+	if len(entries[1].Changes) != 1 || entries[1].Changes[0].Field != "points" {
+		t.Errorf("Expected update to change only points, got %+v", entries[1].Changes)
+	}
+}
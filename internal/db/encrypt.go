@@ -0,0 +1,370 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// sensitiveFields are the JSON names of the PersonCommon and Household
+// fields encrypted at rest, used to find the same values in audit entries.
+var sensitiveFields = map[string]bool{
+	"email": true, "phone": true, "dob": true,
+	"street": true, "city": true, "state": true, "postalCode": true,
+	"income": true,
+}
+
+// sensitiveField reports whether an audited field, such as "head.dob" or
+// "members.0.extra.veteran", is encrypted at rest. Intake answers and the
+// signature always are.
+func sensitiveField(field string) bool {
+	name := field[strings.LastIndex(field, ".")+1:]
+	return sensitiveFields[name] || strings.HasPrefix(field, "extra.") || strings.Contains(field, ".extra.") ||
+		field == "signature.name" || field == "signature.image"
+}
+
+// signatureFields returns the encrypted fields of a copy of the signature s,
+// so the caller's signature is left as it was.
+func signatureFields(s **model.Signature) []*string {
+	if *s == nil {
+		return nil
+	}
+	sig := **s
+	*s = &sig
+	return []*string{&sig.Name, &sig.Image}
+}
+
+func sensitive(p *model.PersonCommon) []*string {
+	return []*string{&p.Email, &p.Phone, &p.DOB, &p.Street, &p.City, &p.State, &p.PostalCode}
+}
+
+// decode reads doc into v and decrypts any encrypted fields.
+func (db *FirestoreDB) decode(ctx context.Context, doc *firestore.DocumentSnapshot, v any) error {
+	if err := doc.DataTo(v); err != nil {
+		return err
+	}
+	return db.open(ctx, v)
+}
+
+// seal returns a copy of v with sensitive fields encrypted, ready to store.
+// Households also get their match index. Values of other types, and all
+// values when encryption is not configured, are otherwise returned unchanged.
+func (db *FirestoreDB) seal(ctx context.Context, v any) (any, error) {
+	if h, ok := v.(model.Household); ok {
+		h.MatchIndex = db.matchIndex(h)
+		v = h
+	}
+	if db.Cipher == nil {
+		return v, nil
+	}
+	var err error
+	switch v := v.(type) {
+	case model.Person:
+		err = db.sealPerson(ctx, &v.PersonCommon)
+		return v, err
+	case model.Household:
+		err = db.sealHousehold(ctx, &v)
+		return v, err
+	case model.DeletedHousehold:
+		err = db.sealHousehold(ctx, &v.Household)
+		return v, err
+	case model.HouseholdSnapshot:
+		err = db.sealHousehold(ctx, &v.Household)
+		return v, err
+	case model.AuditEntry:
+		v.Changes = append([]model.AuditChange(nil), v.Changes...)
+		err = db.auditValues(v.Changes, func(s string) (string, error) { return db.Cipher.Encrypt(ctx, s) })
+		return v, err
+	}
+	return v, nil
+}
+
+// open decrypts the sensitive fields of the value v points to in place.
+func (db *FirestoreDB) open(ctx context.Context, v any) error {
+	if db.Cipher == nil {
+		return nil
+	}
+	switch v := v.(type) {
+	case *model.Person:
+		return db.openPerson(ctx, &v.PersonCommon)
+	case *model.Household:
+		return db.openHousehold(ctx, v)
+	case *model.DeletedHousehold:
+		return db.openHousehold(ctx, &v.Household)
+	case *model.HouseholdSnapshot:
+		return db.openHousehold(ctx, &v.Household)
+	case *model.AuditEntry:
+		return db.auditValues(v.Changes, func(s string) (string, error) { return db.Cipher.Decrypt(ctx, s) })
+	}
+	return nil
+}
+
+func (db *FirestoreDB) sealHousehold(ctx context.Context, h *model.Household) error {
+	extra, err := mapExtra(h.Extra, func(s string) (string, error) { return db.Cipher.Encrypt(ctx, s) })
+	if err != nil {
+		return fmt.Errorf("error encrypting household %s: %w", h.Id, err)
+	}
+	h.Extra = extra
+	for _, field := range append([]*string{&h.Income}, signatureFields(&h.Signature)...) {
+		if *field, err = db.Cipher.Encrypt(ctx, *field); err != nil {
+			return fmt.Errorf("error encrypting household %s: %w", h.Id, err)
+		}
+	}
+	if err := db.sealPerson(ctx, &h.Head.PersonCommon); err != nil {
+		return err
+	}
+	if h.Members != nil {
+		h.Members = append([]model.Person(nil), h.Members...)
+	}
+	for i := range h.Members {
+		if err := db.sealPerson(ctx, &h.Members[i].PersonCommon); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *FirestoreDB) openHousehold(ctx context.Context, h *model.Household) error {
+	extra, err := mapExtra(h.Extra, func(s string) (string, error) { return db.Cipher.Decrypt(ctx, s) })
+	if err != nil {
+		return fmt.Errorf("error decrypting household %s: %w", h.Id, err)
+	}
+	h.Extra = extra
+	for _, field := range append([]*string{&h.Income}, signatureFields(&h.Signature)...) {
+		if *field, err = db.Cipher.Decrypt(ctx, *field); err != nil {
+			return fmt.Errorf("error decrypting household %s: %w", h.Id, err)
+		}
+	}
+	if err := db.openPerson(ctx, &h.Head.PersonCommon); err != nil {
+		return err
+	}
+	for i := range h.Members {
+		if err := db.openPerson(ctx, &h.Members[i].PersonCommon); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sealPerson encrypts p's sensitive fields, which must be plain text, and
+// sets the email blind index.
+func (db *FirestoreDB) sealPerson(ctx context.Context, p *model.PersonCommon) error {
+	p.EmailIndex = db.Cipher.BlindIndex(strings.ToLower(p.Email))
+	for _, field := range sensitive(p) {
+		enc, err := db.Cipher.Encrypt(ctx, *field)
+		if err != nil {
+			return fmt.Errorf("error encrypting person %s: %w", p.Id, err)
+		}
+		*field = enc
+	}
+	extra, err := mapExtra(p.Extra, func(s string) (string, error) { return db.Cipher.Encrypt(ctx, s) })
+	if err != nil {
+		return fmt.Errorf("error encrypting person %s: %w", p.Id, err)
+	}
+	p.Extra = extra
+	return nil
+}
+
+func (db *FirestoreDB) openPerson(ctx context.Context, p *model.PersonCommon) error {
+	for _, field := range sensitive(p) {
+		dec, err := db.Cipher.Decrypt(ctx, *field)
+		if err != nil {
+			return fmt.Errorf("error decrypting person %s: %w", p.Id, err)
+		}
+		*field = dec
+	}
+	extra, err := mapExtra(p.Extra, func(s string) (string, error) { return db.Cipher.Decrypt(ctx, s) })
+	if err != nil {
+		return fmt.Errorf("error decrypting person %s: %w", p.Id, err)
+	}
+	p.Extra = extra
+	return nil
+}
+
+// mapExtra returns a copy of the intake answers with fn applied to each, so
+// the caller's map is left as it was.
+func mapExtra(extra map[string]string, fn func(string) (string, error)) (map[string]string, error) {
+	if extra == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(extra))
+	for key, value := range extra {
+		v, err := fn(value)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// auditValues applies fn to the before and after values of changes to
+// sensitive fields, such as "head.dob" or "members.0.phone".
+func (db *FirestoreDB) auditValues(changes []model.AuditChange, fn func(string) (string, error)) error {
+	for i, c := range changes {
+		if !sensitiveField(c.Field) {
+			continue
+		}
+		var err error
+		if changes[i].Before, err = fn(c.Before); err != nil {
+			return fmt.Errorf("error processing audit value for %s: %w", c.Field, err)
+		}
+		if changes[i].After, err = fn(c.After); err != nil {
+			return fmt.Errorf("error processing audit value for %s: %w", c.Field, err)
+		}
+	}
+	return nil
+}
+
+// Reencrypt rewrites stored persons, households, deleted households, household
+// history and audit entries whose sensitive values are plain text or
+// encrypted under an old key. Run it after enabling encryption or rotating
+// keys; an old key can be removed from the key provider once it has finished. It returns how many documents
+// were rewritten.
+func (db *FirestoreDB) Reencrypt(ctx context.Context) (int, error) {
+	if db.Cipher == nil {
+		return 0, nil
+	}
+	total := 0
+	for _, fn := range []func(context.Context, *FirestoreDB) (int, error){
+		reencrypt[model.Person]("persons"),
+		reencrypt[model.Household]("households"),
+		reencrypt[model.DeletedHousehold]("deletedhouseholds"),
+		reencrypt[model.HouseholdSnapshot]("householdhistory"),
+		reencrypt[model.AuditEntry]("auditlog"),
+	} {
+		n, err := fn(ctx, db)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func reencrypt[T any](name string) func(context.Context, *FirestoreDB) (int, error) {
+	return func(ctx context.Context, db *FirestoreDB) (int, error) {
+		coll := db.Client.Collection(name)
+
+		// Find stale documents first, then rewrite them in transactions so
+		// concurrent edits are not overwritten with the values read here.
+		var refs []*firestore.DocumentRef
+		iter := coll.Documents(ctx)
+		defer iter.Stop()
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return 0, fmt.Errorf("error scanning %s: %w", name, err)
+			}
+			var raw T
+			if err := doc.DataTo(&raw); err != nil {
+				return 0, fmt.Errorf("error parsing %s document %s: %w", name, doc.Ref.ID, err)
+			}
+			if db.stale(&raw) {
+				refs = append(refs, doc.Ref)
+			}
+		}
+
+		done := 0
+		for start := 0; start < len(refs); start += MaxAuditedWrites {
+			chunk := refs[start:min(start+MaxAuditedWrites, len(refs))]
+			err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+				docs, err := tx.GetAll(chunk)
+				if err != nil {
+					return err
+				}
+				for _, doc := range docs {
+					if !doc.Exists() {
+						continue
+					}
+					var v T
+					if err := doc.DataTo(&v); err != nil {
+						return err
+					}
+					if !db.stale(&v) {
+						continue
+					}
+					if err := db.open(ctx, &v); err != nil {
+						return err
+					}
+					sealed, err := db.seal(ctx, v)
+					if err != nil {
+						return err
+					}
+					if err := tx.Set(doc.Ref, sealed); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return done, fmt.Errorf("error re-encrypting %s: %w", name, err)
+			}
+			done += len(chunk)
+		}
+		return done, nil
+	}
+}
+
+// stale reports whether the stored value v points to has sensitive values
+// that are plain text or under an old key, or lacks its email blind index.
+func (db *FirestoreDB) stale(v any) bool {
+	switch v := v.(type) {
+	case *model.Person:
+		return db.stalePerson(&v.PersonCommon)
+	case *model.Household:
+		return db.staleHousehold(v)
+	case *model.DeletedHousehold:
+		return db.staleHousehold(&v.Household)
+	case *model.HouseholdSnapshot:
+		return db.staleHousehold(&v.Household)
+	case *model.AuditEntry:
+		for _, c := range v.Changes {
+			if sensitiveField(c.Field) && (!db.Cipher.Current(c.Before) || !db.Cipher.Current(c.After)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (db *FirestoreDB) staleHousehold(h *model.Household) bool {
+	if !db.Cipher.Current(h.Income) || db.staleExtra(h.Extra) || db.stalePerson(&h.Head.PersonCommon) {
+		return true
+	}
+	if s := h.Signature; s != nil && (!db.Cipher.Current(s.Name) || !db.Cipher.Current(s.Image)) {
+		return true
+	}
+	for i := range h.Members {
+		if db.stalePerson(&h.Members[i].PersonCommon) {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *FirestoreDB) stalePerson(p *model.PersonCommon) bool {
+	for _, field := range sensitive(p) {
+		if !db.Cipher.Current(*field) {
+			return true
+		}
+	}
+	return db.staleExtra(p.Extra) || (p.Email != "" && p.EmailIndex == "")
+}
+
+func (db *FirestoreDB) staleExtra(extra map[string]string) bool {
+	for _, v := range extra {
+		if !db.Cipher.Current(v) {
+			return true
+		}
+	}
+	return false
+}
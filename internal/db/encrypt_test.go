@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"foodbank/internal/fieldcrypt"
+	"foodbank/internal/model"
+)
+
+func newEncryptedDB(t *testing.T, file fieldcrypt.KeyFile) *FirestoreDB {
+	dbInstance := newFirestoreDB(t)
+	keys, err := fieldcrypt.NewLocalKeyProvider(file)
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	dbInstance.Cipher = fieldcrypt.New(keys)
+	return dbInstance
+}
+
+func TestFirestoreDB_EncryptedPerson(t *testing.T) {
+	file, err := fieldcrypt.NewKeyFile("k1")
+	if err != nil {
+		t.Fatalf("Failed to create key file: %v", err)
+	}
+	dbInstance := newEncryptedDB(t, file)
+	ctx := context.Background()
+
+	person, err := model.GeneratePerson()
+	if err != nil {
+		t.Fatalf("Failed to generate person: %v", err)
+	}
+	person.Extra = map[string]string{"veteran": "yes"}
+	if err := dbInstance.PutPerson(ctx, *person); err != nil {
+		t.Fatalf("Failed to put person: %v", err)
+	}
+
+	doc, err := dbInstance.Client.Collection("persons").Doc(person.Id).Get(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read raw person: %v", err)
+	}
+	var raw model.Person
+	if err := doc.DataTo(&raw); err != nil {
+		t.Fatalf("Failed to parse raw person: %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(raw.Email) || !fieldcrypt.IsEncrypted(raw.DOB) {
+		t.Errorf("Expected encrypted email and DOB, got %q and %q", raw.Email, raw.DOB)
+	}
+	if !fieldcrypt.IsEncrypted(raw.Extra["veteran"]) {
+		t.Errorf("Expected encrypted intake answer, got %q", raw.Extra["veteran"])
+	}
+
+	found, err := dbInstance.GetPersonByEmail(ctx, person.Email)
+	if err != nil || found == nil {
+		t.Fatalf("Failed to find person by email: %v", err)
+	}
+	if found.Id != person.Id || found.DOB != person.DOB {
+		t.Errorf("Expected person %s with DOB %s, got %s with %s", person.Id, person.DOB, found.Id, found.DOB)
+	}
+
+	rotated, err := fieldcrypt.NewKeyFile("k2")
+	if err != nil {
+		t.Fatalf("Failed to create key file: %v", err)
+	}
+	file.Keys["k2"] = rotated.Keys["k2"]
+	file.Current = "k2"
+	dbInstance = newEncryptedDB(t, file)
+	if _, err := dbInstance.Reencrypt(ctx); err != nil {
+		t.Fatalf("Failed to re-encrypt: %v", err)
+	}
+
+	delete(file.Keys, "k1")
+	dbInstance = newEncryptedDB(t, file)
+	again, err := dbInstance.GetPerson(ctx, person.Id)
+	if err != nil {
+		t.Fatalf("Failed to read person with only the new key: %v", err)
+	}
+	if again.Phone != person.Phone || again.Extra["veteran"] != "yes" {
+		t.Errorf("Expected phone %s and intake answer after rotation, got %s and %v", person.Phone, again.Phone, again.Extra)
+	}
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+// AnonymizeHousehold replaces a household with its anonymized form, deletes
+// its certification history and removes the values from its earlier audit
+// entries. action is model.AuditAnonymize under the retention policy or
+// model.AuditErase when the client asked for their data to be erased.
+func (db *FirestoreDB) AnonymizeHousehold(ctx context.Context, id string, action string) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	ref := db.Client.Collection("households").Doc(id)
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var household model.Household
+		if err := db.decode(ctx, doc, &household); err != nil {
+			return err
+		}
+		entries, err := db.householdAuditEntries(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		history, err := db.householdHistoryRefs(tx, id)
+		if err != nil {
+			return err
+		}
+
+		anon, err := db.seal(ctx, household.Anonymize())
+		if err != nil {
+			return err
+		}
+		if err := tx.Set(ref, anon); err != nil {
+			return err
+		}
+		if err := deleteAll(tx, history); err != nil {
+			return err
+		}
+		return db.redactAudit(ctx, tx, entries, model.NewAuditEvent(actor, now, "households", id, action))
+	})
+	if err != nil {
+		return fmt.Errorf("error anonymizing household with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// PurgeHousehold permanently removes a household, whether or not it was
+// deleted first, with its certification history, and removes the values from
+// its audit entries. Visits by its members are kept.
+func (db *FirestoreDB) PurgeHousehold(ctx context.Context, id string) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		entries, err := db.householdAuditEntries(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		history, err := db.householdHistoryRefs(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(db.Client.Collection("households").Doc(id)); err != nil {
+			return err
+		}
+		if err := deleteAll(tx, history); err != nil {
+			return err
+		}
+		if err := tx.Delete(db.Client.Collection("deletedhouseholds").Doc(id)); err != nil {
+			return err
+		}
+		return db.redactAudit(ctx, tx, entries, model.NewAuditEvent(actor, now, "households", id, model.AuditPurge))
+	})
+	if err != nil {
+		return fmt.Errorf("error purging household with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+func (db *FirestoreDB) householdAuditEntries(ctx context.Context, tx *firestore.Transaction, id string) ([]model.AuditEntry, error) {
+	var entries []model.AuditEntry
+	err := each(ctx, db, tx.Documents(db.Client.Collection("auditlog").Where("EntityID", "==", id)), func(e model.AuditEntry) error {
+		if e.EntityType == "households" && !e.Redacted {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func deleteAll(tx *firestore.Transaction, refs []*firestore.DocumentRef) error {
+	for _, ref := range refs {
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactAudit rewrites entries without their values and appends event.
+func (db *FirestoreDB) redactAudit(ctx context.Context, tx *firestore.Transaction, entries []model.AuditEntry, event model.AuditEntry) error {
+	for _, e := range entries {
+		if err := tx.Set(db.Client.Collection("auditlog").Doc(e.Id), e.Redact()); err != nil {
+			return err
+		}
+	}
+	return db.appendAudit(ctx, tx, event)
+}
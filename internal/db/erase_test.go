@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"foodbank/internal/model"
+)
+
+func TestFirestoreDB_AnonymizeHouseholdRedactsAudit(t *testing.T) {
+	dbInstance := newFirestoreDB(t)
+	ctx := context.Background()
+
+	households, err := model.GenerateHouseholds(1)
+	if err != nil {
+		t.Fatalf("Failed to generate household: %v", err)
+	}
+	household := households[0]
+	if err := dbInstance.PutHousehold(ctx, household); err != nil {
+		t.Fatalf("Failed to put household: %v", err)
+	}
+
+	if err := dbInstance.AnonymizeHousehold(ctx, household.Id, model.AuditErase); err != nil {
+		t.Fatalf("Failed to anonymize household: %v", err)
+	}
+
+	stored, err := dbInstance.GetHouseholdByID(ctx, household.Id)
+	if err != nil {
+		t.Fatalf("Failed to get household: %v", err)
+	}
+	if !stored.Anonymized || stored.Head.LastName != "" || stored.Head.Id != household.Head.Id {
+		t.Errorf("Expected anonymized household keeping person IDs, got %+v", stored)
+	}
+
+	entries, err := dbInstance.GetAuditEntries(ctx, "households", household.Id)
+	if err != nil {
+		t.Fatalf("Failed to get audit entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != model.AuditErase {
+		t.Fatalf("Expected create and erase entries, got %+v", entries)
+	}
+	for _, c := range entries[1].Changes {
+		if c.Before != "" || c.After != "" {
+			t.Errorf("Expected redacted change, got %+v", c)
+		}
+	}
+}
@@ -5,16 +5,22 @@ import (
 	"fmt"
 	"strings"
 
+	"foodbank/internal/fieldcrypt"
 	"foodbank/internal/model"
 
 	"cloud.google.com/go/firestore"
 	"github.com/oklog/ulid/v2"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // FirestoreDB encapsulates the Firestore client.
 type FirestoreDB struct {
 	Client *firestore.Client
+	// Cipher, when set, encrypts the sensitive PersonCommon fields of stored
+	// persons and households and the matching audit values.
+	Cipher *fieldcrypt.Cipher
 }
 
 // NewFirestoreDB creates a new instance of FirestoreDB.
@@ -22,12 +28,21 @@ func NewFirestoreDB(client *firestore.Client) *FirestoreDB {
 	return &FirestoreDB{Client: client}
 }
 
-// GetPersonByEmail retrieves a person by email.
+// GetPersonByEmail retrieves a person by email. With encryption enabled the
+// lookup uses the email blind index, falling back to the plain text field for
+// persons not yet re-encrypted.
 func (db *FirestoreDB) GetPersonByEmail(ctx context.Context, email string) (*model.Person, error) {
-	iter := db.Client.Collection("persons").Where("Email", "==", strings.ToLower(email)).Limit(1).Documents(ctx)
-	defer iter.Stop()
+	email = strings.ToLower(email)
+	persons := db.Client.Collection("persons")
 
-	doc, err := iter.Next()
+	var doc *firestore.DocumentSnapshot
+	var err error
+	if db.Cipher != nil {
+		doc, err = first(persons.Where("EmailIndex", "==", db.Cipher.BlindIndex(email)).Limit(1).Documents(ctx))
+	}
+	if db.Cipher == nil || err == iterator.Done {
+		doc, err = first(persons.Where("Email", "==", email).Limit(1).Documents(ctx))
+	}
 	if err == iterator.Done {
 		// not found
 		return nil, nil
@@ -37,7 +52,7 @@ func (db *FirestoreDB) GetPersonByEmail(ctx context.Context, email string) (*mod
 	}
 
 	var person model.Person
-	if err := doc.DataTo(&person); err != nil {
+	if err := db.decode(ctx, doc, &person); err != nil {
 		return nil, fmt.Errorf("error parsing person data: %w", err)
 	}
 
@@ -46,47 +61,7 @@ func (db *FirestoreDB) GetPersonByEmail(ctx context.Context, email string) (*mod
 
 // GetHouseholds retrieves all households ordered by ID in descending order.
 func (db *FirestoreDB) GetHouseholds(ctx context.Context, pageSize int, startAfter string) ([]model.Household, string, error) {
-	var households []model.Household
-	var query firestore.Query
-
-	// Build the query
-	if startAfter == "" {
-		query = db.Client.Collection("households").OrderBy("Id", firestore.Desc).Limit(pageSize)
-	} else {
-		lastDoc, err := db.Client.Collection("households").Doc(startAfter).Get(ctx)
-		if err != nil {
-			return nil, "", fmt.Errorf("error retrieving last document for pagination: %w", err)
-		}
-		query = db.Client.Collection("households").OrderBy("Id", firestore.Desc).Limit(pageSize).StartAfter(lastDoc.Data())
-	}
-
-	iter := query.Documents(ctx)
-	defer iter.Stop() // Ensure the iterator is properly closed
-
-	// Iterate over documents
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, "", fmt.Errorf("error retrieving households: %w", err)
-		}
-
-		var household model.Household
-		if err := doc.DataTo(&household); err != nil {
-			return nil, "", fmt.Errorf("error parsing household data: %w", err)
-		}
-		households = append(households, household)
-	}
-
-	// Determine next page token
-	nextPageToken := ""
-	if len(households) > 0 {
-		nextPageToken = households[len(households)-1].Id
-	}
-
-	return households, nextPageToken, nil
+	return getPage(ctx, db, db.Client.Collection("households"), pageSize, startAfter, model.Household.GetID)
 }
 
 // GetHouseholdByID retrieves a specific household by its ID.
@@ -97,13 +72,45 @@ func (db *FirestoreDB) GetHouseholdByID(ctx context.Context, id string) (*model.
 	}
 
 	var household model.Household
-	if err := doc.DataTo(&household); err != nil {
+	if err := db.decode(ctx, doc, &household); err != nil {
 		return nil, fmt.Errorf("error parsing household data for ID %s: %w", id, err)
 	}
 
 	return &household, nil
 }
 
+// GetHouseholdsByID retrieves the households with the given IDs in one read,
+// keyed by ID. IDs without a household are left out.
+func (db *FirestoreDB) GetHouseholdsByID(ctx context.Context, ids []string) (map[string]model.Household, error) {
+	households := map[string]model.Household{}
+	var refs []*firestore.DocumentRef
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			refs = append(refs, db.Client.Collection("households").Doc(id))
+		}
+	}
+	if len(refs) == 0 {
+		return households, nil
+	}
+	docs, err := db.Client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving households: %w", err)
+	}
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var household model.Household
+		if err := db.decode(ctx, doc, &household); err != nil {
+			return nil, fmt.Errorf("error parsing household data for ID %s: %w", doc.Ref.ID, err)
+		}
+		households[household.Id] = household
+	}
+	return households, nil
+}
+
 // AddHousehold adds a new household to Firestore.
 func (db *FirestoreDB) AddHousehold(ctx context.Context, household model.Household) error {
 	// Generate a ULID if ID is not set
@@ -111,24 +118,36 @@ func (db *FirestoreDB) AddHousehold(ctx context.Context, household model.Househo
 		household.Id = ulid.Make().String()
 	}
 
-	_, err := db.Client.Collection("households").Doc(household.Id).Set(ctx, household)
+	return db.PutHousehold(ctx, household)
+}
+
+// PutHousehold creates or replaces a household.
+func (db *FirestoreDB) PutHousehold(ctx context.Context, household model.Household) error {
+	err := audited(ctx, db, "households", []write[model.Household]{set(household.Id, household)})
 	if err != nil {
 		return fmt.Errorf("error saving household: %w", err)
 	}
 	return nil
 }
 
-// DeleteHousehold deletes a specific household by its ID.
-func (db *FirestoreDB) DeleteHousehold(ctx context.Context, id string) error {
-	_, err := db.Client.Collection("households").Doc(id).Delete(ctx)
+// PutHouseholds creates or replaces households, MaxAuditedWrites at a time.
+func (db *FirestoreDB) PutHouseholds(ctx context.Context, households []model.Household) error {
+	writes := make([]write[model.Household], len(households))
+	for i, household := range households {
+		if household.Id == "" {
+			household.Id = ulid.Make().String()
+		}
+		writes[i] = set(household.Id, household)
+	}
+	err := audited(ctx, db, "households", writes)
 	if err != nil {
-		return fmt.Errorf("error deleting household with ID %s: %w", id, err)
+		return fmt.Errorf("error saving households: %w", err)
 	}
 	return nil
 }
 
 func (db *FirestoreDB) PutPerson(ctx context.Context, person model.Person) error {
-	_, err := db.Client.Collection("persons").Doc(person.Id).Set(ctx, person)
+	err := audited(ctx, db, "persons", []write[model.Person]{set(person.Id, person)})
 	if err != nil {
 		return fmt.Errorf("error saving person: %w", err)
 	}
@@ -166,14 +185,14 @@ func (db *FirestoreDB) DeleteResetPassword(ctx context.Context, id string) error
 }
 
 func (db *FirestoreDB) PutPersons(ctx context.Context, persons []model.Person) error {
-	batch := db.Client.Batch()
-	for _, person := range persons {
+	writes := make([]write[model.Person], len(persons))
+	for i, person := range persons {
 		if person.Id == "" {
 			person.Id = ulid.Make().String()
 		}
-		batch.Set(db.Client.Collection("persons").Doc(person.Id), person)
+		writes[i] = set(person.Id, person)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "persons", writes)
 	if err != nil {
 		return fmt.Errorf("error saving persons: %w", err)
 	}
@@ -187,7 +206,7 @@ func (db *FirestoreDB) GetPerson(ctx context.Context, id string) (*model.Person,
 	}
 
 	var person model.Person
-	if err := doc.DataTo(&person); err != nil {
+	if err := db.decode(ctx, doc, &person); err != nil {
 		return nil, fmt.Errorf("error parsing person data for ID %s: %w", id, err)
 	}
 
@@ -195,7 +214,7 @@ func (db *FirestoreDB) GetPerson(ctx context.Context, id string) (*model.Person,
 }
 
 func (db *FirestoreDB) DeletePerson(ctx context.Context, id string) error {
-	_, err := db.Client.Collection("persons").Doc(id).Delete(ctx)
+	err := audited(ctx, db, "persons", []write[model.Person]{del[model.Person](id)})
 	if err != nil {
 		return fmt.Errorf("error deleting person with ID %s: %w", id, err)
 	}
@@ -203,11 +222,11 @@ func (db *FirestoreDB) DeletePerson(ctx context.Context, id string) error {
 }
 
 func (db *FirestoreDB) DeletePersons(ctx context.Context, ids []string) error {
-	batch := db.Client.Batch()
-	for _, id := range ids {
-		batch.Delete(db.Client.Collection("persons").Doc(id))
+	writes := make([]write[model.Person], len(ids))
+	for i, id := range ids {
+		writes[i] = del[model.Person](id)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "persons", writes)
 	if err != nil {
 		return fmt.Errorf("error deleting persons: %w", err)
 	}
@@ -215,7 +234,7 @@ func (db *FirestoreDB) DeletePersons(ctx context.Context, ids []string) error {
 }
 
 func (db *FirestoreDB) PutFoodBank(ctx context.Context, foodBank model.FoodBank) error {
-	_, err := db.Client.Collection("foodbanks").Doc(foodBank.Id).Set(ctx, foodBank)
+	err := audited(ctx, db, "foodbanks", []write[model.FoodBank]{set(foodBank.Id, foodBank)})
 	if err != nil {
 		return fmt.Errorf("error saving food bank: %w", err)
 	}
@@ -223,14 +242,14 @@ func (db *FirestoreDB) PutFoodBank(ctx context.Context, foodBank model.FoodBank)
 }
 
 func (db *FirestoreDB) PutFoodBanks(ctx context.Context, foodBanks []model.FoodBank) error {
-	batch := db.Client.Batch()
-	for _, foodBank := range foodBanks {
+	writes := make([]write[model.FoodBank], len(foodBanks))
+	for i, foodBank := range foodBanks {
 		if foodBank.Id == "" {
 			foodBank.Id = ulid.Make().String()
 		}
-		batch.Set(db.Client.Collection("foodbanks").Doc(foodBank.Id), foodBank)
+		writes[i] = set(foodBank.Id, foodBank)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "foodbanks", writes)
 	if err != nil {
 		return fmt.Errorf("error saving food banks: %w", err)
 	}
@@ -251,8 +270,21 @@ func (db *FirestoreDB) GetFoodBank(ctx context.Context, id string) (*model.FoodB
 	return &foodBank, nil
 }
 
+// GetFoodBanks retrieves all food banks ordered by name.
+func (db *FirestoreDB) GetFoodBanks(ctx context.Context) ([]model.FoodBank, error) {
+	var foodBanks []model.FoodBank
+	err := each(ctx, db, db.Client.Collection("foodbanks").OrderBy("Name", firestore.Asc).Documents(ctx), func(fb model.FoodBank) error {
+		foodBanks = append(foodBanks, fb)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving food banks: %w", err)
+	}
+	return foodBanks, nil
+}
+
 func (db *FirestoreDB) DeleteFoodBank(ctx context.Context, id string) error {
-	_, err := db.Client.Collection("foodbanks").Doc(id).Delete(ctx)
+	err := audited(ctx, db, "foodbanks", []write[model.FoodBank]{del[model.FoodBank](id)})
 	if err != nil {
 		return fmt.Errorf("error deleting food bank with ID %s: %w", id, err)
 	}
@@ -260,11 +292,11 @@ func (db *FirestoreDB) DeleteFoodBank(ctx context.Context, id string) error {
 }
 
 func (db *FirestoreDB) DeleteFoodBanks(ctx context.Context, ids []string) error {
-	batch := db.Client.Batch()
-	for _, id := range ids {
-		batch.Delete(db.Client.Collection("foodbanks").Doc(id))
+	writes := make([]write[model.FoodBank], len(ids))
+	for i, id := range ids {
+		writes[i] = del[model.FoodBank](id)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "foodbanks", writes)
 	if err != nil {
 		return fmt.Errorf("error deleting food banks: %w", err)
 	}
@@ -272,7 +304,7 @@ func (db *FirestoreDB) DeleteFoodBanks(ctx context.Context, ids []string) error
 }
 
 func (db *FirestoreDB) PutFoodBankVisit(ctx context.Context, visit model.FoodBankVisit) error {
-	_, err := db.Client.Collection("foodbankvisits").Doc(visit.Id).Set(ctx, visit)
+	err := audited(ctx, db, "foodbankvisits", []write[model.FoodBankVisit]{set(visit.Id, visit)})
 	if err != nil {
 		return fmt.Errorf("error saving food bank visit: %w", err)
 	}
@@ -280,14 +312,14 @@ func (db *FirestoreDB) PutFoodBankVisit(ctx context.Context, visit model.FoodBan
 }
 
 func (db *FirestoreDB) PutFoodBankVisits(ctx context.Context, visits []model.FoodBankVisit) error {
-	batch := db.Client.Batch()
-	for _, visit := range visits {
+	writes := make([]write[model.FoodBankVisit], len(visits))
+	for i, visit := range visits {
 		if visit.Id == "" {
 			visit.Id = ulid.Make().String()
 		}
-		batch.Set(db.Client.Collection("foodbankvisits").Doc(visit.Id), visit)
+		writes[i] = set(visit.Id, visit)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "foodbankvisits", writes)
 	if err != nil {
 		return fmt.Errorf("error saving food bank visits: %w", err)
 	}
@@ -309,7 +341,7 @@ func (db *FirestoreDB) GetFoodBankVisit(ctx context.Context, id string) (*model.
 }
 
 func (db *FirestoreDB) DeleteFoodBankVisit(ctx context.Context, id string) error {
-	_, err := db.Client.Collection("foodbankvisits").Doc(id).Delete(ctx)
+	err := audited(ctx, db, "foodbankvisits", []write[model.FoodBankVisit]{del[model.FoodBankVisit](id)})
 	if err != nil {
 		return fmt.Errorf("error deleting food bank visit with ID %s: %w", id, err)
 	}
@@ -317,11 +349,11 @@ func (db *FirestoreDB) DeleteFoodBankVisit(ctx context.Context, id string) error
 }
 
 func (db *FirestoreDB) DeleteFoodBankVisits(ctx context.Context, ids []string) error {
-	batch := db.Client.Batch()
-	for _, id := range ids {
-		batch.Delete(db.Client.Collection("foodbankvisits").Doc(id))
+	writes := make([]write[model.FoodBankVisit], len(ids))
+	for i, id := range ids {
+		writes[i] = del[model.FoodBankVisit](id)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "foodbankvisits", writes)
 	if err != nil {
 		return fmt.Errorf("error deleting food bank visits: %w", err)
 	}
@@ -329,7 +361,7 @@ func (db *FirestoreDB) DeleteFoodBankVisits(ctx context.Context, ids []string) e
 }
 
 func (db *FirestoreDB) PutItem(ctx context.Context, item model.Item) error {
-	_, err := db.Client.Collection("items").Doc(item.Id).Set(ctx, item)
+	err := audited(ctx, db, "items", []write[model.Item]{set(item.Id, item)})
 	if err != nil {
 		return fmt.Errorf("error saving item: %w", err)
 	}
@@ -337,14 +369,14 @@ func (db *FirestoreDB) PutItem(ctx context.Context, item model.Item) error {
 }
 
 func (db *FirestoreDB) PutItems(ctx context.Context, items []model.Item) error {
-	batch := db.Client.Batch()
-	for _, item := range items {
+	writes := make([]write[model.Item], len(items))
+	for i, item := range items {
 		if item.Id == "" {
 			item.Id = ulid.Make().String()
 		}
-		batch.Set(db.Client.Collection("items").Doc(item.Id), item)
+		writes[i] = set(item.Id, item)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "items", writes)
 	if err != nil {
 		return fmt.Errorf("error saving items: %w", err)
 	}
@@ -366,7 +398,7 @@ func (db *FirestoreDB) GetItem(ctx context.Context, id string) (*model.Item, err
 }
 
 func (db *FirestoreDB) DeleteItem(ctx context.Context, id string) error {
-	_, err := db.Client.Collection("items").Doc(id).Delete(ctx)
+	err := audited(ctx, db, "items", []write[model.Item]{del[model.Item](id)})
 	if err != nil {
 		return fmt.Errorf("error deleting item with ID %s: %w", id, err)
 	}
@@ -374,11 +406,11 @@ func (db *FirestoreDB) DeleteItem(ctx context.Context, id string) error {
 }
 
 func (db *FirestoreDB) DeleteItems(ctx context.Context, ids []string) error {
-	batch := db.Client.Batch()
-	for _, id := range ids {
-		batch.Delete(db.Client.Collection("items").Doc(id))
+	writes := make([]write[model.Item], len(ids))
+	for i, id := range ids {
+		writes[i] = del[model.Item](id)
 	}
-	_, err := batch.Commit(ctx)
+	err := audited(ctx, db, "items", writes)
 	if err != nil {
 		return fmt.Errorf("error deleting items: %w", err)
 	}
@@ -400,7 +432,7 @@ func (db *FirestoreDB) GetPersons(ctx context.Context) ([]model.Person, error) {
 		}
 
 		var person model.Person
-		if err := doc.DataTo(&person); err != nil {
+		if err := db.decode(ctx, doc, &person); err != nil {
 			return nil, fmt.Errorf("error parsing person data: %w", err)
 		}
 		persons = append(persons, person)
@@ -409,6 +441,21 @@ func (db *FirestoreDB) GetPersons(ctx context.Context) ([]model.Person, error) {
 	return persons, nil
 }
 
+// GetPersonsPage retrieves a page of persons ordered by ID in descending order.
+func (db *FirestoreDB) GetPersonsPage(ctx context.Context, pageSize int, startAfter string) ([]model.Person, string, error) {
+	return getPage(ctx, db, db.Client.Collection("persons"), pageSize, startAfter, model.Person.GetID)
+}
+
+// GetFoodBankVisits retrieves a page of food bank visits ordered by ID in descending order.
+func (db *FirestoreDB) GetFoodBankVisits(ctx context.Context, pageSize int, startAfter string) ([]model.FoodBankVisit, string, error) {
+	return getPage(ctx, db, db.Client.Collection("foodbankvisits"), pageSize, startAfter, model.FoodBankVisit.GetID)
+}
+
+// GetItems retrieves a page of items ordered by ID in descending order.
+func (db *FirestoreDB) GetItems(ctx context.Context, pageSize int, startAfter string) ([]model.Item, string, error) {
+	return getPage(ctx, db, db.Client.Collection("items"), pageSize, startAfter, model.Item.GetID)
+}
+
 func (db *FirestoreDB) GetHouseholdPersons(ctx context.Context, householdID string) ([]model.Person, error) {
 	var persons []model.Person
 	iter := db.Client.Collection("persons").Where("householdID", "==", householdID).Documents(ctx)
@@ -424,7 +471,7 @@ func (db *FirestoreDB) GetHouseholdPersons(ctx context.Context, householdID stri
 		}
 
 		var person model.Person
-		if err := doc.DataTo(&person); err != nil {
+		if err := db.decode(ctx, doc, &person); err != nil {
 			return nil, fmt.Errorf("error parsing person data for household %s: %w", householdID, err)
 		}
 		persons = append(persons, person)
@@ -432,3 +479,55 @@ func (db *FirestoreDB) GetHouseholdPersons(ctx context.Context, householdID stri
 
 	return persons, nil
 }
+
+func first(iter *firestore.DocumentIterator) (*firestore.DocumentSnapshot, error) {
+	defer iter.Stop()
+	return iter.Next()
+}
+
+// IsNotFound reports whether err was caused by a missing Firestore document.
+func IsNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
+
+// getPage reads up to pageSize documents from coll ordered by Id descending,
+// starting after the document with ID startAfter. The returned token is the
+// ID of the last document when the page is full, or "" when there are no more.
+func getPage[T any](ctx context.Context, db *FirestoreDB, coll *firestore.CollectionRef, pageSize int, startAfter string, idOf func(T) string) ([]T, string, error) {
+	var results []T
+	query := coll.OrderBy("Id", firestore.Desc).Limit(pageSize)
+
+	if startAfter != "" {
+		lastDoc, err := coll.Doc(startAfter).Get(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("error retrieving last document for pagination: %w", err)
+		}
+		query = query.StartAfter(lastDoc)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("error retrieving %s: %w", coll.ID, err)
+		}
+
+		var result T
+		if err := db.decode(ctx, doc, &result); err != nil {
+			return nil, "", fmt.Errorf("error parsing %s data: %w", coll.ID, err)
+		}
+		results = append(results, result)
+	}
+
+	nextPageToken := ""
+	if len(results) == pageSize {
+		nextPageToken = idOf(results[len(results)-1])
+	}
+
+	return results, nextPageToken, nil
+}
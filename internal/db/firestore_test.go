@@ -125,6 +125,37 @@ func TestFirestoreDB_PutFoodBankVisitsAndGetFoodBankVisits(t *testing.T) {
 	testPutAndGets(t, dbInstance, model.GenerateFoodBankVisits, dbInstance.PutFoodBankVisits, dbInstance.GetFoodBankVisit)
 }
 
+func TestFirestoreDB_VisitedBefore(t *testing.T) {
+	dbInstance := newFirestoreDB(t)
+	ctx := context.Background()
+
+	// more people than one "in" batch, every other one with an earlier visit
+	var ids []string
+	var visits []model.FoodBankVisit
+	for i := 0; i < inQueryLimit+5; i++ {
+		visit, _ := model.GenerateFoodBankVisit()
+		visit.Date = "2025-05-31"
+		if i%2 == 1 {
+			visit.Date = "2025-06-01"
+		}
+		ids = append(ids, visit.PersonId)
+		visits = append(visits, *visit)
+	}
+	if err := dbInstance.PutFoodBankVisits(ctx, visits); err != nil {
+		t.Fatalf("Failed to put visits: %v", err)
+	}
+
+	visited, err := dbInstance.VisitedBefore(ctx, ids, "2025-06-01")
+	if err != nil {
+		t.Fatalf("Failed to find earlier visitors: %v", err)
+	}
+	for i, id := range ids {
+		if visited[id] != (i%2 == 0) {
+			t.Errorf("person %d: visited before = %v", i, visited[id])
+		}
+	}
+}
+
 func TestFirestoreDB_PutItemsAndGetItems(t *testing.T) {
 	dbInstance := newFirestoreDB(t)
 	testPutAndGets(t, dbInstance, model.GenerateItems, dbInstance.PutItems, dbInstance.GetItem)
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+// PutPovertyGuideline saves the guideline for a year, replacing any earlier
+// one for the same year.
+func (db *FirestoreDB) PutPovertyGuideline(ctx context.Context, guideline model.PovertyGuideline) error {
+	err := audited(ctx, db, "povertyguidelines", []write[model.PovertyGuideline]{set(guideline.Id, guideline)})
+	if err != nil {
+		return fmt.Errorf("error saving poverty guideline for %d: %w", guideline.Year, err)
+	}
+	return nil
+}
+
+// GetPovertyGuidelines retrieves the guidelines of all years, latest first.
+func (db *FirestoreDB) GetPovertyGuidelines(ctx context.Context) ([]model.PovertyGuideline, error) {
+	var guidelines []model.PovertyGuideline
+	err := each(ctx, db, db.Client.Collection("povertyguidelines").OrderBy("Year", firestore.Desc).Documents(ctx), func(g model.PovertyGuideline) error {
+		guidelines = append(guidelines, g)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving poverty guidelines: %w", err)
+	}
+	return guidelines, nil
+}
+
+func (db *FirestoreDB) DeletePovertyGuideline(ctx context.Context, id string) error {
+	err := audited(ctx, db, "povertyguidelines", []write[model.PovertyGuideline]{del[model.PovertyGuideline](id)})
+	if err != nil {
+		return fmt.Errorf("error deleting poverty guideline with ID %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"foodbank/internal/model"
+)
+
+// PutIntakeForm saves a site's intake form, keyed by the site's FoodBank ID.
+func (db *FirestoreDB) PutIntakeForm(ctx context.Context, form model.IntakeForm) error {
+	err := audited(ctx, db, "intakeforms", []write[model.IntakeForm]{set(form.Id, form)})
+	if err != nil {
+		return fmt.Errorf("error saving intake form for site %s: %w", form.Id, err)
+	}
+	return nil
+}
+
+// GetIntakeForm returns the intake form of the site with the given FoodBank
+// ID. A site that has none gets an empty form.
+func (db *FirestoreDB) GetIntakeForm(ctx context.Context, site string) (*model.IntakeForm, error) {
+	doc, err := db.Client.Collection("intakeforms").Doc(site).Get(ctx)
+	if IsNotFound(err) {
+		return &model.IntakeForm{Id: site}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving intake form for site %s: %w", site, err)
+	}
+
+	var form model.IntakeForm
+	if err := doc.DataTo(&form); err != nil {
+		return nil, fmt.Errorf("error parsing intake form for site %s: %w", site, err)
+	}
+	return &form, nil
+}
+
+// GetIntakeForms returns the intake forms of all sites.
+func (db *FirestoreDB) GetIntakeForms(ctx context.Context) ([]model.IntakeForm, error) {
+	var forms []model.IntakeForm
+	err := each(ctx, db, db.Client.Collection("intakeforms").Documents(ctx), func(f model.IntakeForm) error {
+		forms = append(forms, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving intake forms: %w", err)
+	}
+	return forms, nil
+}
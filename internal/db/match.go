@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// matchIndex returns the household's MatchKeys as stored: keyed hashes when
+// personal fields are encrypted, so the index doesn't reveal them.
+func (db *FirestoreDB) matchIndex(h model.Household) []string {
+	keys := h.MatchKeys()
+	if db.Cipher != nil {
+		for i, key := range keys {
+			keys[i] = db.Cipher.BlindIndex(key)
+		}
+	}
+	return keys
+}
+
+// FindHouseholdMatches returns stored households whose head has the same name
+// and date of birth, or the same phone number, as h's head.
+func (db *FirestoreDB) FindHouseholdMatches(ctx context.Context, h model.Household) ([]model.Household, error) {
+	keys := db.matchIndex(h)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var matches []model.Household
+	query := db.Client.Collection("households").Where("MatchIndex", "array-contains-any", keys).Limit(10)
+	err := each(ctx, db, query.Documents(ctx), func(match model.Household) error {
+		matches = append(matches, match)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error finding matching households: %w", err)
+	}
+	return matches, nil
+}
+
+// ReindexHouseholds sets the match index of households stored without one or
+// with an out of date one, such as before encryption was turned on. It
+// returns how many households were rewritten.
+func (db *FirestoreDB) ReindexHouseholds(ctx context.Context) (int, error) {
+	coll := db.Client.Collection("households")
+
+	var refs []*firestore.DocumentRef
+	iter := coll.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error scanning households: %w", err)
+		}
+		var h model.Household
+		if err := db.decode(ctx, doc, &h); err != nil {
+			return 0, fmt.Errorf("error parsing household %s: %w", doc.Ref.ID, err)
+		}
+		if !slices.Equal(h.MatchIndex, db.matchIndex(h)) {
+			refs = append(refs, doc.Ref)
+		}
+	}
+
+	// The index isn't part of the audited data, so the households are
+	// rewritten directly rather than through audited.
+	done := 0
+	for start := 0; start < len(refs); start += MaxAuditedWrites {
+		chunk := refs[start:min(start+MaxAuditedWrites, len(refs))]
+		err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			docs, err := tx.GetAll(chunk)
+			if err != nil {
+				return err
+			}
+			for _, doc := range docs {
+				if !doc.Exists() {
+					continue
+				}
+				var h model.Household
+				if err := db.decode(ctx, doc, &h); err != nil {
+					return err
+				}
+				sealed, err := db.seal(ctx, h)
+				if err != nil {
+					return err
+				}
+				if err := tx.Set(doc.Ref, sealed); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return done, fmt.Errorf("error reindexing households: %w", err)
+		}
+		done += len(chunk)
+	}
+	return done, nil
+}
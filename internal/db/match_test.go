@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"foodbank/internal/fieldcrypt"
+	"foodbank/internal/model"
+)
+
+func TestFirestoreDB_FindHouseholdMatches(t *testing.T) {
+	file, err := fieldcrypt.NewKeyFile("k1")
+	if err != nil {
+		t.Fatalf("Failed to create key file: %v", err)
+	}
+	for name, dbInstance := range map[string]*FirestoreDB{
+		"plain":     newFirestoreDB(t),
+		"encrypted": newEncryptedDB(t, file),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			households, err := model.GenerateHouseholds(1)
+			if err != nil {
+				t.Fatalf("Failed to generate household: %v", err)
+			}
+			household := households[0]
+			if err := dbInstance.PutHousehold(ctx, household); err != nil {
+				t.Fatalf("Failed to put household: %v", err)
+			}
+
+			signup := model.Household{Head: household.Head}
+			signup.Head.FirstName = strings.ToUpper(household.Head.FirstName)
+			signup.Head.Phone = ""
+			matches, err := dbInstance.FindHouseholdMatches(ctx, signup)
+			if err != nil {
+				t.Fatalf("Failed to find matches: %v", err)
+			}
+			if _, ok := model.BestMatch(signup, matches); !ok {
+				t.Errorf("Expected household %s to match by name and DOB, got %+v", household.Id, matches)
+			}
+
+			signup.Head.DOB = "1900-01-01"
+			matches, err = dbInstance.FindHouseholdMatches(ctx, signup)
+			if err != nil {
+				t.Fatalf("Failed to find matches: %v", err)
+			}
+			for _, m := range matches {
+				if m.Id == household.Id {
+					t.Errorf("Expected no match for a different DOB")
+				}
+			}
+		})
+	}
+}
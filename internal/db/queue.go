@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+func (db *FirestoreDB) queueOn(foodBankID string, date string) firestore.Query {
+	return db.Client.Collection("queuetickets").Where("FoodBankId", "==", foodBankID).Where("Date", "==", date)
+}
+
+// CheckIn records a household's visit and gives it the next number in the
+// queue of the visit's site that day. If the household booked the site that
+// day, the booking is marked visited and linked to the visit.
+func (db *FirestoreDB) CheckIn(ctx context.Context, householdID string, visit model.FoodBankVisit) (model.QueueTicket, error) {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	var ticket model.QueueTicket
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var booked *model.Booking
+		err := each(ctx, db, tx.Documents(db.bookingsOn(visit.FoodBankId, visit.Date).Where("HouseholdId", "==", householdID)), func(b model.Booking) error {
+			if booked == nil && b.CanBecome(model.BookingVisited) {
+				booked = &b
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var queue []model.QueueTicket
+		err = each(ctx, db, tx.Documents(db.queueOn(visit.FoodBankId, visit.Date)), func(t model.QueueTicket) error {
+			queue = append(queue, t)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		entry, _ := model.NewAuditEntry(actor, now, "foodbankvisits", visit.Id, nil, &visit)
+		if err := tx.Create(db.Client.Collection("foodbankvisits").Doc(visit.Id), visit); err != nil {
+			return err
+		}
+		if err := db.appendAudit(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		ticket = model.NewQueueTicket(visit, householdID, queue, now)
+		entry, _ = model.NewAuditEntry(actor, now, "queuetickets", ticket.Id, nil, &ticket)
+		if err := tx.Create(db.Client.Collection("queuetickets").Doc(ticket.Id), ticket); err != nil {
+			return err
+		}
+		if err := db.appendAudit(ctx, tx, entry); err != nil {
+			return err
+		}
+		if booked == nil {
+			return nil
+		}
+
+		updated := *booked
+		updated.Status, updated.VisitId = model.BookingVisited, visit.Id
+		entry, _ = model.NewAuditEntry(actor, now, "bookings", booked.Id, booked, &updated)
+		if err := tx.Set(db.Client.Collection("bookings").Doc(booked.Id), updated); err != nil {
+			return err
+		}
+		return db.appendAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return model.QueueTicket{}, fmt.Errorf("error checking in household %s: %w", householdID, err)
+	}
+	return ticket, nil
+}
+
+// GetQueue retrieves the tickets given out at a site on a date, by number.
+func (db *FirestoreDB) GetQueue(ctx context.Context, foodBankID string, date string) ([]model.QueueTicket, error) {
+	var tickets []model.QueueTicket
+	err := each(ctx, db, db.queueOn(foodBankID, date).Documents(ctx), func(t model.QueueTicket) error {
+		tickets = append(tickets, t)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving queue of site %s on %s: %w", foodBankID, date, err)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].Number < tickets[j].Number })
+	return tickets, nil
+}
+
+// SetTicketStatus calls a household in the queue, or records that it was
+// served or left. It fails if the ticket can't change to status, as
+// model.QueueTicket.CanBecome tells.
+func (db *FirestoreDB) SetTicketStatus(ctx context.Context, id string, status string) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	ref := db.Client.Collection("queuetickets").Doc(id)
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var ticket model.QueueTicket
+		if err := db.decode(ctx, doc, &ticket); err != nil {
+			return err
+		}
+		return db.moveTicket(ctx, tx, actor, now, ticket, status)
+	})
+	if err != nil {
+		return fmt.Errorf("error setting queue ticket %s to %s: %w", id, status, err)
+	}
+	return nil
+}
+
+// CallNext calls the household waiting with the lowest number at a site on a
+// date to be served, returning its ticket, or false if nobody is waiting.
+func (db *FirestoreDB) CallNext(ctx context.Context, foodBankID string, date string) (model.QueueTicket, bool, error) {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	var next model.QueueTicket
+	var found bool
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var queue []model.QueueTicket
+		err := each(ctx, db, tx.Documents(db.queueOn(foodBankID, date)), func(t model.QueueTicket) error {
+			queue = append(queue, t)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if next, found = model.NextInQueue(queue); !found {
+			return nil
+		}
+		return db.moveTicket(ctx, tx, actor, now, next, model.QueueServing)
+	})
+	if err != nil {
+		return model.QueueTicket{}, false, fmt.Errorf("error calling next in queue of site %s: %w", foodBankID, err)
+	}
+	return next, found, nil
+}
+
+func (db *FirestoreDB) moveTicket(ctx context.Context, tx *firestore.Transaction, actor string, now time.Time, ticket model.QueueTicket, status string) error {
+	if !ticket.CanBecome(status) {
+		return fmt.Errorf("ticket is %s", ticket.Status)
+	}
+	updated := ticket.Become(status, now)
+	entry, _ := model.NewAuditEntry(actor, now, "queuetickets", ticket.Id, &ticket, &updated)
+	if err := tx.Set(db.Client.Collection("queuetickets").Doc(ticket.Id), updated); err != nil {
+		return err
+	}
+	return db.appendAudit(ctx, tx, entry)
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+// RecertifyHousehold certifies a household again with the details given at
+// recertification, as model.Household.Recertify does, and adds the household
+// as it was to its certification history in the householdhistory collection.
+func (db *FirestoreDB) RecertifyHousehold(ctx context.Context, id string, with model.Household) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	ref := db.Client.Collection("households").Doc(id)
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var household model.Household
+		if err := db.decode(ctx, doc, &household); err != nil {
+			return err
+		}
+
+		recertified, snapshot := household.Recertify(with, now, actor)
+		entry, _ := model.NewAuditEntry(actor, now, "households", id, &household, &recertified)
+		entry.Action = model.AuditRecertify
+
+		sealed, err := db.seal(ctx, recertified)
+		if err != nil {
+			return err
+		}
+		if err := tx.Set(ref, sealed); err != nil {
+			return err
+		}
+		if sealed, err = db.seal(ctx, snapshot); err != nil {
+			return err
+		}
+		if err := tx.Create(db.Client.Collection("householdhistory").Doc(snapshot.Id), sealed); err != nil {
+			return err
+		}
+		return db.appendAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("error recertifying household with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetHouseholdHistory retrieves the snapshots taken of a household when it
+// was recertified, newest first.
+func (db *FirestoreDB) GetHouseholdHistory(ctx context.Context, id string) ([]model.HouseholdSnapshot, error) {
+	var snapshots []model.HouseholdSnapshot
+	err := each(ctx, db, db.Client.Collection("householdhistory").Where("Household.Id", "==", id).Documents(ctx), func(s model.HouseholdSnapshot) error {
+		snapshots = append(snapshots, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving history of household %s: %w", id, err)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Id > snapshots[j].Id })
+	return snapshots, nil
+}
+
+// householdHistoryRefs returns the snapshots of a household, for erasing
+// them along with it.
+func (db *FirestoreDB) householdHistoryRefs(tx *firestore.Transaction, id string) ([]*firestore.DocumentRef, error) {
+	docs, err := tx.Documents(db.Client.Collection("householdhistory").Where("Household.Id", "==", id)).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*firestore.DocumentRef, len(docs))
+	for i, doc := range docs {
+		refs[i] = doc.Ref
+	}
+	return refs, nil
+}
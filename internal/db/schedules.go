@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+func (db *FirestoreDB) PutReportSchedule(ctx context.Context, schedule model.ReportSchedule) error {
+	err := audited(ctx, db, "reportschedules", []write[model.ReportSchedule]{set(schedule.Id, schedule)})
+	if err != nil {
+		return fmt.Errorf("error saving report schedule: %w", err)
+	}
+	return nil
+}
+
+func (db *FirestoreDB) GetReportSchedule(ctx context.Context, id string) (*model.ReportSchedule, error) {
+	doc, err := db.Client.Collection("reportschedules").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving report schedule with ID %s: %w", id, err)
+	}
+
+	var schedule model.ReportSchedule
+	if err := doc.DataTo(&schedule); err != nil {
+		return nil, fmt.Errorf("error parsing report schedule data for ID %s: %w", id, err)
+	}
+
+	return &schedule, nil
+}
+
+// GetReportSchedules retrieves all report schedules ordered by name.
+func (db *FirestoreDB) GetReportSchedules(ctx context.Context) ([]model.ReportSchedule, error) {
+	var schedules []model.ReportSchedule
+	err := each(ctx, db, db.Client.Collection("reportschedules").OrderBy("Name", firestore.Asc).Documents(ctx), func(s model.ReportSchedule) error {
+		schedules = append(schedules, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving report schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (db *FirestoreDB) DeleteReportSchedule(ctx context.Context, id string) error {
+	err := audited(ctx, db, "reportschedules", []write[model.ReportSchedule]{del[model.ReportSchedule](id)})
+	if err != nil {
+		return fmt.Errorf("error deleting report schedule with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// ClaimReportSchedule moves a due schedule's NextRunAt from due to next in a
+// transaction. It returns false if another server instance claimed the run
+// first, so each run is delivered once however many instances are running.
+func (db *FirestoreDB) ClaimReportSchedule(ctx context.Context, id string, due time.Time, next time.Time) (bool, error) {
+	ref := db.Client.Collection("reportschedules").Doc(id)
+	claimed := false
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		claimed = false
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var schedule model.ReportSchedule
+		if err := doc.DataTo(&schedule); err != nil {
+			return err
+		}
+		if !schedule.NextRunAt.Equal(due) {
+			return nil
+		}
+		claimed = true
+		return tx.Update(ref, []firestore.Update{{Path: "NextRunAt", Value: next}})
+	})
+	if err != nil {
+		return false, fmt.Errorf("error claiming report schedule with ID %s: %w", id, err)
+	}
+	return claimed, nil
+}
+
+// FinishReportSchedule records the outcome of a run. Like the claim, this is
+// bookkeeping by the scheduler and is not written to the audit log.
+func (db *FirestoreDB) FinishReportSchedule(ctx context.Context, id string, ranAt time.Time, runErr string) error {
+	_, err := db.Client.Collection("reportschedules").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "LastRunAt", Value: ranAt},
+		{Path: "LastError", Value: runErr},
+	})
+	if err != nil {
+		return fmt.Errorf("error updating report schedule with ID %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+func (db *FirestoreDB) PutPickupSlot(ctx context.Context, slot model.PickupSlot) error {
+	err := audited(ctx, db, "pickupslots", []write[model.PickupSlot]{set(slot.Id, slot)})
+	if err != nil {
+		return fmt.Errorf("error saving pickup slot: %w", err)
+	}
+	return nil
+}
+
+func (db *FirestoreDB) GetPickupSlot(ctx context.Context, id string) (*model.PickupSlot, error) {
+	doc, err := db.Client.Collection("pickupslots").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pickup slot with ID %s: %w", id, err)
+	}
+	var slot model.PickupSlot
+	if err := db.decode(ctx, doc, &slot); err != nil {
+		return nil, fmt.Errorf("error parsing pickup slot data for ID %s: %w", id, err)
+	}
+	return &slot, nil
+}
+
+// GetPickupSlots retrieves a site's pickup slots in weekly order, from
+// Sunday's earliest.
+func (db *FirestoreDB) GetPickupSlots(ctx context.Context, foodBankID string) ([]model.PickupSlot, error) {
+	var slots []model.PickupSlot
+	err := each(ctx, db, db.Client.Collection("pickupslots").Where("FoodBankId", "==", foodBankID).Documents(ctx), func(s model.PickupSlot) error {
+		slots = append(slots, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pickup slots of site %s: %w", foodBankID, err)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Weekday != slots[j].Weekday {
+			return slots[i].Weekday < slots[j].Weekday
+		}
+		return slots[i].Start < slots[j].Start
+	})
+	return slots, nil
+}
+
+// DeletePickupSlot removes a slot. Bookings already made for it are kept.
+func (db *FirestoreDB) DeletePickupSlot(ctx context.Context, id string) error {
+	err := audited(ctx, db, "pickupslots", []write[model.PickupSlot]{del[model.PickupSlot](id)})
+	if err != nil {
+		return fmt.Errorf("error deleting pickup slot with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// BookPickup books a place in a pickup slot for a household, filling in the
+// slot's site and times and who booked it. The slot's bookings are counted
+// in the same transaction, so two bookings can't take its last place. It
+// returns the reasons the booking can't be made, as
+// model.PickupSlot.CheckBooking does, without saving anything.
+func (db *FirestoreDB) BookPickup(ctx context.Context, booking model.Booking) (model.ValidationErrors, error) {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	today := now.In(model.Location()).Format("2006-01-02")
+	var errs model.ValidationErrors
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		errs = nil
+		doc, err := tx.Get(db.Client.Collection("pickupslots").Doc(booking.SlotId))
+		if err != nil {
+			return err
+		}
+		var slot model.PickupSlot
+		if err := db.decode(ctx, doc, &slot); err != nil {
+			return err
+		}
+		var existing []model.Booking
+		err = each(ctx, db, tx.Documents(db.bookingsOn(slot.FoodBankId, booking.Date)), func(b model.Booking) error {
+			existing = append(existing, b)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if errs = slot.CheckBooking(booking, existing, today); errs.HasErrors() {
+			return nil
+		}
+
+		booking.FoodBankId, booking.Start, booking.End = slot.FoodBankId, slot.Start, slot.End
+		booking.Status = model.BookingBooked
+		booking.BookedAt, booking.BookedBy = now, actor
+		entry, _ := model.NewAuditEntry(actor, now, "bookings", booking.Id, nil, &booking)
+		if err := tx.Create(db.Client.Collection("bookings").Doc(booking.Id), booking); err != nil {
+			return err
+		}
+		return db.appendAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error booking pickup slot %s: %w", booking.SlotId, err)
+	}
+	return errs, nil
+}
+
+func (db *FirestoreDB) bookingsOn(foodBankID string, date string) firestore.Query {
+	return db.Client.Collection("bookings").Where("FoodBankId", "==", foodBankID).Where("Date", "==", date)
+}
+
+func (db *FirestoreDB) GetBooking(ctx context.Context, id string) (*model.Booking, error) {
+	doc, err := db.Client.Collection("bookings").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving booking with ID %s: %w", id, err)
+	}
+	var booking model.Booking
+	if err := db.decode(ctx, doc, &booking); err != nil {
+		return nil, fmt.Errorf("error parsing booking data for ID %s: %w", id, err)
+	}
+	return &booking, nil
+}
+
+// GetBookings retrieves a site's bookings on a date, cancelled ones
+// included, by time and then in the order they were made.
+func (db *FirestoreDB) GetBookings(ctx context.Context, foodBankID string, date string) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := each(ctx, db, db.bookingsOn(foodBankID, date).Documents(ctx), func(b model.Booking) error {
+		bookings = append(bookings, b)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving bookings of site %s on %s: %w", foodBankID, date, err)
+	}
+	sort.Slice(bookings, func(i, j int) bool {
+		if bookings[i].Start != bookings[j].Start {
+			return bookings[i].Start < bookings[j].Start
+		}
+		return bookings[i].Id < bookings[j].Id
+	})
+	return bookings, nil
+}
+
+// GetHouseholdBookings retrieves a household's bookings, latest date first.
+func (db *FirestoreDB) GetHouseholdBookings(ctx context.Context, householdID string) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := each(ctx, db, db.Client.Collection("bookings").Where("HouseholdId", "==", householdID).Documents(ctx), func(b model.Booking) error {
+		bookings = append(bookings, b)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving bookings of household %s: %w", householdID, err)
+	}
+	sort.Slice(bookings, func(i, j int) bool {
+		if bookings[i].Date != bookings[j].Date {
+			return bookings[i].Date > bookings[j].Date
+		}
+		return bookings[i].Start > bookings[j].Start
+	})
+	return bookings, nil
+}
+
+// SetBookingStatus cancels a booking or marks it a no-show. It fails if the
+// booking can't change to status, as model.Booking.CanBecome tells.
+func (db *FirestoreDB) SetBookingStatus(ctx context.Context, id string, status string) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	ref := db.Client.Collection("bookings").Doc(id)
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var booking model.Booking
+		if err := db.decode(ctx, doc, &booking); err != nil {
+			return err
+		}
+		if !booking.CanBecome(status) {
+			return fmt.Errorf("booking is %s", booking.Status)
+		}
+
+		updated := booking
+		updated.Status = status
+		entry, _ := model.NewAuditEntry(actor, now, "bookings", id, &booking, &updated)
+		if err := tx.Set(ref, updated); err != nil {
+			return err
+		}
+		return db.appendAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("error setting booking %s to %s: %w", id, status, err)
+	}
+	return nil
+}
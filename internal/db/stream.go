@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// EachHousehold calls fn for every household, reading one document at a time
+// so callers such as exports don't hold the whole collection in memory.
+func (db *FirestoreDB) EachHousehold(ctx context.Context, fn func(model.Household) error) error {
+	return each(ctx, db, db.Client.Collection("households").OrderBy("Id", firestore.Asc).Documents(ctx), fn)
+}
+
+// EachFoodBankVisit calls fn for every visit dated between from and to
+// inclusive, both formatted as 2006-01-02.
+func (db *FirestoreDB) EachFoodBankVisit(ctx context.Context, from string, to string, fn func(model.FoodBankVisit) error) error {
+	query := db.Client.Collection("foodbankvisits").
+		Where("Date", ">=", from).
+		Where("Date", "<=", to).
+		OrderBy("Date", firestore.Asc)
+	return each(ctx, db, query.Documents(ctx), fn)
+}
+
+// inQueryLimit is the most values a Firestore "in" filter takes.
+const inQueryLimit = 30
+
+// VisitedBefore returns which of personIDs have a visit at any site dated
+// before the given 2006-01-02 date. Only the visits of those people are read,
+// looked up in batches.
+func (db *FirestoreDB) VisitedBefore(ctx context.Context, personIDs []string, before string) (map[string]bool, error) {
+	visited := map[string]bool{}
+	for start := 0; start < len(personIDs); start += inQueryLimit {
+		batch := personIDs[start:min(start+inQueryLimit, len(personIDs))]
+		// dates are compared here rather than in the query, which would need
+		// a composite index
+		query := db.Client.Collection("foodbankvisits").Where("PersonId", "in", batch)
+		err := each(ctx, db, query.Documents(ctx), func(v model.FoodBankVisit) error {
+			if v.Date < before {
+				visited[v.PersonId] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return visited, nil
+}
+
+func each[T any](ctx context.Context, db *FirestoreDB, iter *firestore.DocumentIterator, fn func(T) error) error {
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error retrieving documents: %w", err)
+		}
+
+		var v T
+		if err := db.decode(ctx, doc, &v); err != nil {
+			return fmt.Errorf("error parsing document %s: %w", doc.Ref.ID, err)
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/model"
+
+	"cloud.google.com/go/firestore"
+)
+
+// DeleteHousehold moves a household to the deletedhouseholds collection,
+// recording who deleted it and when. Deleted households no longer appear in
+// lists, lookups or exports, but can be restored until they are purged.
+func (db *FirestoreDB) DeleteHousehold(ctx context.Context, id string) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	ref := db.Client.Collection("households").Doc(id)
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var household model.Household
+		if err := db.decode(ctx, doc, &household); err != nil {
+			return err
+		}
+
+		var before, after any = household, model.DeletedHousehold{Household: household, DeletedAt: now, DeletedBy: actor}
+		entry, _ := model.NewAuditEntry(actor, now, "households", id, &before, &after)
+		entry.Action = model.AuditDelete
+
+		sealed, err := db.seal(ctx, after)
+		if err != nil {
+			return err
+		}
+		if err := tx.Set(db.Client.Collection("deletedhouseholds").Doc(id), sealed); err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		return db.appendAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting household with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// RestoreHousehold moves a deleted household back into the households collection.
+func (db *FirestoreDB) RestoreHousehold(ctx context.Context, id string) error {
+	actor := ActorFrom(ctx)
+	now := time.Now()
+	ref := db.Client.Collection("deletedhouseholds").Doc(id)
+
+	err := db.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var deleted model.DeletedHousehold
+		if err := db.decode(ctx, doc, &deleted); err != nil {
+			return err
+		}
+
+		var before, after any = deleted, deleted.Household
+		entry, _ := model.NewAuditEntry(actor, now, "households", id, &before, &after)
+		entry.Action = model.AuditRestore
+
+		sealed, err := db.seal(ctx, deleted.Household)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(db.Client.Collection("households").Doc(id), sealed); err != nil {
+			return err
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		return db.appendAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("error restoring household with ID %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetDeletedHouseholds retrieves deleted households, most recently deleted first.
+func (db *FirestoreDB) GetDeletedHouseholds(ctx context.Context) ([]model.DeletedHousehold, error) {
+	var households []model.DeletedHousehold
+	err := each(ctx, db, db.Client.Collection("deletedhouseholds").OrderBy("DeletedAt", firestore.Desc).Documents(ctx), func(h model.DeletedHousehold) error {
+		households = append(households, h)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deleted households: %w", err)
+	}
+	return households, nil
+}
+
+// PurgeDeletedHouseholds permanently removes households deleted before the
+// given time and returns how many were removed.
+func (db *FirestoreDB) PurgeDeletedHouseholds(ctx context.Context, before time.Time) (int, error) {
+	var ids []string
+	err := each(ctx, db, db.Client.Collection("deletedhouseholds").Where("DeletedAt", "<", before).Documents(ctx), func(h model.DeletedHousehold) error {
+		ids = append(ids, h.Id)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error finding households to purge: %w", err)
+	}
+
+	for i, id := range ids {
+		if err := db.PurgeHousehold(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodbank/internal/model"
+)
+
+func TestFirestoreDB_DeleteAndRestoreHousehold(t *testing.T) {
+	dbInstance := newFirestoreDB(t)
+	ctx := WithActor(context.Background(), "tester")
+
+	households, err := model.GenerateHouseholds(1)
+	if err != nil {
+		t.Fatalf("Failed to generate household: %v", err)
+	}
+	household := households[0]
+	if err := dbInstance.PutHousehold(ctx, household); err != nil {
+		t.Fatalf("Failed to put household: %v", err)
+	}
+
+	if err := dbInstance.DeleteHousehold(ctx, household.Id); err != nil {
+		t.Fatalf("Failed to delete household: %v", err)
+	}
+	if _, err := dbInstance.GetHouseholdByID(ctx, household.Id); !IsNotFound(err) {
+		t.Errorf("Expected deleted household to be hidden, got %v", err)
+	}
+
+	deleted, err := dbInstance.GetDeletedHouseholds(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get deleted households: %v", err)
+	}
+	found := false
+	for _, h := range deleted {
+		if h.Id == household.Id {
+			found = true
+			if h.DeletedBy != "tester" || h.DeletedAt.IsZero() {
+				t.Errorf("Expected deletion by tester with a time, got %q at %v", h.DeletedBy, h.DeletedAt)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("Deleted household %s not listed", household.Id)
+	}
+
+	if err := dbInstance.RestoreHousehold(ctx, household.Id); err != nil {
+		t.Fatalf("Failed to restore household: %v", err)
+	}
+	restored, err := dbInstance.GetHouseholdByID(ctx, household.Id)
+	if err != nil {
+		t.Fatalf("Failed to get restored household: %v", err)
+	}
+	if restored.Head.LastName != household.Head.LastName {
+		t.Errorf("Expected restored head %s, got %s", household.Head.LastName, restored.Head.LastName)
+	}
+}
+
+func TestFirestoreDB_PurgeDeletedHouseholds(t *testing.T) {
+	dbInstance := newFirestoreDB(t)
+	ctx := context.Background()
+
+	households, err := model.GenerateHouseholds(1)
+	if err != nil {
+		t.Fatalf("Failed to generate household: %v", err)
+	}
+	if err := dbInstance.PutHousehold(ctx, households[0]); err != nil {
+		t.Fatalf("Failed to put household: %v", err)
+	}
+	if err := dbInstance.DeleteHousehold(ctx, households[0].Id); err != nil {
+		t.Fatalf("Failed to delete household: %v", err)
+	}
+
+	if _, err := dbInstance.PurgeDeletedHouseholds(ctx, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if err := dbInstance.RestoreHousehold(ctx, households[0].Id); err == nil {
+		t.Error("Expected restoring a purged household to fail")
+	}
+}
@@ -0,0 +1,198 @@
+// Package export writes households, persons and visits as CSV or XLSX one row
+// at a time, so exports never need the whole collection in memory.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"foodbank/internal/model"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// RowWriter writes a table one row at a time. Close must be called to
+// flush buffered rows and finish the file.
+type RowWriter interface {
+	WriteRow(row []string) error
+	Close() error
+}
+
+// NewRowWriter returns a RowWriter producing the given format.
+func NewRowWriter(w io.Writer, format string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ContentType returns the MIME type for format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// WriteRow prefixes cells that a spreadsheet would treat as a formula with a
+// quote, since signup data is entered by the public.
+func (c *csvWriter) WriteRow(row []string) error {
+	safe := make([]string, len(row))
+	for i, v := range row {
+		if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+			v = "'" + v
+		}
+		safe[i] = v
+	}
+	return c.w.Write(safe)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Column is one exported column. Key is the value used to select the column
+// and matches the json tag of the underlying field where there is one.
+type Column[T any] struct {
+	Key    string
+	Header string
+	Value  func(T) string
+}
+
+// SelectColumns returns the columns named by keys, in the order given, or all
+// columns when keys is empty.
+func SelectColumns[T any](all []Column[T], keys []string) ([]Column[T], error) {
+	if len(keys) == 0 {
+		return all, nil
+	}
+	selected := make([]Column[T], 0, len(keys))
+	for _, key := range keys {
+		found := false
+		for _, col := range all {
+			if col.Key == key {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", key)
+		}
+	}
+	return selected, nil
+}
+
+// Table writes rows of T to a RowWriter using a fixed set of columns.
+type Table[T any] struct {
+	w       RowWriter
+	columns []Column[T]
+}
+
+// NewTable writes the header row and returns a Table for the remaining rows.
+func NewTable[T any](w RowWriter, columns []Column[T]) (*Table[T], error) {
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.Header
+	}
+	if err := w.WriteRow(headers); err != nil {
+		return nil, err
+	}
+	return &Table[T]{w: w, columns: columns}, nil
+}
+
+func (t *Table[T]) Write(v T) error {
+	row := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		row[i] = col.Value(v)
+	}
+	return t.w.WriteRow(row)
+}
+
+// PersonRow is a person exported one row per person, with the household they
+// belong to.
+type PersonRow struct {
+	HouseholdId string
+	Role        string
+	model.Person
+}
+
+// PersonRows returns one PersonRow for the head and each member of h.
+func PersonRows(h model.Household) []PersonRow {
+	rows := []PersonRow{{HouseholdId: h.Id, Role: "head", Person: h.Head}}
+	for _, m := range h.Members {
+		rows = append(rows, PersonRow{HouseholdId: h.Id, Role: "member", Person: m})
+	}
+	return rows
+}
+
+// personColumns are the PersonCommon fields shared by the household and
+// person exports.
+func personColumns[T any](person func(T) model.Person) []Column[T] {
+	field := func(key, header string, value func(model.PersonCommon) string) Column[T] {
+		return Column[T]{Key: key, Header: header, Value: func(v T) string { return value(person(v).PersonCommon) }}
+	}
+	return []Column[T]{
+		field("firstName", "First Name", func(p model.PersonCommon) string { return p.FirstName }),
+		field("lastName", "Last Name", func(p model.PersonCommon) string { return p.LastName }),
+		field("dob", "Date of Birth", func(p model.PersonCommon) string { return p.DOB }),
+		field("gender", "Gender", func(p model.PersonCommon) string { return p.Gender }),
+		field("race", "Race", func(p model.PersonCommon) string { return p.Race }),
+		field("language", "Language", func(p model.PersonCommon) string { return p.Language }),
+		field("relationship", "Relationship", func(p model.PersonCommon) string { return p.Relationship }),
+		field("email", "Email", func(p model.PersonCommon) string { return p.Email }),
+		field("phone", "Phone", func(p model.PersonCommon) string { return p.Phone }),
+		field("street", "Street", func(p model.PersonCommon) string { return p.Street }),
+		field("city", "City", func(p model.PersonCommon) string { return p.City }),
+		field("state", "State", func(p model.PersonCommon) string { return p.State }),
+		field("postalCode", "ZIP Code", func(p model.PersonCommon) string { return p.PostalCode }),
+	}
+}
+
+// HouseholdColumns export one row per household: the head's details followed
+// by the other members flattened into a single cell.
+var HouseholdColumns = append(append([]Column[model.Household]{
+	{Key: "id", Header: "Household ID", Value: func(h model.Household) string { return h.Id }},
+	{Key: "created", Header: "Created", Value: model.Household.Created},
+}, personColumns(func(h model.Household) model.Person { return h.Head })...),
+	Column[model.Household]{Key: "memberCount", Header: "Household Size", Value: func(h model.Household) string {
+		return strconv.Itoa(h.Size())
+	}},
+	Column[model.Household]{Key: "members", Header: "Other Members", Value: func(h model.Household) string {
+		members := make([]string, len(h.Members))
+		for i, m := range h.Members {
+			members[i] = fmt.Sprintf("%s %s (%s, %s)", m.FirstName, m.LastName, m.Relationship, m.DOB)
+		}
+		return strings.Join(members, "; ")
+	}},
+)
+
+// PersonColumns export one row per person.
+var PersonColumns = append([]Column[PersonRow]{
+	{Key: "householdId", Header: "Household ID", Value: func(r PersonRow) string { return r.HouseholdId }},
+	{Key: "role", Header: "Role", Value: func(r PersonRow) string { return r.Role }},
+	{Key: "id", Header: "Person ID", Value: func(r PersonRow) string { return r.Id }},
+}, personColumns(func(r PersonRow) model.Person { return r.Person })...)
+
+// VisitColumns export one row per food bank visit.
+var VisitColumns = []Column[model.FoodBankVisit]{
+	{Key: "id", Header: "Visit ID", Value: func(v model.FoodBankVisit) string { return v.Id }},
+	{Key: "date", Header: "Date", Value: func(v model.FoodBankVisit) string { return v.Date }},
+	{Key: "personId", Header: "Person ID", Value: func(v model.FoodBankVisit) string { return v.PersonId }},
+	{Key: "foodBankId", Header: "Food Bank ID", Value: func(v model.FoodBankVisit) string { return v.FoodBankId }},
+	{Key: "notes", Header: "Notes", Value: func(v model.FoodBankVisit) string { return v.Notes }},
+}
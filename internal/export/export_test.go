@@ -0,0 +1,123 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"foodbank/internal/model"
+)
+
+func TestHouseholdAndPersonRows(t *testing.T) {
+	households, err := model.GenerateHouseholds(1)
+	if err != nil {
+		t.Fatalf("Failed to generate households: %v", err)
+	}
+	h := households[0]
+
+	var buf bytes.Buffer
+	rw, _ := NewRowWriter(&buf, FormatCSV)
+	columns, err := SelectColumns(PersonColumns, []string{"householdId", "role", "firstName"})
+	if err != nil {
+		t.Fatalf("Failed to select columns: %v", err)
+	}
+	table, err := NewTable(rw, columns)
+	if err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	for _, row := range PersonRows(h) {
+		if err := table.Write(row); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != len(h.Members)+2 {
+		t.Fatalf("Expected %d rows, got %d", len(h.Members)+2, len(records))
+	}
+	if got := strings.Join(records[1], ","); got != h.Id+",head,"+h.Head.FirstName {
+		t.Errorf("Unexpected head row %q", got)
+	}
+}
+
+func TestSelectColumnsRejectsUnknown(t *testing.T) {
+	if _, err := SelectColumns(VisitColumns, []string{"date", "nope"}); err == nil {
+		t.Errorf("Expected error for unknown column")
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	rw, _ := NewRowWriter(&buf, FormatCSV)
+	rw.WriteRow([]string{"=HYPERLINK(\"x\")", "Smith"})
+	rw.Close()
+
+	if got := buf.String(); !strings.HasPrefix(got, `"'=HYPERLINK(""x"")",Smith`) {
+		t.Errorf("Expected formula to be quoted, got %q", got)
+	}
+}
+
+func TestXLSXIsReadable(t *testing.T) {
+	var buf bytes.Buffer
+	rw, err := NewRowWriter(&buf, FormatXLSX)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	rw.WriteRow([]string{"Name", "Notes"})
+	rw.WriteRow([]string{"Ana", "<needs> & wants"})
+	if err := rw.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+	var sheet []byte
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			sheet, _ = io.ReadAll(r)
+		}
+	}
+	if sheet == nil {
+		t.Fatalf("Worksheet missing from workbook")
+	}
+
+	var parsed struct {
+		Rows []struct {
+			Cells []struct {
+				Ref  string `xml:"r,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(sheet, &parsed); err != nil {
+		t.Fatalf("Worksheet is not valid XML: %v", err)
+	}
+	if len(parsed.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(parsed.Rows))
+	}
+	cell := parsed.Rows[1].Cells[1]
+	if cell.Ref != "B2" || cell.Text != "<needs> & wants" {
+		t.Errorf("Unexpected cell %+v", cell)
+	}
+}
+
+func TestCellRef(t *testing.T) {
+	for col, want := range map[int]string{0: "A1", 25: "Z1", 26: "AA1", 701: "ZZ1", 702: "AAA1"} {
+		if got := cellRef(col, 1); got != want {
+			t.Errorf("cellRef(%d) = %s, want %s", col, got, want)
+		}
+	}
+}
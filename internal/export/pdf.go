@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pdfLinesPerPage = 60
+	pdfFontSize     = 10
+	pdfLineHeight   = 12
+	pdfLeft         = 40
+	pdfTop          = 760
+)
+
+// WritePDF writes lines as a plain US Letter PDF in a monospaced font, so
+// reports laid out with padded columns keep their alignment. Text outside
+// Latin-1 is replaced with "?".
+func WritePDF(w io.Writer, lines []string) error {
+	pages := (len(lines) + pdfLinesPerPage - 1) / pdfLinesPerPage
+	if pages == 0 {
+		pages = 1
+	}
+
+	bw := bufio.NewWriter(w)
+	offsets := []int{}
+	written := 0
+	put := func(format string, args ...any) {
+		n, _ := fmt.Fprintf(bw, format, args...)
+		written += n
+	}
+	// Objects: 1 catalog, 2 page tree, 3 font, then a page and content
+	// stream for each page.
+	object := func(body string) {
+		offsets = append(offsets, written)
+		put("%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	put("%%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i := 0; i < pages; i++ {
+		end := min((i+1)*pdfLinesPerPage, len(lines))
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfLeft, pdfTop)
+		for _, line := range lines[i*pdfLinesPerPage : end] {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := written
+	put("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		put("%010d 00000 n \n", off)
+	}
+	put("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return bw.Flush()
+}
+
+// pdfEscape encodes s as the body of a PDF literal string in Latin-1.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// AlignColumns pads the cells of rows so each column lines up in a
+// monospaced font.
+func AlignColumns(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-len([]rune(cell))+2))
+			}
+		}
+		lines[r] = b.String()
+	}
+	return lines
+}
+
+// WriteCSV writes rows to w as CSV.
+func WriteCSV(w io.Writer, rows [][]string) error {
+	rw := &csvWriter{w: csv.NewWriter(w)}
+	for _, row := range rows {
+		if err := rw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
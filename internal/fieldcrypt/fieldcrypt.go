@@ -0,0 +1,181 @@
+// Package fieldcrypt encrypts individual string fields with envelope
+// encryption: values are sealed with a random data key, and the data key is
+// wrapped by a key-encryption key held by a KeyProvider.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prefix marks encrypted values. The rest of the value is
+// "<key id>:<wrapped data key>:<nonce and ciphertext>", both base64 encoded.
+const prefix = "enc:v1:"
+
+// KeyProvider holds the key-encryption keys. Implementations may keep keys
+// locally or delegate wrapping to a key management service.
+type KeyProvider interface {
+	// CurrentKeyID names the key new data keys are wrapped with.
+	CurrentKeyID() string
+	// WrapKey encrypts a data key with the named key.
+	WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key wrapped with the named key.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+	// IndexKey is the HMAC key for blind indexes. It is not rotated, since
+	// that would invalidate every stored index.
+	IndexKey() []byte
+}
+
+// Cipher encrypts and decrypts field values. A data key is generated per
+// process and per key-encryption key, and unwrapped data keys are cached.
+type Cipher struct {
+	keys KeyProvider
+
+	mu        sync.Mutex
+	current   *dataKey
+	unwrapped map[string]cipher.AEAD
+}
+
+type dataKey struct {
+	keyID   string
+	wrapped string
+	aead    cipher.AEAD
+}
+
+// New returns a Cipher using keys.
+func New(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys, unwrapped: map[string]cipher.AEAD{}}
+}
+
+// IsEncrypted reports whether s was produced by Encrypt.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// Encrypt seals s. Empty and already encrypted values are returned unchanged.
+func (c *Cipher) Encrypt(ctx context.Context, s string) (string, error) {
+	if s == "" || IsEncrypted(s) {
+		return s, nil
+	}
+	dk, err := c.dataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, dk.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := dk.aead.Seal(nonce, nonce, []byte(s), nil)
+	return prefix + dk.keyID + ":" + dk.wrapped + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Values without the encrypted
+// prefix are returned unchanged, so data written before encryption was
+// enabled can still be read.
+func (c *Cipher) Decrypt(ctx context.Context, s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(s, prefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, err := c.open(ctx, parts[0], parts[1])
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("error decrypting value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Current reports whether s is empty or encrypted under the current
+// key-encryption key, i.e. does not need rewriting after a key rotation.
+func (c *Cipher) Current(s string) bool {
+	return s == "" || strings.HasPrefix(s, prefix+c.keys.CurrentKeyID()+":")
+}
+
+// BlindIndex returns a keyed hash of s that can be stored and queried for
+// equality without revealing s. Callers normalize s first, e.g. lowercasing
+// email addresses.
+func (c *Cipher) BlindIndex(s string) string {
+	if s == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.keys.IndexKey())
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Cipher) dataKey(ctx context.Context) (*dataKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keyID := c.keys.CurrentKeyID()
+	if c.current != nil && c.current.keyID == keyID {
+		return c.current, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	wrapped, err := c.keys.WrapKey(ctx, keyID, key)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	c.current = &dataKey{keyID: keyID, wrapped: base64.RawStdEncoding.EncodeToString(wrapped), aead: aead}
+	c.unwrapped[keyID+":"+c.current.wrapped] = aead
+	return c.current, nil
+}
+
+func (c *Cipher) open(ctx context.Context, keyID string, wrapped string) (cipher.AEAD, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if aead, ok := c.unwrapped[keyID+":"+wrapped]; ok {
+		return aead, nil
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, errors.New("malformed wrapped data key")
+	}
+	key, err := c.keys.UnwrapKey(ctx, keyID, raw)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	c.unwrapped[keyID+":"+wrapped] = aead
+	return aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
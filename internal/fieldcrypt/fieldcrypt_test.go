@@ -0,0 +1,120 @@
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newCipher(t *testing.T) (*Cipher, KeyFile) {
+	t.Helper()
+	file, err := NewKeyFile("k1")
+	if err != nil {
+		t.Fatalf("NewKeyFile: %v", err)
+	}
+	keys, err := NewLocalKeyProvider(file)
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider: %v", err)
+	}
+	return New(keys), file
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newCipher(t)
+
+	enc, err := c.Encrypt(ctx, "1980-01-02")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "1980") {
+		t.Errorf("Encrypt() = %q, want an opaque encrypted value", enc)
+	}
+	if again, _ := c.Encrypt(ctx, enc); again != enc {
+		t.Error("Encrypt should leave encrypted values unchanged")
+	}
+
+	dec, err := c.Decrypt(ctx, enc)
+	if err != nil || dec != "1980-01-02" {
+		t.Errorf("Decrypt() = %q, %v", dec, err)
+	}
+	if dec, _ := c.Decrypt(ctx, "plain"); dec != "plain" {
+		t.Errorf("Decrypt(plain) = %q, want it unchanged", dec)
+	}
+	if enc, _ := c.Encrypt(ctx, ""); enc != "" {
+		t.Errorf("Encrypt(\"\") = %q, want \"\"", enc)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	ctx := context.Background()
+	old, file := newCipher(t)
+	enc, err := old.Encrypt(ctx, "555-1234")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	rotated, err := NewKeyFile("k2")
+	if err != nil {
+		t.Fatalf("NewKeyFile: %v", err)
+	}
+	file.Keys["k2"] = rotated.Keys["k2"]
+	file.Current = "k2"
+	keys, err := NewLocalKeyProvider(file)
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider: %v", err)
+	}
+	c := New(keys)
+
+	if c.Current(enc) {
+		t.Error("value under the old key should not be current")
+	}
+	if dec, err := c.Decrypt(ctx, enc); err != nil || dec != "555-1234" {
+		t.Errorf("Decrypt() after rotation = %q, %v", dec, err)
+	}
+	reenc, err := c.Encrypt(ctx, "555-1234")
+	if err != nil || !c.Current(reenc) {
+		t.Errorf("re-encrypted value %q should be current (%v)", reenc, err)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	c, _ := newCipher(t)
+	other, _ := newCipher(t)
+
+	a := c.BlindIndex("ana@example.com")
+	if a == "" || a != c.BlindIndex("ana@example.com") {
+		t.Error("BlindIndex should be deterministic")
+	}
+	if a == c.BlindIndex("bo@example.com") || a == other.BlindIndex("ana@example.com") {
+		t.Error("BlindIndex should depend on the value and the index key")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	file, err := NewKeyFile("dev")
+	if err != nil {
+		t.Fatalf("NewKeyFile: %v", err)
+	}
+	data, _ := json.Marshal(file)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile: %v", err)
+	}
+	if keys.CurrentKeyID() != "dev" {
+		t.Errorf("CurrentKeyID() = %q, want dev", keys.CurrentKeyID())
+	}
+
+	file.Current = "missing"
+	if _, err := NewLocalKeyProvider(file); err == nil {
+		t.Error("expected an error for a missing current key")
+	}
+}
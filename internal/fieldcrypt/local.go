@@ -0,0 +1,107 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyFile is the JSON layout read by LoadKeyFile. Keys are base64 encoded
+// 32 byte AES keys. To rotate, add a key and make it current; keep old keys
+// until every value has been rewritten under the new one.
+type KeyFile struct {
+	Current  string            `json:"current"`
+	Keys     map[string][]byte `json:"keys"`
+	IndexKey []byte            `json:"indexKey"`
+}
+
+// LocalKeyProvider wraps data keys with keys read from a local file. It is
+// meant for development; production should use a key management service.
+type LocalKeyProvider struct {
+	file  KeyFile
+	aeads map[string]cipher.AEAD
+}
+
+// LoadKeyFile reads a KeyFile from path.
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key file: %w", err)
+	}
+	var file KeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing key file %s: %w", path, err)
+	}
+	return NewLocalKeyProvider(file)
+}
+
+// NewLocalKeyProvider checks file and returns a provider for its keys.
+func NewLocalKeyProvider(file KeyFile) (*LocalKeyProvider, error) {
+	if _, ok := file.Keys[file.Current]; !ok {
+		return nil, fmt.Errorf("current key %q not found", file.Current)
+	}
+	if len(file.IndexKey) < 32 {
+		return nil, errors.New("index key must be at least 32 bytes")
+	}
+	p := &LocalKeyProvider{file: file, aeads: map[string]cipher.AEAD{}}
+	for id, key := range file.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		p.aeads[id] = aead
+	}
+	return p, nil
+}
+
+// NewKeyFile returns a KeyFile with one fresh key named keyID.
+func NewKeyFile(keyID string) (KeyFile, error) {
+	key := make([]byte, 32)
+	index := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return KeyFile{}, err
+	}
+	if _, err := rand.Read(index); err != nil {
+		return KeyFile{}, err
+	}
+	return KeyFile{Current: keyID, Keys: map[string][]byte{keyID: key}, IndexKey: index}, nil
+}
+
+func (p *LocalKeyProvider) CurrentKeyID() string {
+	return p.file.Current
+}
+
+func (p *LocalKeyProvider) IndexKey() []byte {
+	return p.file.IndexKey
+}
+
+func (p *LocalKeyProvider) WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	aead, ok := p.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+func (p *LocalKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("wrapped key too short")
+	}
+	return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], nil)
+}
@@ -0,0 +1,329 @@
+// Package importer turns client lists exported from spreadsheets into
+// households. Parsing is separate from writing so staff can review a dry-run
+// report of every row before anything is saved.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// BatchSize is the number of households written per transaction; each one is
+// written together with its audit entry.
+const BatchSize = db.MaxAuditedWrites
+
+// Field keys that are not PersonCommon fields.
+const (
+	FieldIgnore    = ""
+	FieldHousehold = "household"
+	FieldRole      = "role"
+)
+
+// Fields lists the keys a CSV column can be mapped to, using the PersonCommon
+// json tags, plus household grouping and role.
+var Fields = []string{
+	FieldHousehold, FieldRole,
+	"firstName", "lastName", "dob", "gender", "race", "language", "relationship",
+	"email", "phone", "street", "city", "state", "postalCode",
+}
+
+// aliases maps normalized legacy header names to field keys.
+var aliases = map[string]string{
+	"householdid": FieldHousehold, "family": FieldHousehold, "familyid": FieldHousehold, "case": FieldHousehold,
+	"first": "firstName", "given": "firstName", "givenname": "firstName",
+	"last": "lastName", "surname": "lastName", "familyname": "lastName",
+	"dateofbirth": "dob", "birthdate": "dob", "birthday": "dob",
+	"sex": "gender", "ethnicity": "race", "primarylanguage": "language",
+	"relation": "relationship", "emailaddress": "email", "phonenumber": "phone", "telephone": "phone",
+	"address": "street", "streetaddress": "street", "zip": "postalCode", "zipcode": "postalCode",
+}
+
+// Mapping assigns a field key to each CSV column by index. FieldIgnore
+// skips the column.
+type Mapping []string
+
+// GuessMapping proposes a field for each header by matching field keys and
+// common legacy header names, ignoring case, spaces and punctuation.
+func GuessMapping(headers []string) Mapping {
+	m := make(Mapping, len(headers))
+	for i, h := range headers {
+		n := normalize(h)
+		for _, f := range Fields {
+			if normalize(f) == n {
+				m[i] = f
+			}
+		}
+		if m[i] == FieldIgnore {
+			m[i] = aliases[n]
+		}
+	}
+	return m
+}
+
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// RowResult reports the outcome for one data row. Line is the 1-based line
+// in the CSV file, counting the header.
+type RowResult struct {
+	Line      int
+	Household string
+	Errors    model.ValidationErrors
+}
+
+// Result is the dry-run report for a file. Households holds only the
+// households whose rows are all valid; any error excludes the whole household.
+type Result struct {
+	Headers    []string
+	Rows       []RowResult
+	Households []model.Household
+	Skipped    int
+}
+
+// ErrorCount returns the number of rows with errors.
+func (r *Result) ErrorCount() int {
+	n := 0
+	for _, row := range r.Rows {
+		if row.Errors.HasErrors() {
+			n++
+		}
+	}
+	return n
+}
+
+type group struct {
+	key     string
+	lines   []int
+	persons []model.Person
+	roles   []string
+}
+
+// Parse reads a CSV file with a header row and groups rows into households.
+// Rows sharing a household column value form one household, whose head is
+// the row with role "head" (or relationship "self"), else the first row. Without
+// a household column every row is its own household.
+func Parse(r io.Reader, mapping Mapping) (*Result, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	headers, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	if len(mapping) != len(headers) {
+		return nil, fmt.Errorf("mapping has %d columns but file has %d", len(mapping), len(headers))
+	}
+
+	result := &Result{Headers: headers}
+	groups := map[string]*group{}
+	var order []*group
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV line %d: %w", line, err)
+		}
+		if isBlank(record) {
+			continue
+		}
+
+		person, key, role, errs := toPerson(record, mapping)
+		if key == "" {
+			key = fmt.Sprintf("line %d", line)
+		}
+		result.Rows = append(result.Rows, RowResult{Line: line, Household: key, Errors: errs})
+
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.lines = append(g.lines, line)
+		g.persons = append(g.persons, person)
+		g.roles = append(g.roles, role)
+	}
+
+	rowIndex := map[int]int{}
+	for i, row := range result.Rows {
+		rowIndex[row.Line] = i
+	}
+
+	for _, g := range order {
+		household, headLine := g.household()
+		for _, e := range household.Validate() {
+			// members' errors were reported on their own rows by toPerson
+			if strings.HasPrefix(e.Field, "members.") {
+				continue
+			}
+			row := &result.Rows[rowIndex[headLine]]
+			e.Field = strings.TrimPrefix(e.Field, "head.")
+			if !hasField(row.Errors, e.Field) {
+				row.Errors = append(row.Errors, e)
+			}
+		}
+
+		valid := true
+		for _, line := range g.lines {
+			if result.Rows[rowIndex[line]].Errors.HasErrors() {
+				valid = false
+			}
+		}
+		if valid {
+			result.Households = append(result.Households, household)
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// household builds the household for g, returning it with the CSV line of its head.
+func (g *group) household() (model.Household, int) {
+	head := 0
+	for i, role := range g.roles {
+		if role == "head" {
+			head = i
+			break
+		}
+	}
+
+	h := model.Household{Id: ulid.Make().String(), Head: g.persons[head], Members: []model.Person{}}
+	for i, p := range g.persons {
+		if i != head {
+			h.Members = append(h.Members, p)
+		}
+	}
+	return h, g.lines[head]
+}
+
+// toPerson maps a record to a person and validates it with Person.Validate.
+// Email is optional for clients, so a missing email is not reported.
+func toPerson(record []string, mapping Mapping) (model.Person, string, string, model.ValidationErrors) {
+	var p model.Person
+	var household, role string
+	var errs model.ValidationErrors
+
+	for i, field := range mapping {
+		if i >= len(record) {
+			break
+		}
+		v := strings.TrimSpace(record[i])
+		switch field {
+		case FieldHousehold:
+			household = v
+		case FieldRole:
+			role = v
+		case "firstName":
+			p.FirstName = v
+		case "lastName":
+			p.LastName = v
+		case "dob":
+			// an unrecognized date is kept as is and reported by Validate
+			p.DOB, _ = ParseDate(v)
+		case "gender":
+			p.Gender = v
+		case "race":
+			p.Race = v
+		case "language":
+			p.Language = v
+		case "relationship":
+			p.Relationship = v
+		case "email":
+			p.Email = strings.ToLower(v)
+		case "phone":
+			p.Phone = v
+		case "street":
+			p.Street = v
+		case "city":
+			p.City = v
+		case "state":
+			p.State = v
+		case "postalCode":
+			p.PostalCode = v
+		}
+	}
+	p.Id = ulid.Make().String()
+
+	for _, e := range p.Validate() {
+		if e.Field == "email" && e.Type == "missing" {
+			continue
+		}
+		errs = append(errs, e)
+	}
+
+	role = strings.ToLower(role)
+	if role == "self" || role == "hoh" || strings.EqualFold(p.Relationship, "self") {
+		role = "head"
+	}
+	return p, household, role, errs
+}
+
+var dateLayouts = []string{"2006-01-02", "1/2/2006", "01/02/2006", "1-2-2006", "2006/1/2"}
+
+// ParseDate accepts ISO and common US date formats and returns the date as
+// 2006-01-02, the format used for DOB. An empty string is returned unchanged.
+func ParseDate(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return s, fmt.Errorf("unrecognized date %q", s)
+}
+
+// Write saves households in batches of BatchSize, returning how many were
+// written before any error.
+func Write(ctx context.Context, database *db.FirestoreDB, households []model.Household) (int, error) {
+	written := 0
+	for start := 0; start < len(households); start += BatchSize {
+		end := min(start+BatchSize, len(households))
+		if err := database.PutHouseholds(ctx, households[start:end]); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+func hasField(errs model.ValidationErrors, field string) bool {
+	for _, e := range errs {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const legacyCSV = `Family ID,First Name,Last Name,Date of Birth,Relation,Zip,Phone
+A1,Maria,Lopez,3/14/1980,Self,97501,555-1234
+A1,Diego,Lopez,2012-06-01,Child,,
+B2,Sam,Nguyen,,Self,97520,
+C3,Ana,Silva,02/30/1990,Self,,
+
+A1,Rosa,Lopez,1955-01-09,Parent,,
+`
+
+func TestGuessMapping(t *testing.T) {
+	m := GuessMapping([]string{"Family ID", "First Name", "Last Name", "Date of Birth", "Relation", "Zip", "Phone", "Notes"})
+	want := Mapping{FieldHousehold, "firstName", "lastName", "dob", "relationship", "postalCode", "phone", FieldIgnore}
+	for i := range want {
+		if m[i] != want[i] {
+			t.Errorf("Column %d: expected %q, got %q", i, want[i], m[i])
+		}
+	}
+}
+
+func TestParseGroupsAndValidates(t *testing.T) {
+	headers := strings.Split(strings.SplitN(legacyCSV, "\n", 2)[0], ",")
+	result, err := Parse(strings.NewReader(legacyCSV), GuessMapping(headers))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	if len(result.Rows) != 5 {
+		t.Fatalf("Expected 5 rows, got %d", len(result.Rows))
+	}
+	if len(result.Households) != 1 || result.Skipped != 2 {
+		t.Fatalf("Expected 1 valid and 2 skipped households, got %d and %d", len(result.Households), result.Skipped)
+	}
+
+	h := result.Households[0]
+	if h.Head.FirstName != "Maria" || h.Head.DOB != "1980-03-14" {
+		t.Errorf("Unexpected head %+v", h.Head.PersonCommon)
+	}
+	if len(h.Members) != 2 || h.Members[1].FirstName != "Rosa" {
+		t.Errorf("Expected Diego and Rosa as members, got %+v", h.Members)
+	}
+
+	errorsByLine := map[int]string{}
+	for _, row := range result.Rows {
+		for _, e := range row.Errors {
+			errorsByLine[row.Line] += e.Field + ":" + e.Type + " "
+		}
+	}
+	if errorsByLine[4] != "dob:missing " {
+		t.Errorf("Expected missing DOB on line 4, got %q", errorsByLine[4])
+	}
+	if errorsByLine[5] != "dob:invalid " {
+		t.Errorf("Expected invalid DOB on line 5, got %q", errorsByLine[5])
+	}
+	if result.ErrorCount() != 2 {
+		t.Errorf("Expected 2 rows with errors, got %d", result.ErrorCount())
+	}
+}
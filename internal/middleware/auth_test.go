@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestResourceName(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/v1/households":       "households",
+		"/api/v1/households/:id":   "households",
+		"/api/v1/visits/:id/items": "visits",
+		"/api/v1/openapi.json":     "openapi.json",
+		"/api/v1":                  "",
+		"/api/v1/":                 "",
+		"/households/:id":          "households",
+	} {
+		if got := resourceName(path, "/api/v1"); got != want {
+			t.Errorf("resourceName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+// The middleware scopes tokens by the route path, so a request's resource is
+// named by the route it matched, not the URL it asked for.
+func TestResourceNameOfRoute(t *testing.T) {
+	e := echo.New()
+	var got string
+	g := e.Group("/api/v1")
+	g.GET("/households/:id", func(c echo.Context) error {
+		got = resourceName(c.Path(), "/api/v1")
+		return c.NoContent(http.StatusOK)
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/households/visits", nil))
+	if got != "households" {
+		t.Errorf("resource = %q, want households", got)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	for header, want := range map[string]string{
+		"Bearer fbk_abc": "fbk_abc",
+		"Bearer ":        "",
+		"Basic fbk_abc":  "",
+		"":               "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/households", nil)
+		req.Header.Set(echo.HeaderAuthorization, header)
+		got, ok := BearerToken(echo.New().NewContext(req, httptest.NewRecorder()))
+		if got != want || ok != (want != "") {
+			t.Errorf("BearerToken(%q) = %q, %v", header, got, ok)
+		}
+	}
+}
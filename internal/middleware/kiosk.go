@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// KioskCookie marks a browser as a self-service signup kiosk.
+const KioskCookie = "kiosk"
+
+// KioskMiddleware keeps a kiosk browser on the kiosk pages: any other page,
+// including the staff pages, redirects to home. Static files are still
+// served. Paths under home are allowed so the kiosk can be exited.
+func KioskMiddleware(home string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, err := c.Cookie(KioskCookie); err != nil {
+				return next(c)
+			}
+			path := c.Request().URL.Path
+			if path == home || strings.HasPrefix(path, home+"/") || strings.HasPrefix(path, "/static/") {
+				return next(c)
+			}
+			return c.Redirect(http.StatusSeeOther, home)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"time"
+)
+
+const (
+	APITokenScopeRead      = "read"
+	APITokenScopeReadWrite = "readwrite"
+
+	apiTokenPrefix = "fbk_"
+)
+
+// APIResources are the API resource names a token can be scoped to.
+var APIResources = []string{"households", "persons", "visits", "items", "reports"}
+
+// APIToken is a long-lived credential for machine clients. Only the SHA-256
+// hash of the token is stored; the plain token is shown once when created.
+type APIToken struct {
+	Id         string    `json:"id"`
+	Name       string    `json:"name"`
+	Hash       string    `json:"-"`
+	Scope      string    `json:"scope"`
+	Resources  []string  `json:"resources"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	RevokedAt  time.Time `json:"revokedAt"`
+}
+
+func (t APIToken) GetID() string {
+	return t.Id
+}
+
+func (t APIToken) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if t.Name == "" {
+		errors = append(errors, ValidationError{Field: "name", Type: "missing", Message: "field_missing"})
+	}
+	if t.Scope != APITokenScopeRead && t.Scope != APITokenScopeReadWrite {
+		errors = append(errors, ValidationError{Field: "scope", Type: "invalid", Message: "invalid_scope"})
+	}
+	if len(t.Resources) == 0 {
+		errors = append(errors, ValidationError{Field: "resources", Type: "missing", Message: "field_missing"})
+	}
+	for _, r := range t.Resources {
+		if !slices.Contains(APIResources, r) {
+			errors = append(errors, ValidationError{Field: "resources", Type: "invalid", Message: "invalid_resource"})
+			break
+		}
+	}
+
+	return errors
+}
+
+// Revoked reports whether the token has been revoked.
+func (t APIToken) Revoked() bool {
+	return !t.RevokedAt.IsZero()
+}
+
+// Allows reports whether the token may access resource, with write set for
+// requests that modify data.
+func (t APIToken) Allows(resource string, write bool) bool {
+	if t.Revoked() || !slices.Contains(t.Resources, resource) {
+		return false
+	}
+	return !write || t.Scope == APITokenScopeReadWrite
+}
+
+// NewAPITokenSecret returns a new random plain token and its hash.
+func NewAPITokenSecret() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns the value stored in APIToken.Hash for a plain token.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
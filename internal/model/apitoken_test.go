@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPITokenAllows(t *testing.T) {
+	read := APIToken{Scope: APITokenScopeRead, Resources: []string{"households", "visits"}}
+	readWrite := APIToken{Scope: APITokenScopeReadWrite, Resources: []string{"households"}}
+	revoked := readWrite
+	revoked.RevokedAt = time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name     string
+		token    APIToken
+		resource string
+		write    bool
+		want     bool
+	}{
+		{"read token reads its resource", read, "households", false, true},
+		{"read token reads its other resource", read, "visits", false, true},
+		{"read token can't write", read, "households", true, false},
+		{"read token can't read other resources", read, "persons", false, false},
+		{"read-write token reads", readWrite, "households", false, true},
+		{"read-write token writes", readWrite, "households", true, true},
+		{"read-write token can't write other resources", readWrite, "items", true, false},
+		{"no resource", readWrite, "", false, false},
+		{"revoked token can't read", revoked, "households", false, false},
+		{"revoked token can't write", revoked, "households", true, false},
+	} {
+		if got := tt.token.Allows(tt.resource, tt.write); got != tt.want {
+			t.Errorf("%s: Allows(%q, %v) = %v, want %v", tt.name, tt.resource, tt.write, got, tt.want)
+		}
+	}
+}
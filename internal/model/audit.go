@@ -0,0 +1,166 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	AuditCreate    = "create"
+	AuditUpdate    = "update"
+	AuditDelete    = "delete"
+	AuditRestore   = "restore"
+	AuditPurge     = "purge"
+	AuditAnonymize = "anonymize"
+	AuditErase     = "erase"
+	AuditRecertify = "recertify"
+)
+
+// AuditEntry records one change to a stored entity. Entries are only ever
+// appended, never updated or removed.
+type AuditEntry struct {
+	Id         string        `json:"id"`
+	At         time.Time     `json:"at"`
+	Actor      string        `json:"actor"`
+	EntityType string        `json:"entityType"`
+	EntityID   string        `json:"entityId"`
+	Action     string        `json:"action"`
+	Changes    []AuditChange `json:"changes"`
+	// Redacted is set when the change values were removed because the
+	// entity's personal data was erased. Field names are kept.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// Redact returns a copy of the entry without before and after values.
+// Erasing personal data is the one case where existing entries are changed.
+func (e AuditEntry) Redact() AuditEntry {
+	changes := make([]AuditChange, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = AuditChange{Field: c.Field}
+	}
+	e.Changes = changes
+	e.Redacted = true
+	return e
+}
+
+// AuditChange is the before and after value of a single field. Nested fields
+// are named by path, e.g. "members.1.dob".
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// NewAuditEntry describes the change from before to after, either of which
+// may be nil for a create or delete. It returns false when nothing changed.
+func NewAuditEntry[T any](actor string, at time.Time, entityType string, entityID string, before *T, after *T) (AuditEntry, bool) {
+	action := AuditUpdate
+	switch {
+	case before == nil && after == nil:
+		return AuditEntry{}, false
+	case before == nil:
+		action = AuditCreate
+	case after == nil:
+		action = AuditDelete
+	}
+
+	var b, a any
+	if before != nil {
+		b = *before
+	}
+	if after != nil {
+		a = *after
+	}
+	changes := Diff(b, a)
+	if action == AuditUpdate && len(changes) == 0 {
+		return AuditEntry{}, false
+	}
+
+	entry := NewAuditEvent(actor, at, entityType, entityID, action)
+	entry.Changes = changes
+	return entry, true
+}
+
+// NewAuditEvent records an action without field values, for changes such as
+// purges where keeping the old values would defeat the purpose.
+func NewAuditEvent(actor string, at time.Time, entityType string, entityID string, action string) AuditEntry {
+	return AuditEntry{
+		Id:         ulid.MustNew(ulid.Timestamp(at), ulid.DefaultEntropy()).String(),
+		At:         at,
+		Actor:      actor,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+	}
+}
+
+// Diff compares the JSON form of before and after field by field, so fields
+// hidden from JSON, such as password hashes, are never recorded. A nil value
+// is treated as having no fields.
+func Diff(before any, after any) []AuditChange {
+	b := flatten(before)
+	a := flatten(after)
+
+	var changes []AuditChange
+	for field, value := range b {
+		if a[field] != value {
+			changes = append(changes, AuditChange{Field: field, Before: value, After: a[field]})
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok && value != "" {
+			changes = append(changes, AuditChange{Field: field, After: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func flatten(v any) map[string]string {
+	fields := map[string]string{}
+	if v == nil {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		fields[""] = fmt.Sprint(v)
+		return fields
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		fields[""] = string(data)
+		return fields
+	}
+	flattenInto(fields, "", generic)
+	return fields
+}
+
+func flattenInto(fields map[string]string, prefix string, v any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			flattenInto(fields, join(key), value)
+		}
+	case []any:
+		for i, value := range v {
+			flattenInto(fields, join(strconv.Itoa(i)), value)
+		}
+	case nil:
+		fields[prefix] = ""
+	case string:
+		fields[prefix] = v
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	before := Household{Id: "h1", Head: Person{PersonCommon: PersonCommon{FirstName: "Ana", DOB: "1980-01-02"}, PasswordHash: "old"}}
+	after := before
+	after.Head.DOB = "1980-02-01"
+	after.Head.PasswordHash = "new"
+	after.Members = []Person{{PersonCommon: PersonCommon{FirstName: "Luis"}}}
+
+	got := Diff(before, after)
+	want := []AuditChange{
+		{Field: "head.dob", Before: "1980-01-02", After: "1980-02-01"},
+		{Field: "members.0.firstName", After: "Luis"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewAuditEntry(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	item := Item{Id: "i1", Name: "Rice"}
+
+	entry, ok := NewAuditEntry("session", at, "items", "i1", nil, &item)
+	if !ok || entry.Action != AuditCreate || entry.Actor != "session" || entry.EntityID != "i1" {
+		t.Errorf("create entry = %+v, %v", entry, ok)
+	}
+
+	if _, ok := NewAuditEntry("session", at, "items", "i1", &item, &item); ok {
+		t.Error("expected no entry for an unchanged update")
+	}
+
+	entry, ok = NewAuditEntry[Item]("session", at, "items", "i1", &item, nil)
+	if !ok || entry.Action != AuditDelete {
+		t.Errorf("delete entry = %+v, %v", entry, ok)
+	}
+	for _, c := range entry.Changes {
+		if c.After != "" {
+			t.Errorf("delete change %+v has an after value", c)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Income periods, with how many there are in a year.
+var IncomePeriods = map[string]float64{
+	"weekly":   52,
+	"biweekly": 26,
+	"monthly":  12,
+	"yearly":   1,
+}
+
+// amountPattern is a plain decimal number, so ParseAmount doesn't take the
+// NaN, Inf and hexadecimal forms strconv.ParseFloat also reads.
+var amountPattern = regexp.MustCompile(`^(\d+(\.\d*)?|\.\d+)$`)
+
+// ParseAmount reads a dollar amount as people write it, such as "$1,200.50".
+func ParseAmount(s string) (float64, error) {
+	s = strings.NewReplacer("$", "", ",", "", " ", "").Replace(s)
+	if !amountPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
+
+// AnnualIncome returns the household's income for a year, and false if it
+// wasn't reported or can't be read.
+func (h Household) AnnualIncome() (float64, bool) {
+	if h.Income == "" {
+		return 0, false
+	}
+	amount, err := ParseAmount(h.Income)
+	perYear, ok := IncomePeriods[h.IncomePeriod]
+	if err != nil || !ok {
+		return 0, false
+	}
+	return amount * perYear, true
+}
+
+func (h Household) validateIncome() ValidationErrors {
+	var errors ValidationErrors
+	if h.Income == "" {
+		return errors
+	}
+	if _, err := ParseAmount(h.Income); err != nil {
+		errors = append(errors, ValidationError{Field: "income", Type: "invalid", Message: "invalid_number"})
+	}
+	if h.IncomePeriod == "" {
+		errors = append(errors, ValidationError{Field: "incomePeriod", Type: "missing", Message: "field_missing"})
+	} else if _, ok := IncomePeriods[h.IncomePeriod]; !ok {
+		errors = append(errors, ValidationError{Field: "incomePeriod", Type: "invalid", Message: "invalid_option"})
+	}
+	return errors
+}
+
+// PovertyGuideline is the federal poverty guideline for a year, as published
+// by HHS: an annual income for a household of one, and an amount to add for
+// each additional person. Percent is the TEFAP income limit set by the state
+// as a percentage of the guideline, such as 185. Its Id is the year.
+type PovertyGuideline struct {
+	Id               string  `json:"id"`
+	Year             int     `json:"year"`
+	FirstPerson      float64 `json:"firstPerson"`
+	AdditionalPerson float64 `json:"additionalPerson"`
+	Percent          int     `json:"percent"`
+}
+
+func (g PovertyGuideline) GetID() string {
+	return g.Id
+}
+
+func (g PovertyGuideline) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if g.Year < 2000 || g.Year > 2100 {
+		errors = append(errors, ValidationError{Field: "year", Type: "invalid", Message: "invalid_year"})
+	}
+	if g.FirstPerson <= 0 {
+		errors = append(errors, ValidationError{Field: "firstPerson", Type: "invalid", Message: "invalid_number"})
+	}
+	if g.AdditionalPerson < 0 {
+		errors = append(errors, ValidationError{Field: "additionalPerson", Type: "invalid", Message: "invalid_number"})
+	}
+	if g.Percent <= 0 {
+		errors = append(errors, ValidationError{Field: "percent", Type: "invalid", Message: "invalid_number"})
+	}
+
+	return errors
+}
+
+// Guideline returns the poverty guideline for a household of size people.
+func (g PovertyGuideline) Guideline(size int) float64 {
+	return g.FirstPerson + float64(size-1)*g.AdditionalPerson
+}
+
+// Limit returns the highest annual income at which a household of size
+// people is eligible.
+func (g PovertyGuideline) Limit(size int) float64 {
+	return g.Guideline(size) * float64(g.Percent) / 100
+}
+
+// GuidelineFor returns the guideline in effect on date asOf: that of asOf's
+// year, or the latest earlier year's until it is published.
+func GuidelineFor(guidelines []PovertyGuideline, asOf time.Time) (PovertyGuideline, bool) {
+	sorted := append([]PovertyGuideline(nil), guidelines...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Year > sorted[j].Year })
+	for _, g := range sorted {
+		if g.Year <= asOf.Year() {
+			return g, true
+		}
+	}
+	return PovertyGuideline{}, false
+}
+
+// Eligibility statuses.
+const (
+	EligibilityUnknown = "unknown"
+	Eligible           = "eligible"
+	Ineligible         = "ineligible"
+)
+
+// Eligibility is the outcome of checking a household's income against the
+// TEFAP limit for its size. Guideline is the zero value when no guideline
+// applies.
+type Eligibility struct {
+	Status       string           `json:"status"`
+	Size         int              `json:"size"`
+	AnnualIncome float64          `json:"annualIncome"`
+	Limit        float64          `json:"limit"`
+	Guideline    PovertyGuideline `json:"guideline"`
+}
+
+// Eligibility checks the household's income against the guideline in effect
+// on asOf. The status is EligibilityUnknown when the household's income
+// wasn't reported or no guideline applies.
+func (h Household) Eligibility(guidelines []PovertyGuideline, asOf time.Time) Eligibility {
+	e := Eligibility{Status: EligibilityUnknown, Size: h.Size()}
+	g, ok := GuidelineFor(guidelines, asOf)
+	if !ok {
+		return e
+	}
+	e.Guideline = g
+	e.Limit = g.Limit(e.Size)
+
+	income, ok := h.AnnualIncome()
+	if !ok {
+		return e
+	}
+	e.AnnualIncome = income
+	if income <= e.Limit {
+		e.Status = Eligible
+	} else {
+		e.Status = Ineligible
+	}
+	return e
+}
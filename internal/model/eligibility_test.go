@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAmount(t *testing.T) {
+	for s, want := range map[string]float64{"1200": 1200, "$1,200.50": 1200.5, " 0 ": 0, ".5": 0.5} {
+		if got, err := ParseAmount(s); err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "abc", "-5", "NaN", "Inf", "+Inf", "Infinity", "0x1p3", "1e3", "1_000", "1e400"} {
+		if _, err := ParseAmount(s); err == nil {
+			t.Errorf("ParseAmount(%q): expected an error", s)
+		}
+	}
+}
+
+func TestValidateIncome(t *testing.T) {
+	got := map[string]string{}
+	for _, e := range (Household{Income: "lots"}).validateIncome() {
+		got[e.Field] = e.Message
+	}
+	if got["income"] != "invalid_number" || got["incomePeriod"] != "field_missing" {
+		t.Errorf("got %v", got)
+	}
+	if errs := (Household{Income: "100", IncomePeriod: "daily"}).validateIncome(); len(errs) != 1 || errs[0].Message != "invalid_option" {
+		t.Errorf("got %v", errs)
+	}
+	if errs := (Household{}).validateIncome(); errs.HasErrors() {
+		t.Errorf("income is optional: got %v", errs)
+	}
+}
+
+func TestEligibility(t *testing.T) {
+	guidelines := []PovertyGuideline{
+		{Id: "2024", Year: 2024, FirstPerson: 15060, AdditionalPerson: 5380, Percent: 185},
+		{Id: "2025", Year: 2025, FirstPerson: 15650, AdditionalPerson: 5500, Percent: 185},
+	}
+	// two people in 2025: (15650 + 5500) * 1.85 = 39127.50
+	h := Household{Members: []Person{{}}, Income: "3,000", IncomePeriod: "monthly"}
+	asOf := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	e := h.Eligibility(guidelines, asOf)
+	if e.Status != Eligible || e.Size != 2 || e.AnnualIncome != 36000 || e.Limit != 39127.5 || e.Guideline.Year != 2025 {
+		t.Errorf("got %+v", e)
+	}
+
+	h.Income = "1,000"
+	h.IncomePeriod = "weekly"
+	if e := h.Eligibility(guidelines, asOf); e.Status != Ineligible {
+		t.Errorf("52000 a year: got %+v", e)
+	}
+
+	// 2026's guideline isn't entered yet, so 2025's still applies
+	if g, ok := GuidelineFor(guidelines, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)); !ok || g.Year != 2025 {
+		t.Errorf("GuidelineFor(2026) = %v, %v", g, ok)
+	}
+	if _, ok := GuidelineFor(guidelines, time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("GuidelineFor(2023): expected no guideline")
+	}
+
+	if e := (Household{}).Eligibility(guidelines, asOf); e.Status != EligibilityUnknown || e.Limit != 15650*1.85 {
+		t.Errorf("no income: got %+v", e)
+	}
+	if e := h.Eligibility(nil, asOf); e.Status != EligibilityUnknown || e.Guideline.Year != 0 {
+		t.Errorf("no guidelines: got %+v", e)
+	}
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/brianvoe/gofakeit"
 	"github.com/oklog/ulid/v2"
 )
@@ -52,6 +54,11 @@ func Relationship() string {
 	return randomStringFromSlice(relationships)
 }
 
+// DOB returns a date of birth in the past.
+func DOB() string {
+	return gofakeit.DateRange(time.Date(1930, 1, 1, 0, 0, 0, 0, time.UTC), time.Now().AddDate(0, 0, -1)).Format("2006-01-02")
+}
+
 func GenerateHouseholds(n int) ([]Household, error) {
 	households := make([]Household, n)
 	for i := range households {
@@ -85,7 +92,7 @@ func GeneratePerson() (*Person, error) {
 			PostalCode:   gofakeit.Zip(),
 			Phone:        gofakeit.Phone(),
 			Gender:       gofakeit.Gender(),
-			DOB:          gofakeit.Date().Format("2006-01-02"),
+			DOB:          DOB(),
 			Race:         Race(),
 			Language:     Language(),
 			Relationship: Relationship(),
@@ -145,7 +152,7 @@ func GeneratePeople(n int) ([]Person, error) {
 				PostalCode:   gofakeit.Zip(),
 				Phone:        gofakeit.Phone(),
 				Gender:       gofakeit.Gender(),
-				DOB:          gofakeit.Date().Format("2006-01-02"),
+				DOB:          DOB(),
 				Race:         Race(),
 				Language:     Language(),
 				Relationship: Relationship(),
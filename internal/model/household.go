@@ -2,7 +2,10 @@ package model
 
 import (
 	"fmt"
+	"maps"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/oklog/ulid/v2"
 )
@@ -23,16 +26,59 @@ type Household struct {
 	Id      string   `json:"id"` // Firestore document key
 	Head    Person   `json:"head"`
 	Members []Person `json:"members"`
+	// Anonymized is set once identifying details have been removed under the
+	// retention policy or at the client's request.
+	Anonymized bool `json:"anonymized,omitempty"`
+	// Income is the household's gross income per IncomePeriod, one of
+	// IncomePeriods, as reported at signup. Empty means not reported.
+	Income       string `json:"income,omitempty"`
+	IncomePeriod string `json:"incomePeriod,omitempty"`
+	// CertifiedOn is the date, as 2006-01-02, the household last signed up or
+	// recertified its details.
+	CertifiedOn string `json:"certifiedOn,omitempty"`
+	// Signature is the head's latest signed self-declaration of need.
+	Signature *Signature `json:"signature,omitempty"`
+	// DuplicateOf is the ID of a household whose head has the same phone
+	// number but not the same name and date of birth, set at signup for staff
+	// to review whether both are the same household.
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	// Extra holds the answers to the household questions of a site's
+	// IntakeForm, keyed by field key.
+	Extra map[string]string `json:"extra,omitempty"`
+	// MatchIndex holds the MatchKeys, as keyed hashes when personal fields
+	// are encrypted, so returning clients can be found at signup.
+	MatchIndex []string `json:"-"`
+}
+
+// DeletedHousehold is a household that has been deleted but can still be
+// restored until it is purged.
+type DeletedHousehold struct {
+	Household
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy"`
 }
 
 func (h Household) Created() string {
 	id, err := ulid.Parse(h.Id)
 	if err == nil {
-		return ulid.Time(id.Time()).In(pacLoc).Format("2006-01-02 15:04")
+		return FormatTimestamp(ulid.Time(id.Time()))
 	}
 	return ""
 }
 
+// FormatTimestamp formats t in the food bank's local time zone.
+func FormatTimestamp(t time.Time) string {
+	return t.In(Location()).Format("2006-01-02 15:04")
+}
+
+// Location returns the food bank's local time zone.
+func Location() *time.Location {
+	if pacLoc == nil {
+		return time.Local
+	}
+	return pacLoc
+}
+
 func (h Household) Validate() ValidationErrors {
 	var errors ValidationErrors
 
@@ -46,10 +92,189 @@ func (h Household) Validate() ValidationErrors {
 	if h.Head.DOB == "" {
 		errors = append(errors, ValidationError{Field: "head.dob", Type: "missing", Message: "field_missing"})
 	}
+	errors = append(errors, h.Head.validateDetails("head.")...)
+	errors = append(errors, h.validateIncome()...)
+	errors = append(errors, h.validateCertifiedOn()...)
+	if h.Signature != nil {
+		errors = append(errors, h.Signature.validate("signature.")...)
+	}
+
+	for i, m := range h.Members {
+		prefix := fmt.Sprintf("members.%d.", i)
+		if m.FirstName == "" {
+			errors = append(errors, ValidationError{Field: prefix + "firstName", Type: "missing", Message: "field_missing"})
+		}
+		errors = append(errors, m.validateDetails(prefix)...)
+	}
 
 	return errors
 }
 
+// Persons returns the head followed by the other members.
+func (h Household) Persons() []Person {
+	return append([]Person{h.Head}, h.Members...)
+}
+
+// Anonymize returns a copy of the household with names, contact details,
+// street address, postal code and dates of birth removed. Person IDs are kept
+// so past visits are still counted for the household in reports, along with
+// gender, race, language, relationship, city and state.
+func (h Household) Anonymize() Household {
+	anon := Household{Id: h.Id, Head: h.Head.anonymize(), Anonymized: true}
+	for _, m := range h.Members {
+		anon.Members = append(anon.Members, m.anonymize())
+	}
+	return anon
+}
+
+func (p Person) anonymize() Person {
+	return Person{PersonCommon: PersonCommon{
+		Id:           p.Id,
+		City:         p.City,
+		State:        p.State,
+		Gender:       p.Gender,
+		Race:         p.Race,
+		Language:     p.Language,
+		Relationship: p.Relationship,
+	}}
+}
+
+// Size returns the number of people in the household, including the head.
+func (h Household) Size() int {
+	return len(h.Members) + 1
+}
+
 func (h Household) GetID() string {
 	return h.Id
 }
+
+// MatchKeys returns the keys that identify the household's head when they
+// sign up again: their name with date of birth, and their phone number.
+// Names are compared ignoring case and spacing, and phone numbers by their
+// last ten digits.
+func (h Household) MatchKeys() []string {
+	var keys []string
+	p := h.Head
+	first, last := normalizeName(p.FirstName), normalizeName(p.LastName)
+	if first != "" && last != "" && p.DOB != "" {
+		keys = append(keys, "name:"+first+"|"+last+"|"+p.DOB)
+	}
+	if phone := normalizePhone(p.Phone); len(phone) >= 7 {
+		keys = append(keys, "phone:"+phone)
+	}
+	return keys
+}
+
+func normalizeName(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func normalizePhone(s string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return digits
+}
+
+// BestMatch returns the candidate most likely to be the same household as h:
+// one whose head has the same name and date of birth, otherwise one with the
+// same phone number.
+func BestMatch(h Household, candidates []Household) (Household, bool) {
+	keys := h.MatchKeys()
+	for _, key := range keys {
+		for _, c := range candidates {
+			for _, k := range c.MatchKeys() {
+				if k == key {
+					return c, true
+				}
+			}
+		}
+	}
+	return Household{}, false
+}
+
+// SameHead reports whether the heads of h and other have the same name and
+// date of birth. A phone number alone doesn't show that they are the same
+// person: numbers change hands, and are known to others.
+func (h Household) SameHead(other Household) bool {
+	keys := h.MatchKeys()
+	if len(keys) == 0 || !strings.HasPrefix(keys[0], "name:") {
+		return false
+	}
+	otherKeys := other.MatchKeys()
+	return len(otherKeys) > 0 && otherKeys[0] == keys[0]
+}
+
+// Update returns h with the details given in a new signup of the same
+// household. Values left empty in the signup keep h's values. The signup's
+// members replace h's, but a member with the same name keeps their ID, as
+// does the head, so past visits still count for them.
+func (h Household) Update(signup Household) Household {
+	h.Head = h.Head.update(signup.Head)
+	h.Extra = updateExtra(h.Extra, signup.Extra)
+	if signup.Income != "" {
+		h.Income, h.IncomePeriod = signup.Income, signup.IncomePeriod
+	}
+	if signup.Signature != nil {
+		h.Signature = signup.Signature
+	}
+
+	existing := h.Members
+	h.Members = nil
+	used := make([]bool, len(existing))
+	for _, m := range signup.Members {
+		for i, e := range existing {
+			if !used[i] && normalizeName(e.FirstName) == normalizeName(m.FirstName) &&
+				normalizeName(e.LastName) == normalizeName(m.LastName) {
+				used[i] = true
+				m = e.update(m)
+				break
+			}
+		}
+		h.Members = append(h.Members, m)
+	}
+	return h
+}
+
+func (p Person) update(from Person) Person {
+	for _, f := range []struct{ to, from *string }{
+		{&p.FirstName, &from.FirstName},
+		{&p.LastName, &from.LastName},
+		{&p.Email, &from.Email},
+		{&p.Street, &from.Street},
+		{&p.City, &from.City},
+		{&p.PostalCode, &from.PostalCode},
+		{&p.Phone, &from.Phone},
+		{&p.Gender, &from.Gender},
+		{&p.DOB, &from.DOB},
+		{&p.Race, &from.Race},
+		{&p.Language, &from.Language},
+		{&p.Relationship, &from.Relationship},
+	} {
+		if *f.from != "" {
+			*f.to = *f.from
+		}
+	}
+	p.Extra = updateExtra(p.Extra, from.Extra)
+	return p
+}
+
+// updateExtra returns extra with the answers given in from, keeping answers
+// from doesn't have, such as those to another site's questions.
+func updateExtra(extra map[string]string, from map[string]string) map[string]string {
+	if len(from) == 0 {
+		return extra
+	}
+	out := maps.Clone(extra)
+	if out == nil {
+		out = map[string]string{}
+	}
+	maps.Copy(out, from)
+	return out
+}
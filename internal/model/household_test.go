@@ -0,0 +1,125 @@
+package model
+
+import "testing"
+
+func TestAnonymizeKeepsIDs(t *testing.T) {
+	h := Household{Id: "h1", Head: Person{PersonCommon: PersonCommon{Id: "head"}}, Members: []Person{{PersonCommon: PersonCommon{Id: "child"}}}}
+	h.Head.FirstName = "Ana"
+	h.Head.DOB = "1980-01-02"
+	h.Head.Gender = "Female"
+	h.Members[0].Phone = "555-1234"
+
+	anon := h.Anonymize()
+	if !anon.Anonymized || anon.Id != h.Id {
+		t.Fatalf("Anonymize() = %+v", anon)
+	}
+	if anon.Head.Id != "head" || anon.Members[0].Id != "child" {
+		t.Errorf("person IDs not kept: %+v", anon)
+	}
+	if anon.Head.FirstName != "" || anon.Head.DOB != "" || anon.Members[0].Phone != "" {
+		t.Errorf("identifying fields kept: %+v", anon)
+	}
+	if anon.Head.Gender != "Female" {
+		t.Errorf("Gender = %q, want Female", anon.Head.Gender)
+	}
+}
+
+func TestMatchKeys(t *testing.T) {
+	h := Household{Head: Person{PersonCommon: PersonCommon{FirstName: " Ana  María", LastName: "LÓPEZ", DOB: "1980-01-02", Phone: "+1 (802) 555-0100"}}}
+	keys := h.MatchKeys()
+	want := []string{"name:ana maría|lópez|1980-01-02", "phone:8025550100"}
+	if len(keys) != 2 || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("MatchKeys() = %q, want %q", keys, want)
+	}
+	if keys := (Household{Head: Person{PersonCommon: PersonCommon{FirstName: "Ana", Phone: "555"}}}).MatchKeys(); len(keys) != 0 {
+		t.Errorf("incomplete details: MatchKeys() = %q", keys)
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	person := func(first string, dob string, phone string) Person {
+		return Person{PersonCommon: PersonCommon{FirstName: first, LastName: "Lopez", DOB: dob, Phone: phone}}
+	}
+	byPhone := Household{Id: "phone", Head: person("Luis", "1975-05-05", "802-555-0100")}
+	byName := Household{Id: "name", Head: person("Ana", "1980-01-02", "")}
+
+	got, ok := BestMatch(Household{Head: person("ana", "1980-01-02", "8025550100")}, []Household{byPhone, byName})
+	if !ok || got.Id != "name" {
+		t.Errorf("BestMatch = %q %v, want the name match", got.Id, ok)
+	}
+	if _, ok := BestMatch(Household{Head: person("Ana", "1981-01-02", "")}, []Household{byPhone, byName}); ok {
+		t.Error("BestMatch matched a different date of birth")
+	}
+}
+
+func TestSameHead(t *testing.T) {
+	h := Household{Head: Person{PersonCommon: PersonCommon{FirstName: "Ana", LastName: "Lopez", DOB: "1980-01-02", Phone: "802-555-0100"}}}
+	same := Household{Head: Person{PersonCommon: PersonCommon{FirstName: "ANA", LastName: "lopez", DOB: "1980-01-02"}}}
+	phoneOnly := Household{Head: Person{PersonCommon: PersonCommon{FirstName: "Eve", LastName: "Lopez", DOB: "1990-01-01", Phone: "8025550100"}}}
+	if !h.SameHead(same) {
+		t.Error("same name and DOB not the same head")
+	}
+	if h.SameHead(phoneOnly) || phoneOnly.SameHead(h) {
+		t.Error("phone number alone made the same head")
+	}
+	if (Household{}).SameHead(Household{}) {
+		t.Error("empty heads are the same")
+	}
+}
+
+func TestUpdateKeepsIDs(t *testing.T) {
+	h := Household{Id: "h1",
+		Head: Person{PersonCommon: PersonCommon{Id: "head", FirstName: "Ana", Street: "1 Main St", Email: "ana@example.com"}},
+		Members: []Person{
+			{PersonCommon: PersonCommon{Id: "kid", FirstName: "Leo", LastName: "Lopez", DOB: "2015-03-04"}},
+			{PersonCommon: PersonCommon{Id: "gone", FirstName: "Max", LastName: "Lopez"}},
+		},
+	}
+	signup := Household{Id: "new",
+		Head: Person{PersonCommon: PersonCommon{Id: "newhead", FirstName: "Ana", Street: "2 Elm St"}},
+		Members: []Person{
+			{PersonCommon: PersonCommon{Id: "newkid", FirstName: "leo", LastName: "Lopez", Race: "latino"}},
+			{PersonCommon: PersonCommon{Id: "baby", FirstName: "Mia", LastName: "Lopez"}},
+		},
+	}
+
+	got := h.Update(signup)
+	if got.Id != "h1" || got.Head.Id != "head" || got.Head.Street != "2 Elm St" || got.Head.Email != "ana@example.com" {
+		t.Errorf("head: %+v", got.Head)
+	}
+	if len(got.Members) != 2 || got.Members[0].Id != "kid" || got.Members[0].DOB != "2015-03-04" ||
+		got.Members[0].Race != "latino" || got.Members[1].Id != "baby" {
+		t.Errorf("members: %+v", got.Members)
+	}
+}
+
+func TestValidateDetails(t *testing.T) {
+	h := Household{
+		Head: Person{PersonCommon: PersonCommon{FirstName: "Ana", LastName: "Lopez", DOB: "2023-02-29",
+			Phone: "(802) 555-0100", PostalCode: "05501-1234", Email: "ana@example"}},
+		Members: []Person{
+			{PersonCommon: PersonCommon{FirstName: "Leo", DOB: "2999-01-01", Phone: "+1 802.555.0100"}},
+			{PersonCommon: PersonCommon{DOB: "2020-13-01", PostalCode: "5501"}},
+		},
+	}
+	got := map[string]string{}
+	for _, e := range h.Validate() {
+		got[e.Field] = e.Message
+	}
+	want := map[string]string{
+		"head.dob":             "invalid_date",
+		"head.email":           "invalid_email",
+		"members.0.dob":        "future_date",
+		"members.1.firstName":  "field_missing",
+		"members.1.dob":        "invalid_date",
+		"members.1.postalCode": "invalid_postal_code",
+	}
+	if len(got) != len(want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+	for field, msg := range want {
+		if got[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, got[field], msg)
+		}
+	}
+}
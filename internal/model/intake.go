@@ -0,0 +1,238 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Intake field types.
+const (
+	IntakeText        = "text"
+	IntakeNumber      = "number"
+	IntakeDate        = "date"
+	IntakeSelect      = "select"
+	IntakeMultiSelect = "multiselect"
+	IntakeCheckbox    = "checkbox"
+)
+
+// Intake field scopes: a household question is asked once, of the head of
+// household, and a person question of every member.
+const (
+	IntakeHousehold = "household"
+	IntakePerson    = "person"
+)
+
+var intakeKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// IntakeForm is the questions a site asks at signup in addition to the
+// standard ones. Its Id is the FoodBank's. Answers are stored in the Extra
+// attributes of the Household or Person, keyed by field key.
+type IntakeForm struct {
+	Id     string        `json:"id"`
+	Fields []IntakeField `json:"fields"`
+}
+
+// IntakeField is one question. LabelKey names a message in the locale files;
+// Label is shown instead when the page's language has no such message, for
+// questions only one site asks.
+type IntakeField struct {
+	Key      string         `json:"key"`
+	Type     string         `json:"type"`
+	Scope    string         `json:"scope"`
+	LabelKey string         `json:"labelKey,omitempty"`
+	Label    string         `json:"label,omitempty"`
+	Options  []IntakeOption `json:"options,omitempty"`
+	Required bool           `json:"required,omitempty"`
+}
+
+// IntakeOption is a choice of a select or multiselect field.
+type IntakeOption struct {
+	Value    string `json:"value"`
+	LabelKey string `json:"labelKey,omitempty"`
+	Label    string `json:"label,omitempty"`
+}
+
+func (f IntakeForm) GetID() string {
+	return f.Id
+}
+
+// Validate checks the form definition itself. Keys are used in form field
+// names, so they are limited to letters, digits and underscores, and must be
+// unique across both scopes. Multiselect answers are stored comma separated,
+// so option values may not contain commas.
+func (f IntakeForm) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	seen := map[string]bool{}
+	for i, field := range f.Fields {
+		prefix := fmt.Sprintf("fields.%d.", i)
+		switch {
+		case field.Key == "":
+			errors = append(errors, ValidationError{Field: prefix + "key", Type: "missing", Message: "field_missing"})
+		case !intakeKeyPattern.MatchString(field.Key):
+			errors = append(errors, ValidationError{Field: prefix + "key", Type: "invalid", Message: "invalid_key"})
+		case seen[field.Key]:
+			errors = append(errors, ValidationError{Field: prefix + "key", Type: "invalid", Message: "duplicate_key"})
+		}
+		seen[field.Key] = true
+
+		switch field.Type {
+		case IntakeText, IntakeNumber, IntakeDate, IntakeCheckbox:
+		case IntakeSelect, IntakeMultiSelect:
+			if len(field.Options) == 0 {
+				errors = append(errors, ValidationError{Field: prefix + "options", Type: "missing", Message: "field_missing"})
+			}
+		default:
+			errors = append(errors, ValidationError{Field: prefix + "type", Type: "invalid", Message: "invalid_type"})
+		}
+		if field.Scope != IntakeHousehold && field.Scope != IntakePerson {
+			errors = append(errors, ValidationError{Field: prefix + "scope", Type: "invalid", Message: "invalid_scope"})
+		}
+		if field.LabelKey == "" && field.Label == "" {
+			errors = append(errors, ValidationError{Field: prefix + "label", Type: "missing", Message: "field_missing"})
+		}
+
+		values := map[string]bool{}
+		for j, o := range field.Options {
+			name := fmt.Sprintf("%soptions.%d.value", prefix, j)
+			switch {
+			case o.Value == "":
+				errors = append(errors, ValidationError{Field: name, Type: "missing", Message: "field_missing"})
+			case strings.Contains(o.Value, ","):
+				errors = append(errors, ValidationError{Field: name, Type: "invalid", Message: "invalid_key"})
+			case values[o.Value]:
+				errors = append(errors, ValidationError{Field: name, Type: "invalid", Message: "duplicate_key"})
+			}
+			values[o.Value] = true
+		}
+	}
+
+	return errors
+}
+
+// Scoped returns the fields asked in scope, in form order.
+func (f IntakeForm) Scoped(scope string) []IntakeField {
+	var fields []IntakeField
+	for _, field := range f.Fields {
+		if field.Scope == scope {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Field returns the field with the given key.
+func (f IntakeForm) Field(key string) (IntakeField, bool) {
+	for _, field := range f.Fields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return IntakeField{}, false
+}
+
+// Answers returns the answers to the fields in scope, where values returns
+// the submitted values for a field key. Numbers are stored in a canonical
+// form, multiselect choices comma separated and a ticked checkbox as "yes".
+// A value that can't be read is kept as given and reported by
+// ValidateAnswers. Unanswered fields are left out; nil means no answers.
+func (f IntakeForm) Answers(scope string, values func(key string) []string) map[string]string {
+	var answers map[string]string
+	for _, field := range f.Scoped(scope) {
+		var nonEmpty []string
+		for _, v := range values(field.Key) {
+			if v = strings.TrimSpace(v); v != "" {
+				nonEmpty = append(nonEmpty, v)
+			}
+		}
+		if len(nonEmpty) == 0 {
+			continue
+		}
+
+		answer := nonEmpty[0]
+		switch field.Type {
+		case IntakeNumber:
+			if n, err := strconv.ParseFloat(answer, 64); err == nil {
+				answer = strconv.FormatFloat(n, 'f', -1, 64)
+			}
+		case IntakeMultiSelect:
+			answer = strings.Join(nonEmpty, ",")
+		case IntakeCheckbox:
+			answer = "yes"
+		}
+		if answers == nil {
+			answers = map[string]string{}
+		}
+		answers[field.Key] = answer
+	}
+	return answers
+}
+
+// ValidateAnswers checks the household's answers to the form: required
+// fields are answered, by the head of household and by every other member
+// for person fields, and answers have the field's type. Answers to fields no
+// longer on the form are kept and not checked.
+func (f IntakeForm) ValidateAnswers(h Household) ValidationErrors {
+	errors := f.validateAnswers(IntakeHousehold, h.Extra, "")
+	errors = append(errors, f.validateAnswers(IntakePerson, h.Head.Extra, "head.")...)
+	for i, m := range h.Members {
+		errors = append(errors, f.validateAnswers(IntakePerson, m.Extra, fmt.Sprintf("members.%d.", i))...)
+	}
+	return errors
+}
+
+func (f IntakeForm) validateAnswers(scope string, answers map[string]string, prefix string) ValidationErrors {
+	var errors ValidationErrors
+	for _, field := range f.Scoped(scope) {
+		name := prefix + "extra." + field.Key
+		answer := answers[field.Key]
+		if answer == "" {
+			if field.Required {
+				errors = append(errors, ValidationError{Field: name, Type: "missing", Message: "field_missing"})
+			}
+			continue
+		}
+		if msg := field.check(answer); msg != "" {
+			errors = append(errors, ValidationError{Field: name, Type: "invalid", Message: msg})
+		}
+	}
+	return errors
+}
+
+// check returns the validation message for an answer of the wrong type, or
+// "" if it is valid.
+func (field IntakeField) check(answer string) string {
+	switch field.Type {
+	case IntakeNumber:
+		if _, err := strconv.ParseFloat(answer, 64); err != nil {
+			return "invalid_number"
+		}
+	case IntakeDate:
+		if _, err := time.Parse("2006-01-02", answer); err != nil {
+			return "invalid_date"
+		}
+	case IntakeSelect:
+		if !field.hasOption(answer) {
+			return "invalid_option"
+		}
+	case IntakeMultiSelect:
+		for _, v := range strings.Split(answer, ",") {
+			if !field.hasOption(v) {
+				return "invalid_option"
+			}
+		}
+	case IntakeCheckbox:
+		if answer != "yes" {
+			return "invalid_option"
+		}
+	}
+	return ""
+}
+
+func (field IntakeField) hasOption(value string) bool {
+	return slices.ContainsFunc(field.Options, func(o IntakeOption) bool { return o.Value == value })
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"testing"
+)
+
+func intakeTestForm() IntakeForm {
+	return IntakeForm{Id: "site1", Fields: []IntakeField{
+		{Key: "adults", Type: IntakeNumber, Scope: IntakeHousehold, Label: "Adults", Required: true},
+		{Key: "moved", Type: IntakeDate, Scope: IntakeHousehold, Label: "Moved in"},
+		{Key: "diet", Type: IntakeMultiSelect, Scope: IntakePerson, LabelKey: "intake.diet",
+			Options: []IntakeOption{{Value: "halal"}, {Value: "kosher"}}},
+		{Key: "veteran", Type: IntakeCheckbox, Scope: IntakePerson, LabelKey: "intake.veteran"},
+	}}
+}
+
+func TestIntakeFormValidate(t *testing.T) {
+	if errs := intakeTestForm().Validate(); errs.HasErrors() {
+		t.Errorf("valid form: got %v", errs)
+	}
+
+	form := IntakeForm{Fields: []IntakeField{
+		{Key: "snap status", Type: IntakeText, Scope: IntakeHousehold, Label: "SNAP"},
+		{Key: "diet", Type: IntakeSelect, Scope: "family", Label: "Diet"},
+		{Key: "diet", Type: "radio", Scope: IntakePerson,
+			Options: []IntakeOption{{Value: "a,b", Label: "A and B"}, {Value: ""}}},
+	}}
+	got := map[string]string{}
+	for _, e := range form.Validate() {
+		got[e.Field] = e.Message
+	}
+	want := map[string]string{
+		"fields.0.key":             "invalid_key",
+		"fields.1.options":         "field_missing",
+		"fields.1.scope":           "invalid_scope",
+		"fields.2.key":             "duplicate_key",
+		"fields.2.type":            "invalid_type",
+		"fields.2.label":           "field_missing",
+		"fields.2.options.0.value": "invalid_key",
+		"fields.2.options.1.value": "field_missing",
+	}
+	if len(got) != len(want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+	for field, msg := range want {
+		if got[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, got[field], msg)
+		}
+	}
+}
+
+func TestIntakeAnswers(t *testing.T) {
+	values := map[string][]string{
+		"adults":  {" 2.50 "},
+		"moved":   {""},
+		"diet":    {"halal", "", "kosher"},
+		"veteran": {"on"},
+	}
+	form := intakeTestForm()
+	get := func(key string) []string { return values[key] }
+
+	household := form.Answers(IntakeHousehold, get)
+	if len(household) != 1 || household["adults"] != "2.5" {
+		t.Errorf("household answers: got %v", household)
+	}
+	person := form.Answers(IntakePerson, get)
+	if person["diet"] != "halal,kosher" || person["veteran"] != "yes" {
+		t.Errorf("person answers: got %v", person)
+	}
+	if none := form.Answers(IntakePerson, func(string) []string { return nil }); none != nil {
+		t.Errorf("no answers: got %v, want nil", none)
+	}
+}
+
+func TestIntakeValidateAnswers(t *testing.T) {
+	h := Household{
+		Extra: map[string]string{"moved": "2024-02-30", "retired": "anything"},
+		Head:  Person{PersonCommon: PersonCommon{Extra: map[string]string{"diet": "halal,vegan"}}},
+		Members: []Person{
+			{PersonCommon: PersonCommon{Extra: map[string]string{"veteran": "yes", "diet": "kosher"}}},
+		},
+	}
+	got := map[string]string{}
+	for _, e := range intakeTestForm().ValidateAnswers(h) {
+		got[e.Field] = e.Message
+	}
+	want := map[string]string{
+		"extra.adults":    "field_missing",
+		"extra.moved":     "invalid_date",
+		"head.extra.diet": "invalid_option",
+	}
+	if len(got) != len(want) {
+		t.Errorf("ValidateAnswers() = %v, want %v", got, want)
+	}
+	for field, msg := range want {
+		if got[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, got[field], msg)
+		}
+	}
+}
+
+func TestUpdateKeepsOtherAnswers(t *testing.T) {
+	h := Household{Extra: map[string]string{"snap": "yes", "wic": "no"}}
+	updated := h.Update(Household{Extra: map[string]string{"wic": "yes"}})
+	if updated.Extra["snap"] != "yes" || updated.Extra["wic"] != "yes" {
+		t.Errorf("got %v", updated.Extra)
+	}
+	if h.Extra["wic"] != "no" {
+		t.Errorf("Update changed the original answers: %v", h.Extra)
+	}
+}
@@ -1,7 +1,7 @@
 package model
 
 import (
-	"regexp"
+	"time"
 )
 
 type Entity interface {
@@ -10,9 +10,9 @@ type Entity interface {
 }
 
 type ValidationError struct {
-	Field   string
-	Type    string
-	Message string
+	Field   string `json:"field,omitempty"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
 }
 
 type ValidationErrors []ValidationError
@@ -21,6 +21,16 @@ func (ve ValidationErrors) HasErrors() bool {
 	return len(ve) > 0
 }
 
+// ErrorResponse is the JSON body returned by the API for any failed request.
+type ErrorResponse struct {
+	Errors ValidationErrors `json:"errors"`
+}
+
+// NewErrorResponse returns an ErrorResponse holding a single error of the given type.
+func NewErrorResponse(errType string, message string) ErrorResponse {
+	return ErrorResponse{Errors: ValidationErrors{{Type: errType, Message: message}}}
+}
+
 func (p Person) Validate() ValidationErrors {
 	var errors ValidationErrors
 
@@ -32,10 +42,8 @@ func (p Person) Validate() ValidationErrors {
 	}
 	if p.Email == "" {
 		errors = append(errors, ValidationError{Field: "email", Type: "missing", Message: "field_missing"})
-	} else if !isValidEmail(p.Email) {
-		errors = append(errors, ValidationError{Field: "email", Type: "invalid", Message: "invalid_email"})
 	}
-	// Add more validation rules as needed
+	errors = append(errors, p.validateDetails("")...)
 
 	return errors
 }
@@ -66,11 +74,6 @@ func (fb FoodBank) Validate() ValidationErrors {
 	return errors
 }
 
-func isValidEmail(email string) bool {
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
-	return emailRegex.MatchString(email)
-}
-
 type ResetPassword struct {
 	Id       string `json:"id"`
 	PersonId string `json:"personId"`
@@ -91,6 +94,26 @@ type PersonCommon struct {
 	Race         string `json:"race"`
 	Language     string `json:"language"`
 	Relationship string `json:"relationship"`
+	// Extra holds the answers to the person questions of a site's
+	// IntakeForm, keyed by field key.
+	Extra map[string]string `json:"extra,omitempty"`
+	// EmailIndex is a keyed hash of the lowercased email, stored so persons
+	// can be found by email when the email itself is encrypted.
+	EmailIndex string `json:"-"`
+}
+
+// Age returns the person's age in whole years on date asOf, computed from
+// DOB. ok is false when DOB is missing, malformed or after asOf.
+func (p PersonCommon) Age(asOf time.Time) (age int, ok bool) {
+	dob, err := time.Parse("2006-01-02", p.DOB)
+	if err != nil || dob.After(asOf) {
+		return 0, false
+	}
+	age = asOf.Year() - dob.Year()
+	if asOf.Month() < dob.Month() || (asOf.Month() == dob.Month() && asOf.Day() < dob.Day()) {
+		age--
+	}
+	return age, true
 }
 
 type PersonOutput struct {
@@ -106,6 +129,11 @@ func (p Person) GetID() string {
 	return p.Id
 }
 
+// Output returns the fields of p that are safe to send to clients.
+func (p Person) Output() PersonOutput {
+	return PersonOutput{PersonCommon: p.PersonCommon}
+}
+
 type PersonInput struct {
 	Person
 	Password string `json:"password"`
@@ -143,7 +171,21 @@ func (fbv FoodBankVisit) GetID() string {
 }
 
 func (fbv FoodBankVisit) Validate() ValidationErrors {
-	return ValidationErrors{}
+	var errors ValidationErrors
+
+	if fbv.Date == "" {
+		errors = append(errors, ValidationError{Field: "date", Type: "missing", Message: "field_missing"})
+	} else if _, err := time.Parse("2006-01-02", fbv.Date); err != nil {
+		errors = append(errors, ValidationError{Field: "date", Type: "invalid", Message: "invalid_date"})
+	}
+	if fbv.PersonId == "" {
+		errors = append(errors, ValidationError{Field: "personId", Type: "missing", Message: "field_missing"})
+	}
+	if fbv.FoodBankId == "" {
+		errors = append(errors, ValidationError{Field: "foodBankId", Type: "missing", Message: "field_missing"})
+	}
+
+	return errors
 }
 
 type Item struct {
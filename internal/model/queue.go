@@ -0,0 +1,124 @@
+package model
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Queue ticket statuses. A household checked in waits until staff call it
+// to be served, and is then served or, if it leaves before that, left.
+const (
+	QueueWaiting = "waiting"
+	QueueServing = "serving"
+	QueueServed  = "served"
+	QueueLeft    = "left"
+)
+
+// QueueTicket is a household's place in the queue of a site on a day. Numbers
+// start from 1 at each site each day. JoinedAt is when the household was
+// checked in, CalledAt when it was called to be served and DoneAt when it was
+// served or left; the times not reached yet are zero.
+type QueueTicket struct {
+	Id          string    `json:"id"`
+	FoodBankId  string    `json:"foodBankId"`
+	Date        string    `json:"date"`
+	Number      int       `json:"number"`
+	HouseholdId string    `json:"householdId"`
+	VisitId     string    `json:"visitId"`
+	Status      string    `json:"status"`
+	JoinedAt    time.Time `json:"joinedAt"`
+	CalledAt    time.Time `json:"calledAt"`
+	DoneAt      time.Time `json:"doneAt"`
+}
+
+func (t QueueTicket) GetID() string {
+	return t.Id
+}
+
+// NewQueueTicket returns the ticket of a household joining the queue when its
+// visit is recorded at, numbered after the tickets already given out at the
+// visit's site that day.
+func NewQueueTicket(visit FoodBankVisit, householdID string, tickets []QueueTicket, at time.Time) QueueTicket {
+	number := 0
+	for _, t := range tickets {
+		number = max(number, t.Number)
+	}
+	return QueueTicket{
+		Id:          ulid.MustNew(ulid.Timestamp(at), ulid.DefaultEntropy()).String(),
+		FoodBankId:  visit.FoodBankId,
+		Date:        visit.Date,
+		Number:      number + 1,
+		HouseholdId: householdID,
+		VisitId:     visit.Id,
+		Status:      QueueWaiting,
+		JoinedAt:    at,
+	}
+}
+
+// CanBecome reports whether the ticket can change to status. A waiting
+// household can be called or leave, and one being served can be done or
+// leave.
+func (t QueueTicket) CanBecome(status string) bool {
+	switch status {
+	case QueueServing:
+		return t.Status == QueueWaiting
+	case QueueServed:
+		return t.Status == QueueServing
+	case QueueLeft:
+		return t.Status == QueueWaiting || t.Status == QueueServing
+	}
+	return false
+}
+
+// Become returns t changed to status at time at, recording when it was
+// called or done.
+func (t QueueTicket) Become(status string, at time.Time) QueueTicket {
+	t.Status = status
+	switch status {
+	case QueueServing:
+		t.CalledAt = at
+	case QueueServed, QueueLeft:
+		t.DoneAt = at
+	}
+	return t
+}
+
+// Wait returns how long the household waited to be called, and false if it
+// hasn't been called.
+func (t QueueTicket) Wait() (time.Duration, bool) {
+	if t.CalledAt.IsZero() {
+		return 0, false
+	}
+	return t.CalledAt.Sub(t.JoinedAt), true
+}
+
+// AverageWait returns the average wait of the tickets that were called, and
+// how many there were.
+func AverageWait(tickets []QueueTicket) (time.Duration, int) {
+	var total time.Duration
+	n := 0
+	for _, t := range tickets {
+		if wait, ok := t.Wait(); ok {
+			total += wait
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(n), n
+}
+
+// NextInQueue returns the waiting ticket with the lowest number, and false if
+// nobody is waiting.
+func NextInQueue(tickets []QueueTicket) (QueueTicket, bool) {
+	var next QueueTicket
+	found := false
+	for _, t := range tickets {
+		if t.Status == QueueWaiting && (!found || t.Number < next.Number) {
+			next, found = t, true
+		}
+	}
+	return next, found
+}
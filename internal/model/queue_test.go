@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueTicket(t *testing.T) {
+	visit := FoodBankVisit{Id: "v1", Date: "2025-06-02", FoodBankId: "fb1"}
+	joined := time.Date(2025, 6, 2, 9, 0, 0, 0, Location())
+
+	first := NewQueueTicket(visit, "h1", nil, joined)
+	if first.Number != 1 || first.Status != QueueWaiting || first.VisitId != "v1" || first.HouseholdId != "h1" || !first.JoinedAt.Equal(joined) {
+		t.Errorf("first ticket: %+v", first)
+	}
+	// numbers carry on after the highest, whatever became of earlier tickets
+	next := NewQueueTicket(visit, "h2", []QueueTicket{{Number: 3, Status: QueueServed}, {Number: 1, Status: QueueLeft}}, joined)
+	if next.Number != 4 {
+		t.Errorf("got number %d, want 4", next.Number)
+	}
+
+	if _, ok := first.Wait(); ok {
+		t.Error("waiting ticket has a wait")
+	}
+	if first.CanBecome(QueueServed) {
+		t.Error("waiting ticket can be served before it is called")
+	}
+	called := first.Become(QueueServing, joined.Add(14*time.Minute))
+	if wait, ok := called.Wait(); !ok || wait != 14*time.Minute {
+		t.Errorf("Wait() = %v, %v", wait, ok)
+	}
+	served := called.Become(QueueServed, joined.Add(20*time.Minute))
+	if !served.DoneAt.Equal(joined.Add(20*time.Minute)) || served.CanBecome(QueueLeft) {
+		t.Errorf("served ticket: %+v", served)
+	}
+}
+
+func TestAverageWait(t *testing.T) {
+	at := time.Date(2025, 6, 2, 9, 0, 0, 0, Location())
+	tickets := []QueueTicket{
+		{Number: 1, Status: QueueServed, JoinedAt: at, CalledAt: at.Add(10 * time.Minute)},
+		{Number: 2, Status: QueueServing, JoinedAt: at, CalledAt: at.Add(20 * time.Minute)},
+		{Number: 3, Status: QueueWaiting, JoinedAt: at},
+		{Number: 4, Status: QueueLeft, JoinedAt: at, DoneAt: at.Add(time.Hour)},
+	}
+	if average, n := AverageWait(tickets); average != 15*time.Minute || n != 2 {
+		t.Errorf("AverageWait() = %v, %d", average, n)
+	}
+	if average, n := AverageWait(nil); average != 0 || n != 0 {
+		t.Errorf("AverageWait(nil) = %v, %d", average, n)
+	}
+}
+
+func TestNextInQueue(t *testing.T) {
+	tickets := []QueueTicket{
+		{Number: 1, Status: QueueServing},
+		{Number: 5, Status: QueueWaiting},
+		{Number: 2, Status: QueueLeft},
+		{Number: 3, Status: QueueWaiting},
+	}
+	if next, ok := NextInQueue(tickets); !ok || next.Number != 3 {
+		t.Errorf("NextInQueue() = %d, %v", next.Number, ok)
+	}
+	if _, ok := NextInQueue(tickets[:1]); ok {
+		t.Error("nobody is waiting")
+	}
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// RecertifyMonths is how long a household's certification lasts.
+const RecertifyMonths = 12
+
+// RecertifyNoticeDays is how long before its certification runs out a
+// household is due to recertify.
+const RecertifyNoticeDays = 30
+
+// Certification statuses.
+const (
+	CertificationCurrent = "current"
+	CertificationDue     = "due"
+	CertificationOverdue = "overdue"
+)
+
+// HouseholdSnapshot is a household as it was before it was recertified. A
+// household's snapshots are its certification history. Id is the snapshot's
+// own; Household.Id is the household's.
+type HouseholdSnapshot struct {
+	Id        string    `json:"id"`
+	TakenAt   time.Time `json:"takenAt"`
+	TakenBy   string    `json:"takenBy"`
+	Household Household `json:"household"`
+}
+
+func (s HouseholdSnapshot) GetID() string {
+	return s.Id
+}
+
+// CertifiedDate returns the date the household was last certified: its
+// CertifiedOn, or the day it signed up if it hasn't been recertified since
+// certification dates were recorded.
+func (h Household) CertifiedDate() (time.Time, bool) {
+	if h.CertifiedOn != "" {
+		t, err := time.ParseInLocation("2006-01-02", h.CertifiedOn, Location())
+		return t, err == nil
+	}
+	id, err := ulid.Parse(h.Id)
+	if err != nil {
+		return time.Time{}, false
+	}
+	y, m, d := ulid.Time(id.Time()).In(Location()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location()), true
+}
+
+// RecertificationDue returns the date the household's certification runs
+// out, and false if it isn't known when it was certified.
+func (h Household) RecertificationDue() (time.Time, bool) {
+	certified, ok := h.CertifiedDate()
+	if !ok {
+		return time.Time{}, false
+	}
+	return certified.AddDate(0, RecertifyMonths, 0), true
+}
+
+// CertificationStatus returns whether the household's certification is
+// current, due to run out within RecertifyNoticeDays, or overdue on asOf.
+// A household with no known certification date is overdue.
+func (h Household) CertificationStatus(asOf time.Time) string {
+	due, ok := h.RecertificationDue()
+	switch {
+	case !ok || !asOf.Before(due):
+		return CertificationOverdue
+	case !asOf.Before(due.AddDate(0, 0, -RecertifyNoticeDays)):
+		return CertificationDue
+	}
+	return CertificationCurrent
+}
+
+// Recertify returns h certified again on date on with the details given at
+// recertification, along with a snapshot of h as it was before, taken by
+// actor. An income or signature left out keeps h's.
+func (h Household) Recertify(with Household, on time.Time, actor string) (Household, HouseholdSnapshot) {
+	before := h
+	before.MatchIndex = nil
+	snapshot := HouseholdSnapshot{
+		Id:        ulid.MustNew(ulid.Timestamp(on), ulid.DefaultEntropy()).String(),
+		TakenAt:   on,
+		TakenBy:   actor,
+		Household: before,
+	}
+
+	if with.Income != "" {
+		h.Income, h.IncomePeriod = with.Income, with.IncomePeriod
+	}
+	if with.Signature != nil {
+		h.Signature = with.Signature
+	}
+	h.CertifiedOn = on.In(Location()).Format("2006-01-02")
+	return h, snapshot
+}
+
+func (h Household) validateCertifiedOn() ValidationErrors {
+	var errors ValidationErrors
+	if h.CertifiedOn == "" {
+		return errors
+	}
+	if _, err := time.Parse("2006-01-02", h.CertifiedOn); err != nil {
+		errors = append(errors, ValidationError{Field: "certifiedOn", Type: "invalid", Message: "invalid_date"})
+	} else if h.CertifiedOn > time.Now().In(Location()).Format("2006-01-02") {
+		errors = append(errors, ValidationError{Field: "certifiedOn", Type: "invalid", Message: "future_date"})
+	}
+	return errors
+}
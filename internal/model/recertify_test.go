@@ -0,0 +1,75 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestCertificationStatus(t *testing.T) {
+	h := Household{CertifiedOn: "2024-06-15"}
+	for date, want := range map[string]string{
+		"2025-05-01": CertificationCurrent,
+		"2025-05-20": CertificationDue,
+		"2025-06-14": CertificationDue,
+		"2025-06-15": CertificationOverdue,
+	} {
+		asOf, _ := time.ParseInLocation("2006-01-02", date, Location())
+		if got := h.CertificationStatus(asOf.Add(12 * time.Hour)); got != want {
+			t.Errorf("%s: got %q, want %q", date, got, want)
+		}
+	}
+
+	// households from before certification dates were recorded were
+	// certified when they signed up
+	signedUp := time.Date(2024, 3, 1, 18, 0, 0, 0, Location())
+	h = Household{Id: ulid.MustNew(ulid.Timestamp(signedUp), ulid.DefaultEntropy()).String()}
+	if due, ok := h.RecertificationDue(); !ok || due.Format("2006-01-02") != "2025-03-01" {
+		t.Errorf("RecertificationDue() = %v, %v", due, ok)
+	}
+	if got := (Household{Id: "not a ulid"}).CertificationStatus(signedUp); got != CertificationOverdue {
+		t.Errorf("unknown certification date: got %q", got)
+	}
+}
+
+func TestRecertify(t *testing.T) {
+	h := Household{
+		Id:           "h1",
+		Head:         Person{PersonCommon: PersonCommon{FirstName: "Ana"}},
+		Income:       "1000",
+		IncomePeriod: "monthly",
+		CertifiedOn:  "2024-01-10",
+		Signature:    &Signature{Name: "Ana Lopez"},
+		MatchIndex:   []string{"key"},
+	}
+	on := time.Date(2025, 1, 20, 10, 0, 0, 0, Location())
+	signed := &Signature{Name: "Ana M. Lopez", SignedAt: on, Attestation: AttestationVersion}
+
+	recertified, snapshot := h.Recertify(Household{Signature: signed}, on, "staff@example.com")
+	if recertified.CertifiedOn != "2025-01-20" || recertified.Signature != signed || recertified.Income != "1000" {
+		t.Errorf("got %+v", recertified)
+	}
+	before := snapshot.Household
+	if snapshot.Id == "" || snapshot.TakenBy != "staff@example.com" || !snapshot.TakenAt.Equal(on) ||
+		before.Id != "h1" || before.CertifiedOn != "2024-01-10" || before.Signature.Name != "Ana Lopez" || before.MatchIndex != nil {
+		t.Errorf("got snapshot %+v", snapshot)
+	}
+
+	recertified, _ = h.Recertify(Household{Income: "500", IncomePeriod: "weekly"}, on, "")
+	if recertified.Income != "500" || recertified.IncomePeriod != "weekly" {
+		t.Errorf("got income %s %s", recertified.Income, recertified.IncomePeriod)
+	}
+}
+
+func TestValidateCertifiedOn(t *testing.T) {
+	for date, want := range map[string]string{"2024-02-30": "invalid_date", "2999-01-01": "future_date", "2024-02-29": ""} {
+		var got string
+		for _, e := range (Household{CertifiedOn: date}).validateCertifiedOn() {
+			got = e.Message
+		}
+		if got != want {
+			t.Errorf("%s: got %q, want %q", date, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"net/mail"
+	"time"
+)
+
+const (
+	ReportTEFAPMonthly = "tefap_monthly"
+	ReportWeeklyVisits = "weekly_visits"
+)
+
+// ReportNames are the reports that can be scheduled, with display names.
+var ReportNames = map[string]string{
+	ReportTEFAPMonthly: "TEFAP monthly distribution (previous month, sent on the 1st)",
+	ReportWeeklyVisits: "Weekly visit summary (previous week, sent on Mondays)",
+}
+
+// ReportSchedule delivers a report to recipients on the report's cadence at
+// Hour o'clock local time.
+type ReportSchedule struct {
+	Id         string    `json:"id"`
+	Name       string    `json:"name"`
+	Report     string    `json:"report"`
+	Site       string    `json:"site"`
+	Format     string    `json:"format"`
+	Recipients []string  `json:"recipients"`
+	Hour       int       `json:"hour"`
+	Enabled    bool      `json:"enabled"`
+	NextRunAt  time.Time `json:"nextRunAt"`
+	LastRunAt  time.Time `json:"lastRunAt"`
+	LastError  string    `json:"lastError"`
+}
+
+func (s ReportSchedule) GetID() string {
+	return s.Id
+}
+
+func (s ReportSchedule) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if s.Name == "" {
+		errors = append(errors, ValidationError{Field: "name", Type: "missing", Message: "field_missing"})
+	}
+	if _, ok := ReportNames[s.Report]; !ok {
+		errors = append(errors, ValidationError{Field: "report", Type: "invalid", Message: "invalid_report"})
+	}
+	if s.Format != "csv" && s.Format != "pdf" {
+		errors = append(errors, ValidationError{Field: "format", Type: "invalid", Message: "invalid_format"})
+	}
+	if s.Hour < 0 || s.Hour > 23 {
+		errors = append(errors, ValidationError{Field: "hour", Type: "invalid", Message: "invalid_hour"})
+	}
+	if len(s.Recipients) == 0 {
+		errors = append(errors, ValidationError{Field: "recipients", Type: "missing", Message: "field_missing"})
+	}
+	for _, r := range s.Recipients {
+		if _, err := mail.ParseAddress(r); err != nil {
+			errors = append(errors, ValidationError{Field: "recipients", Type: "invalid", Message: "invalid_email"})
+			break
+		}
+	}
+
+	return errors
+}
+
+// NextRun returns the first time after t that the schedule is due.
+func (s ReportSchedule) NextRun(t time.Time) time.Time {
+	local := t.In(Location())
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, Location())
+
+	switch s.Report {
+	case ReportTEFAPMonthly:
+		next = time.Date(local.Year(), local.Month(), 1, s.Hour, 0, 0, 0, Location())
+		if !next.After(t) {
+			next = next.AddDate(0, 1, 0)
+		}
+	case ReportWeeklyVisits:
+		next = next.AddDate(0, 0, (int(time.Monday)-int(next.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+	default:
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
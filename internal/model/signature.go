@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+// AttestationVersion is the version of the self-declaration of need that
+// clients sign now. Its text is the "attestation.<version>" message of the
+// locale files. When the wording changes, add it under a new version and keep
+// the old messages, so earlier signatures are shown with the text signed.
+const AttestationVersion = "2025-1"
+
+// MaxSignatureImage is the largest drawn signature accepted, as the length of
+// its data URL.
+const MaxSignatureImage = 200_000
+
+const signatureImagePrefix = "data:image/png;base64,"
+
+// Signature is the head of household's signed self-declaration of need,
+// either drawn on screen or typed as their full name.
+type Signature struct {
+	// Name is the signer's typed full name, if they typed it.
+	Name string `json:"name,omitempty"`
+	// Image is the drawn signature as a PNG data URL, if they drew it.
+	Image    string    `json:"image,omitempty"`
+	SignedAt time.Time `json:"signedAt"`
+	// Attestation is the AttestationVersion of the text that was signed.
+	Attestation string `json:"attestation"`
+}
+
+// Drawn reports whether the signature was drawn rather than typed.
+func (s Signature) Drawn() bool {
+	return s.Image != ""
+}
+
+func (s Signature) validate(prefix string) ValidationErrors {
+	var errors ValidationErrors
+	if strings.TrimSpace(s.Name) == "" && s.Image == "" {
+		errors = append(errors, ValidationError{Field: prefix + "name", Type: "missing", Message: "field_missing"})
+	}
+	if s.Image != "" && !validSignatureImage(s.Image) {
+		errors = append(errors, ValidationError{Field: prefix + "image", Type: "invalid", Message: "invalid_signature"})
+	}
+	if s.SignedAt.IsZero() {
+		errors = append(errors, ValidationError{Field: prefix + "signedAt", Type: "missing", Message: "field_missing"})
+	}
+	if s.Attestation == "" {
+		errors = append(errors, ValidationError{Field: prefix + "attestation", Type: "missing", Message: "field_missing"})
+	}
+	return errors
+}
+
+func validSignatureImage(image string) bool {
+	data, ok := strings.CutPrefix(image, signatureImagePrefix)
+	if !ok || len(image) > MaxSignatureImage {
+		return false
+	}
+	_, err := base64.StdEncoding.DecodeString(data)
+	return err == nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignatureValidate(t *testing.T) {
+	now := time.Now()
+	for _, s := range []Signature{
+		{Name: "Ana Lopez", SignedAt: now, Attestation: AttestationVersion},
+		{Image: "data:image/png;base64,iVBORw0KGgo=", SignedAt: now, Attestation: AttestationVersion},
+	} {
+		if errs := (Household{Head: Person{PersonCommon: PersonCommon{FirstName: "Ana", LastName: "Lopez", DOB: "1980-03-04"}},
+			Signature: &s}).Validate(); errs.HasErrors() {
+			t.Errorf("%+v: got %v", s, errs)
+		}
+	}
+
+	got := map[string]string{}
+	for _, e := range (Signature{Name: " ", Image: "javascript:alert(1)"}).validate("signature.") {
+		got[e.Field] = e.Message
+	}
+	want := map[string]string{
+		"signature.image":       "invalid_signature",
+		"signature.signedAt":    "field_missing",
+		"signature.attestation": "field_missing",
+	}
+	if len(got) != len(want) {
+		t.Errorf("validate() = %v, want %v", got, want)
+	}
+	for field, msg := range want {
+		if got[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, got[field], msg)
+		}
+	}
+
+	unsigned := Signature{Name: " ", SignedAt: now, Attestation: AttestationVersion}.validate("signature.")
+	if len(unsigned) != 1 || unsigned[0].Field != "signature.name" || unsigned[0].Message != "field_missing" {
+		t.Errorf("unsigned: got %v", unsigned)
+	}
+}
+
+func TestUpdateKeepsSignature(t *testing.T) {
+	signed := &Signature{Name: "Ana Lopez", Attestation: AttestationVersion}
+	h := Household{Signature: signed}
+	if updated := h.Update(Household{}); updated.Signature != signed {
+		t.Errorf("got %v, want the earlier signature kept", updated.Signature)
+	}
+	again := &Signature{Name: "Ana M. Lopez", Attestation: AttestationVersion}
+	if updated := h.Update(Household{Signature: again}); updated.Signature != again {
+		t.Errorf("got %v, want the new signature", updated.Signature)
+	}
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Booking statuses. A booking is booked until the household is checked in
+// (visited), is marked as not having come (noshow) or cancels.
+const (
+	BookingBooked    = "booked"
+	BookingVisited   = "visited"
+	BookingNoShow    = "noshow"
+	BookingCancelled = "cancelled"
+)
+
+// BookingStatuses are the statuses a booking can have.
+var BookingStatuses = []string{BookingBooked, BookingVisited, BookingNoShow, BookingCancelled}
+
+// PickupSlot is a time window on a day of the week when a site hands out
+// food to up to Capacity households that booked it. Start and End are
+// "15:04" local times.
+type PickupSlot struct {
+	Id         string `json:"id"`
+	FoodBankId string `json:"foodBankId"`
+	Weekday    int    `json:"weekday"`
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Capacity   int    `json:"capacity"`
+}
+
+func (s PickupSlot) GetID() string {
+	return s.Id
+}
+
+func (s PickupSlot) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if s.FoodBankId == "" {
+		errors = append(errors, ValidationError{Field: "foodBankId", Type: "missing", Message: "field_missing"})
+	}
+	if s.Weekday < int(time.Sunday) || s.Weekday > int(time.Saturday) {
+		errors = append(errors, ValidationError{Field: "weekday", Type: "invalid", Message: "invalid_option"})
+	}
+	start, startErr := time.Parse("15:04", s.Start)
+	if startErr != nil {
+		errors = append(errors, ValidationError{Field: "start", Type: "invalid", Message: "invalid_time"})
+	}
+	end, endErr := time.Parse("15:04", s.End)
+	if endErr != nil {
+		errors = append(errors, ValidationError{Field: "end", Type: "invalid", Message: "invalid_time"})
+	} else if startErr == nil && !end.After(start) {
+		errors = append(errors, ValidationError{Field: "end", Type: "invalid", Message: "end_before_start"})
+	}
+	if s.Capacity < 1 {
+		errors = append(errors, ValidationError{Field: "capacity", Type: "invalid", Message: "invalid_number"})
+	}
+
+	return errors
+}
+
+// On reports whether the slot is held on date, a "2006-01-02" date.
+func (s PickupSlot) On(date string) bool {
+	t, err := time.Parse("2006-01-02", date)
+	return err == nil && int(t.Weekday()) == s.Weekday
+}
+
+// Remaining returns how many places are left in the slot, given the
+// bookings made for the day.
+func (s PickupSlot) Remaining(bookings []Booking) int {
+	taken := 0
+	for _, b := range bookings {
+		if b.SlotId == s.Id && b.Holds() {
+			taken++
+		}
+	}
+	return max(s.Capacity-taken, 0)
+}
+
+// CheckBooking checks that b can be made in s: that s is held on b's date,
+// which is today or later, that the household hasn't already booked the site
+// that day, and that s isn't full. existing are the bookings of the site on
+// b's date.
+func (s PickupSlot) CheckBooking(b Booking, existing []Booking, today string) ValidationErrors {
+	var errors ValidationErrors
+
+	if !s.On(b.Date) {
+		errors = append(errors, ValidationError{Field: "slot", Type: "invalid", Message: "invalid_slot"})
+	} else if b.Date < today {
+		errors = append(errors, ValidationError{Field: "date", Type: "invalid", Message: "past_date"})
+	}
+	for _, e := range existing {
+		if e.HouseholdId == b.HouseholdId && e.Holds() {
+			return append(errors, ValidationError{Field: "slot", Type: "invalid", Message: "already_booked"})
+		}
+	}
+	if s.Remaining(existing) == 0 {
+		errors = append(errors, ValidationError{Field: "slot", Type: "invalid", Message: "slot_full"})
+	}
+
+	return errors
+}
+
+// Booking is a household's place in a pickup slot on a date. The slot's
+// site and times are copied in so the booking still reads the same if the
+// slot is changed or removed. VisitId is the visit recorded when the
+// household was checked in.
+type Booking struct {
+	Id          string    `json:"id"`
+	SlotId      string    `json:"slotId"`
+	FoodBankId  string    `json:"foodBankId"`
+	Date        string    `json:"date"`
+	Start       string    `json:"start"`
+	End         string    `json:"end"`
+	HouseholdId string    `json:"householdId"`
+	Status      string    `json:"status"`
+	VisitId     string    `json:"visitId,omitempty"`
+	BookedAt    time.Time `json:"bookedAt"`
+	BookedBy    string    `json:"bookedBy"`
+}
+
+func (b Booking) GetID() string {
+	return b.Id
+}
+
+func (b Booking) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if b.SlotId == "" {
+		errors = append(errors, ValidationError{Field: "slot", Type: "missing", Message: "field_missing"})
+	}
+	if b.HouseholdId == "" {
+		errors = append(errors, ValidationError{Field: "householdId", Type: "missing", Message: "field_missing"})
+	}
+	if b.Date == "" {
+		errors = append(errors, ValidationError{Field: "date", Type: "missing", Message: "field_missing"})
+	} else if _, err := time.Parse("2006-01-02", b.Date); err != nil {
+		errors = append(errors, ValidationError{Field: "date", Type: "invalid", Message: "invalid_date"})
+	}
+	if !slices.Contains(BookingStatuses, b.Status) {
+		errors = append(errors, ValidationError{Field: "status", Type: "invalid", Message: "invalid_option"})
+	}
+
+	return errors
+}
+
+// Holds reports whether the booking takes a place in its slot, which every
+// booking does unless it was cancelled.
+func (b Booking) Holds() bool {
+	return b.Status != BookingCancelled
+}
+
+// CanBecome reports whether the booking can change to status. A booked
+// booking can be cancelled, missed or visited, and a household marked as a
+// no-show can still be checked in if it arrives late.
+func (b Booking) CanBecome(status string) bool {
+	switch status {
+	case BookingCancelled, BookingNoShow:
+		return b.Status == BookingBooked
+	case BookingVisited:
+		return b.Status == BookingBooked || b.Status == BookingNoShow
+	}
+	return false
+}
+
+// NoShows returns how many of bookings the household didn't come for.
+func NoShows(bookings []Booking) int {
+	n := 0
+	for _, b := range bookings {
+		if b.Status == BookingNoShow {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestPickupSlotValidate(t *testing.T) {
+	valid := PickupSlot{FoodBankId: "fb1", Weekday: 1, Start: "09:00", End: "10:30", Capacity: 10}
+	if errs := valid.Validate(); errs.HasErrors() {
+		t.Errorf("valid slot: %v", errs)
+	}
+
+	for name, tc := range map[string]struct {
+		slot  PickupSlot
+		field string
+		msg   string
+	}{
+		"no site":       {PickupSlot{Weekday: 1, Start: "09:00", End: "10:00", Capacity: 1}, "foodBankId", "field_missing"},
+		"bad weekday":   {PickupSlot{FoodBankId: "fb1", Weekday: 7, Start: "09:00", End: "10:00", Capacity: 1}, "weekday", "invalid_option"},
+		"bad time":      {PickupSlot{FoodBankId: "fb1", Start: "9am", End: "10:00", Capacity: 1}, "start", "invalid_time"},
+		"end too early": {PickupSlot{FoodBankId: "fb1", Start: "10:00", End: "10:00", Capacity: 1}, "end", "end_before_start"},
+		"no capacity":   {PickupSlot{FoodBankId: "fb1", Start: "09:00", End: "10:00"}, "capacity", "invalid_number"},
+	} {
+		errs := tc.slot.Validate()
+		if len(errs) != 1 || errs[0].Field != tc.field || errs[0].Message != tc.msg {
+			t.Errorf("%s: got %v", name, errs)
+		}
+	}
+}
+
+func TestCheckBooking(t *testing.T) {
+	// 2025-06-02 is a Monday
+	slot := PickupSlot{Id: "s1", FoodBankId: "fb1", Weekday: 1, Start: "09:00", End: "10:00", Capacity: 2}
+	booking := Booking{SlotId: "s1", Date: "2025-06-02", HouseholdId: "h1"}
+	other := func(household string, status string) Booking {
+		return Booking{SlotId: "s1", Date: "2025-06-02", HouseholdId: household, Status: status}
+	}
+
+	for name, tc := range map[string]struct {
+		booking  Booking
+		existing []Booking
+		today    string
+		want     string
+	}{
+		"open":                    {booking, []Booking{other("h2", BookingBooked)}, "2025-06-01", ""},
+		"booked today":            {booking, nil, "2025-06-02", ""},
+		"cancelled free":          {booking, []Booking{other("h2", BookingBooked), other("h3", BookingCancelled)}, "2025-06-01", ""},
+		"full":                    {booking, []Booking{other("h2", BookingBooked), other("h3", BookingNoShow)}, "2025-06-01", "slot_full"},
+		"already booked":          {booking, []Booking{other("h1", BookingBooked)}, "2025-06-01", "already_booked"},
+		"rebook after cancelling": {booking, []Booking{other("h1", BookingCancelled)}, "2025-06-01", ""},
+		"wrong day":               {Booking{SlotId: "s1", Date: "2025-06-03", HouseholdId: "h1"}, nil, "2025-06-01", "invalid_slot"},
+		"past":                    {booking, nil, "2025-06-03", "past_date"},
+	} {
+		var got string
+		for _, e := range slot.CheckBooking(tc.booking, tc.existing, tc.today) {
+			got = e.Message
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestBookingCanBecome(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		want     bool
+	}{
+		{BookingBooked, BookingCancelled, true},
+		{BookingBooked, BookingNoShow, true},
+		{BookingBooked, BookingVisited, true},
+		{BookingNoShow, BookingVisited, true},
+		{BookingNoShow, BookingCancelled, false},
+		{BookingVisited, BookingNoShow, false},
+		{BookingCancelled, BookingVisited, false},
+		{BookingBooked, BookingBooked, false},
+	} {
+		if got := (Booking{Status: tc.from}).CanBecome(tc.to); got != tc.want {
+			t.Errorf("%s to %s: got %v", tc.from, tc.to, got)
+		}
+	}
+	if n := NoShows([]Booking{{Status: BookingNoShow}, {Status: BookingVisited}, {Status: BookingNoShow}}); n != 2 {
+		t.Errorf("NoShows = %d", n)
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+	// phonePattern accepts US numbers such as 802-555-0100, (802) 555 0100
+	// and +1 802.555.0100, and local numbers such as 555-0100.
+	phonePattern = regexp.MustCompile(`^((\+?1[ .\-]?)?(\(\d{3}\)|\d{3})[ .\-]?)?\d{3}[ .\-]?\d{4}$`)
+	// postalCodePattern accepts ZIP and ZIP+4 codes.
+	postalCodePattern = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+)
+
+func isValidEmail(email string) bool {
+	return emailPattern.MatchString(email)
+}
+
+// validateDetails checks the format of p's date of birth, email, phone and
+// postal code when they are given. Field names are prefixed with prefix, such
+// as "head." or "members.1.".
+func (p PersonCommon) validateDetails(prefix string) ValidationErrors {
+	var errors ValidationErrors
+
+	if p.DOB != "" {
+		// time.Parse also rejects days past the end of the month, such as
+		// February 31
+		if _, err := time.Parse("2006-01-02", p.DOB); err != nil {
+			errors = append(errors, ValidationError{Field: prefix + "dob", Type: "invalid", Message: "invalid_date"})
+		} else if p.DOB > time.Now().In(Location()).Format("2006-01-02") {
+			errors = append(errors, ValidationError{Field: prefix + "dob", Type: "invalid", Message: "future_date"})
+		}
+	}
+	if p.Email != "" && !isValidEmail(p.Email) {
+		errors = append(errors, ValidationError{Field: prefix + "email", Type: "invalid", Message: "invalid_email"})
+	}
+	if p.Phone != "" && !phonePattern.MatchString(p.Phone) {
+		errors = append(errors, ValidationError{Field: prefix + "phone", Type: "invalid", Message: "invalid_phone"})
+	}
+	if p.PostalCode != "" && !postalCodePattern.MatchString(p.PostalCode) {
+		errors = append(errors, ValidationError{Field: prefix + "postalCode", Type: "invalid", Message: "invalid_postal_code"})
+	}
+
+	return errors
+}
@@ -0,0 +1,395 @@
+// Package msgformat formats translated messages written in a subset of ICU
+// MessageFormat:
+//
+//	{name}                                  the argument's value
+//	{name, number}                          a number with the locale's separators
+//	{name, plural, =0 {...} one {...} other {...}}
+//	{name, select, male {...} other {...}}
+//
+// Inside a plural branch # stands for the number. A quote starts literal text
+// when it is followed by a brace or #, and a doubled quote is a quote; any
+// other quote, as in French "d'inscription", is kept as is.
+package msgformat
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Args holds named message arguments.
+type Args map[string]any
+
+type node struct {
+	text  string
+	arg   string
+	kind  string // "", "number", "plural" or "select"
+	cases map[string][]node
+	hash  bool // # inside a plural branch
+}
+
+var cache sync.Map // pattern -> []node
+
+// Format formats pattern for locale with args.
+func Format(locale string, pattern string, args Args) (string, error) {
+	nodes, err := parseCached(pattern)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := eval(&b, locale, nodes, args, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Params returns the sorted names of the arguments pattern uses.
+func Params(pattern string) ([]string, error) {
+	nodes, err := parseCached(pattern)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var walk func([]node)
+	walk = func(nodes []node) {
+		for _, n := range nodes {
+			if n.arg != "" {
+				seen[n.arg] = true
+			}
+			for _, c := range n.cases {
+				walk(c)
+			}
+		}
+	}
+	walk(nodes)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func parseCached(pattern string) ([]node, error) {
+	if nodes, ok := cache.Load(pattern); ok {
+		return nodes.([]node), nil
+	}
+	p := &parser{src: []rune(pattern)}
+	nodes, err := p.message(false)
+	if err == nil && p.pos < len(p.src) {
+		err = p.errorf("unexpected %q", p.src[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("message %q: %w", pattern, err)
+	}
+	cache.Store(pattern, nodes)
+	return nodes, nil
+}
+
+func eval(b *strings.Builder, locale string, nodes []node, args Args, count any) error {
+	for _, n := range nodes {
+		switch {
+		case n.hash:
+			b.WriteString(FormatNumber(locale, count))
+		case n.arg == "":
+			b.WriteString(n.text)
+		default:
+			v, ok := args[n.arg]
+			if !ok {
+				return fmt.Errorf("missing argument %q", n.arg)
+			}
+			switch n.kind {
+			case "", "number":
+				b.WriteString(formatValue(locale, v))
+			case "plural":
+				branch, ok := n.cases["="+formatValue("", v)]
+				if !ok {
+					branch, ok = n.cases[PluralCategory(locale, v)]
+				}
+				if !ok {
+					branch = n.cases["other"]
+				}
+				if err := eval(b, locale, branch, args, v); err != nil {
+					return err
+				}
+			case "select":
+				branch, ok := n.cases[fmt.Sprint(v)]
+				if !ok {
+					branch = n.cases["other"]
+				}
+				if err := eval(b, locale, branch, args, count); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func formatValue(locale string, v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int, int32, int64, float64:
+		if locale == "" {
+			return fmt.Sprint(v)
+		}
+		return FormatNumber(locale, v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type parser struct {
+	src []rune
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// message parses text and arguments up to the end of input or, inside a
+// branch, the closing brace. inPlural makes # the plural number.
+func (p *parser) message(inPlural bool) ([]node, error) {
+	var nodes []node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, node{text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == '\'':
+			p.pos++
+			if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+				text.WriteRune('\'')
+				p.pos++
+			} else if p.pos < len(p.src) && (p.src[p.pos] == '{' || p.src[p.pos] == '}' || p.src[p.pos] == '#') {
+				for p.pos < len(p.src) && p.src[p.pos] != '\'' {
+					text.WriteRune(p.src[p.pos])
+					p.pos++
+				}
+				p.pos++ // closing quote
+			} else {
+				text.WriteRune('\'')
+			}
+		case r == '{':
+			flush()
+			p.pos++
+			n, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		case r == '}':
+			flush()
+			return nodes, nil
+		case r == '#' && inPlural:
+			flush()
+			nodes = append(nodes, node{hash: true})
+			p.pos++
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+	flush()
+	return nodes, nil
+}
+
+// argument parses the inside of {...} after the opening brace.
+func (p *parser) argument() (node, error) {
+	n := node{arg: p.word()}
+	if n.arg == "" {
+		return n, p.errorf("expected argument name")
+	}
+	if p.skip('}') {
+		return n, nil
+	}
+	if !p.skip(',') {
+		return n, p.errorf("expected , or } after %q", n.arg)
+	}
+	n.kind = p.word()
+	switch n.kind {
+	case "number":
+		if !p.skip('}') {
+			return n, p.errorf("expected } after number")
+		}
+		return n, nil
+	case "plural", "select":
+	default:
+		return n, p.errorf("unknown argument type %q", n.kind)
+	}
+	if !p.skip(',') {
+		return n, p.errorf("expected , after %s", n.kind)
+	}
+
+	n.cases = map[string][]node{}
+	for {
+		p.spaces()
+		if p.skip('}') {
+			break
+		}
+		selector := p.word()
+		if selector == "" {
+			return n, p.errorf("expected %s selector", n.kind)
+		}
+		if !p.skip('{') {
+			return n, p.errorf("expected { after %q", selector)
+		}
+		branch, err := p.message(n.kind == "plural")
+		if err != nil {
+			return n, err
+		}
+		if !p.skip('}') {
+			return n, p.errorf("unclosed branch %q", selector)
+		}
+		n.cases[selector] = branch
+	}
+	if _, ok := n.cases["other"]; !ok {
+		return n, p.errorf("%s for %q has no other branch", n.kind, n.arg)
+	}
+	return n, nil
+}
+
+func (p *parser) spaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\n' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// word reads a name, type or selector such as "count", "one" or "=0".
+func (p *parser) word() string {
+	p.spaces()
+	start := p.pos
+	for p.pos < len(p.src) && strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_=", p.src[p.pos]) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// skip consumes r, after any spaces, if it is next.
+func (p *parser) skip(r rune) bool {
+	p.spaces()
+	if p.pos < len(p.src) && p.src[p.pos] == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// base returns the language part of a locale such as "es-MX".
+func base(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
+
+// PluralCategory returns the CLDR plural category of v for locale: zero,
+// one, two, few, many or other. Only whole numbers have categories other
+// than other.
+func PluralCategory(locale string, v any) string {
+	var n int64
+	switch v := v.(type) {
+	case int:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	default:
+		return "other"
+	}
+	if n < 0 {
+		n = -n
+	}
+
+	switch base(locale) {
+	case "vi":
+		return "other"
+	case "fr":
+		if n <= 1 {
+			return "one"
+		}
+	case "ar":
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// separators holds each language's grouping and decimal separators.
+var separators = map[string][2]string{
+	"en": {",", "."},
+	"es": {".", ","},
+	"fr": {"\u202f", ","},
+	"vi": {".", ","},
+	"ar": {",", "."},
+	"ne": {",", "."},
+	"sw": {",", "."},
+}
+
+// FormatNumber formats an integer or float with the locale's grouping and
+// decimal separators. Floats keep up to two decimal places.
+func FormatNumber(locale string, v any) string {
+	seps, ok := separators[base(locale)]
+	if !ok {
+		seps = separators["en"]
+	}
+
+	var s string
+	switch v := v.(type) {
+	case int:
+		s = strconv.Itoa(v)
+	case int32:
+		s = strconv.FormatInt(int64(v), 10)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+		if _, frac, ok := strings.Cut(s, "."); ok && len(frac) > 2 {
+			s = strconv.FormatFloat(v, 'f', 2, 64)
+		}
+	default:
+		return fmt.Sprint(v)
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(seps[0])
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString(seps[1])
+		b.WriteString(frac)
+	}
+	return sign + b.String()
+}
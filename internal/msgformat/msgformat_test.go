@@ -0,0 +1,89 @@
+package msgformat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	households := "{count, plural, =0 {no households} one {# household} other {# households}}"
+	tests := []struct {
+		locale  string
+		pattern string
+		args    Args
+		want    string
+	}{
+		{"en", "Hello {name}", Args{"name": "Ana"}, "Hello Ana"},
+		{"en", households, Args{"count": 0}, "no households"},
+		{"en", households, Args{"count": 1}, "1 household"},
+		{"en", households, Args{"count": 1234}, "1,234 households"},
+		{"es", "{n, number} personas", Args{"n": 1234567}, "1.234.567 personas"},
+		{"fr", "{n, plural, one {# foyer} other {# foyers}}", Args{"n": 0}, "0 foyer"},
+		{"vi", "{n, plural, one {wrong} other {# hộ}}", Args{"n": 1}, "1 hộ"},
+		{"ar", "{n, plural, zero {z} one {o} two {t} few {f} many {m} other {x}}", Args{"n": 103}, "f"},
+		{"ar", "{n, plural, zero {z} one {o} two {t} few {f} many {m} other {x}}", Args{"n": 111}, "m"},
+		{"ar", "{n, plural, zero {z} one {o} two {t} few {f} many {m} other {x}}", Args{"n": 100}, "x"},
+		{"en", "{g, select, female {she} other {they}} left", Args{"g": "female"}, "she left"},
+		{"en", "{g, select, female {she} other {they}} left", Args{"g": "x"}, "they left"},
+		{"fr", "Formulaire d'inscription '{'brut'}' l''été", nil, "Formulaire d'inscription {brut} l'été"},
+		{"en", "{n, plural, other {'#' # items}}", Args{"n": 2}, "# 2 items"},
+		{"es-MX", "{n, plural, one {# día} other {# días}}", Args{"n": 1}, "1 día"},
+	}
+	for _, tt := range tests {
+		got, err := Format(tt.locale, tt.pattern, tt.args)
+		if err != nil {
+			t.Errorf("Format(%q, %q) error: %v", tt.locale, tt.pattern, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Format(%q, %q) = %q, want %q", tt.locale, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	for _, pattern := range []string{
+		"{name",
+		"unbalanced }",
+		"{n, plural, one {x}}",
+		"{n, date}",
+		"{n, plural, one {x} other {y}",
+	} {
+		if _, err := Format("en", pattern, Args{"n": 1, "name": "x"}); err == nil {
+			t.Errorf("Format(%q) succeeded", pattern)
+		}
+	}
+	if _, err := Format("en", "{missing}", Args{}); err == nil {
+		t.Error("missing argument accepted")
+	}
+}
+
+func TestParams(t *testing.T) {
+	got, err := Params("{b} {a, plural, one {# {c}} other {{c}}} {b}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Params = %v, want %v", got, want)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		locale string
+		v      any
+		want   string
+	}{
+		{"en", 999, "999"},
+		{"en", -1234, "-1,234"},
+		{"fr", 12345, "12\u202f345"},
+		{"es", 1234.5, "1.234,5"},
+		{"en", 2.456, "2.46"},
+		{"xx", 1000, "1,000"},
+	}
+	for _, tt := range tests {
+		if got := FormatNumber(tt.locale, tt.v); got != tt.want {
+			t.Errorf("FormatNumber(%q, %v) = %q, want %q", tt.locale, tt.v, got, tt.want)
+		}
+	}
+}
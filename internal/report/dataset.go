@@ -0,0 +1,127 @@
+// Package report computes the counts funders and USDA ask for from food
+// bank visits and the households of the people who visited.
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+)
+
+// Dataset holds the visits in a date range and the households they belong to.
+// A visit's PersonId may name the head or any member of a household.
+type Dataset struct {
+	From       time.Time
+	To         time.Time
+	Visits     []model.FoodBankVisit
+	Households []model.Household
+
+	householdOf map[string]int
+}
+
+// NewDataset indexes households by the ids of their people. When a person id
+// appears in more than one household, visits are credited to the most
+// recently created one.
+func NewDataset(from time.Time, to time.Time, visits []model.FoodBankVisit, households []model.Household) *Dataset {
+	d := &Dataset{From: from, To: to, Visits: visits, Households: households, householdOf: map[string]int{}}
+	for i, h := range households {
+		for _, p := range h.Persons() {
+			if p.Id == "" {
+				continue
+			}
+			if prev, ok := d.householdOf[p.Id]; ok && households[prev].Id > h.Id {
+				continue
+			}
+			d.householdOf[p.Id] = i
+		}
+	}
+	return d
+}
+
+// HouseholdFor returns the household containing the person with id personId.
+func (d *Dataset) HouseholdFor(personId string) (*model.Household, bool) {
+	i, ok := d.householdOf[personId]
+	if !ok {
+		return nil, false
+	}
+	return &d.Households[i], true
+}
+
+// Load reads the visits dated from..to inclusive, optionally limited to one
+// food bank site, and the households of the people who visited. Households
+// are streamed and only those with a visit are kept.
+func Load(ctx context.Context, database *db.FirestoreDB, from time.Time, to time.Time, site string) (*Dataset, error) {
+	var visits []model.FoodBankVisit
+	visitors := map[string]bool{}
+	err := database.EachFoodBankVisit(ctx, from.Format("2006-01-02"), to.Format("2006-01-02"), func(v model.FoodBankVisit) error {
+		if site == "" || v.FoodBankId == site {
+			visits = append(visits, v)
+			visitors[v.PersonId] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading visits: %w", err)
+	}
+
+	var households []model.Household
+	err = database.EachHousehold(ctx, func(h model.Household) error {
+		for _, p := range h.Persons() {
+			if visitors[p.Id] {
+				households = append(households, h)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading households: %w", err)
+	}
+
+	return NewDataset(from, to, visits, households), nil
+}
+
+// MonthRange returns the first and last day of the month given as 2006-01.
+func MonthRange(month string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid month %q", month)
+	}
+	return start, start.AddDate(0, 1, -1), nil
+}
+
+// ServedHouseholds returns each household with at least one visit once, in
+// order of first visit.
+func (d *Dataset) ServedHouseholds() []*model.Household {
+	var served []*model.Household
+	seen := map[string]bool{}
+	for _, v := range d.Visits {
+		h, ok := d.HouseholdFor(v.PersonId)
+		if ok && !seen[h.Id] {
+			seen[h.Id] = true
+			served = append(served, h)
+		}
+	}
+	return served
+}
+
+// LoadPriorVisitors returns the ids of the people in d's served households
+// who visited any site before d.From.
+func LoadPriorVisitors(ctx context.Context, database *db.FirestoreDB, d *Dataset) (map[string]bool, error) {
+	var ids []string
+	for _, h := range d.ServedHouseholds() {
+		for _, p := range h.Persons() {
+			if p.Id != "" {
+				ids = append(ids, p.Id)
+			}
+		}
+	}
+	visitors, err := database.VisitedBefore(ctx, ids, d.From.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("error loading prior visits: %w", err)
+	}
+	return visitors, nil
+}
@@ -0,0 +1,164 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bucket is one bar of a distribution.
+type Bucket struct {
+	Label string
+	// Key is set instead of Label for buckets the report makes up rather than
+	// takes from the data, one of the Bucket constants, for pages to show in
+	// their language.
+	Key   string
+	Count int
+}
+
+// Keys of the buckets that aren't values from the data.
+const (
+	BucketUnknown   = "unknown"
+	BucketOther     = "other"
+	BucketNew       = "new"
+	BucketReturning = "returning"
+)
+
+// DemographicAgeGroups are the age brackets shown on the demographics dashboard.
+var DemographicAgeGroups = []AgeGroup{
+	{Label: "0-4", Min: 0, Max: 4},
+	{Label: "5-17", Min: 5, Max: 17},
+	{Label: "18-24", Min: 18, Max: 24},
+	{Label: "25-44", Min: 25, Max: 44},
+	{Label: "45-64", Min: 45, Max: 64},
+	{Label: "65+", Min: 65, Max: -1},
+}
+
+// maxZIPCodes limits the ZIP code distribution; the rest are grouped as
+// BucketOther.
+const maxZIPCodes = 15
+
+// Demographics summarizes the people in households served during a dataset's
+// date range, counting each person once as identified by PersonKey. A
+// household is returning if anyone in it visited before the range.
+type Demographics struct {
+	Households     int
+	Individuals    int
+	AgeBrackets    []Bucket
+	HouseholdSizes []Bucket
+	Languages      []Bucket
+	Races          []Bucket
+	Genders        []Bucket
+	ZIPCodes       []Bucket
+	NewVsReturning []Bucket
+}
+
+// BuildDemographics summarizes d. priorVisitors holds the ids of people who
+// visited before d.From.
+func BuildDemographics(d *Dataset, priorVisitors map[string]bool) Demographics {
+	ages := make([]int, len(DemographicAgeGroups))
+	ageUnknown := 0
+	sizes := make([]int, 6)
+	languages, races, genders, zips := counter{}, counter{}, counter{}, counter{}
+	newCount, returning := 0, 0
+
+	served := d.ServedHouseholds()
+	seen := map[string]bool{}
+	out := Demographics{Households: len(served)}
+	for _, h := range served {
+		persons := h.Persons()
+		sizes[min(len(persons), len(sizes))-1]++
+		zips.add(h.Head.PostalCode)
+
+		isReturning := false
+		for _, p := range persons {
+			if priorVisitors[p.Id] {
+				isReturning = true
+			}
+			// people registered in more than one served household are counted once
+			key := PersonKey(p)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out.Individuals++
+			languages.add(p.Language)
+			races.add(p.Race)
+			genders.add(p.Gender)
+
+			age, ok := p.Age(d.To)
+			if !ok {
+				ageUnknown++
+				continue
+			}
+			for i, g := range DemographicAgeGroups {
+				if g.Contains(age) {
+					ages[i]++
+					break
+				}
+			}
+		}
+		if isReturning {
+			returning++
+		} else {
+			newCount++
+		}
+	}
+
+	for i, g := range DemographicAgeGroups {
+		out.AgeBrackets = append(out.AgeBrackets, Bucket{Label: g.Label, Count: ages[i]})
+	}
+	out.AgeBrackets = append(out.AgeBrackets, Bucket{Key: BucketUnknown, Count: ageUnknown})
+	for i, n := range sizes {
+		label := strconv.Itoa(i + 1)
+		if i == len(sizes)-1 {
+			label += "+"
+		}
+		out.HouseholdSizes = append(out.HouseholdSizes, Bucket{Label: label, Count: n})
+	}
+	out.Languages = languages.buckets(0)
+	out.Races = races.buckets(0)
+	out.Genders = genders.buckets(0)
+	out.ZIPCodes = zips.buckets(maxZIPCodes)
+	out.NewVsReturning = []Bucket{{Key: BucketNew, Count: newCount}, {Key: BucketReturning, Count: returning}}
+	return out
+}
+
+// counter tallies free-text values case-insensitively, keeping the first
+// spelling seen as the label. Empty values are counted as BucketUnknown.
+type counter map[string]*Bucket
+
+func (c counter) add(value string) {
+	value = strings.TrimSpace(value)
+	key := strings.ToLower(value)
+	if b, ok := c[key]; ok {
+		b.Count++
+	} else if value == "" {
+		c[key] = &Bucket{Key: BucketUnknown, Count: 1}
+	} else {
+		c[key] = &Bucket{Label: value, Count: 1}
+	}
+}
+
+// buckets returns the counts largest first. When limit is positive, buckets
+// beyond it are combined into BucketOther.
+func (c counter) buckets(limit int) []Bucket {
+	out := make([]Bucket, 0, len(c))
+	for _, b := range c {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Label < out[j].Label
+	})
+	if limit > 0 && len(out) > limit {
+		other := Bucket{Key: BucketOther}
+		for _, b := range out[limit:] {
+			other.Count += b.Count
+		}
+		out = append(out[:limit], other)
+	}
+	return out
+}
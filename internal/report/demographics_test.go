@@ -0,0 +1,64 @@
+package report
+
+import (
+	"testing"
+
+	"foodbank/internal/model"
+)
+
+func TestBuildDemographics(t *testing.T) {
+	head := person("p1", "1990-01-01")
+	head.PostalCode = "97501"
+	head.Language = "Spanish"
+	child := person("p2", "2024-05-05")
+	child.Language = "spanish"
+	single := person("p3", "")
+	single.PostalCode = "97520"
+
+	households := []model.Household{
+		{Id: "h1", Head: head, Members: []model.Person{child}},
+		{Id: "h2", Head: single},
+	}
+	visits := []model.FoodBankVisit{
+		{Date: "2026-09-01", PersonId: "p1"},
+		{Date: "2026-09-08", PersonId: "p2"},
+		{Date: "2026-09-09", PersonId: "p3"},
+	}
+
+	d := BuildDemographics(testDataset(t, visits, households), map[string]bool{"p3": true})
+
+	if d.Households != 2 || d.Individuals != 3 {
+		t.Errorf("Expected 2 households and 3 individuals, got %d and %d", d.Households, d.Individuals)
+	}
+	assertBuckets(t, "age", d.AgeBrackets, map[string]int{"0-4": 1, "25-44": 1, "unknown": 1})
+	assertBuckets(t, "size", d.HouseholdSizes, map[string]int{"1": 1, "2": 1})
+	assertBuckets(t, "language", d.Languages, map[string]int{"Spanish": 2, "unknown": 1})
+	assertBuckets(t, "new vs returning", d.NewVsReturning, map[string]int{"new": 1, "returning": 1})
+	if d.Languages[0].Label != "Spanish" {
+		t.Errorf("Expected largest bucket first, got %+v", d.Languages)
+	}
+}
+
+func TestCounterBucketsOther(t *testing.T) {
+	c := counter{}
+	for _, v := range []string{"a", "a", "a", "b", "b", "c", "d", ""} {
+		c.add(v)
+	}
+	got := c.buckets(2)
+	if len(got) != 3 || got[0].Label != "a" || got[1].Label != "b" || got[2].Key != BucketOther || got[2].Count != 3 {
+		t.Errorf("buckets(2) = %+v", got)
+	}
+}
+
+func assertBuckets(t *testing.T, name string, buckets []Bucket, want map[string]int) {
+	t.Helper()
+	for _, b := range buckets {
+		label := b.Label
+		if b.Key != "" {
+			label = b.Key
+		}
+		if b.Count != want[label] {
+			t.Errorf("%s bucket %q: expected %d, got %d", name, label, want[label], b.Count)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"foodbank/internal/model"
+)
+
+// ServedCounts are unduplicated counts of the households and people served
+// in a date range. Sites breaks the counts down by food bank; a person served
+// at two sites is counted at each, so site counts can sum to more than the
+// overall counts.
+type ServedCounts struct {
+	From            string             `json:"from"`
+	To              string             `json:"to"`
+	Households      int                `json:"households"`
+	Individuals     int                `json:"individuals"`
+	Visits          int                `json:"visits"`
+	UnmatchedVisits int                `json:"unmatchedVisits"`
+	Sites           []SiteServedCounts `json:"sites"`
+}
+
+// SiteServedCounts are the unduplicated counts for one food bank.
+type SiteServedCounts struct {
+	FoodBankId  string `json:"foodBankId"`
+	Households  int    `json:"households"`
+	Individuals int    `json:"individuals"`
+	Visits      int    `json:"visits"`
+}
+
+// PersonKey identifies a person across households. The same person is often
+// registered in more than one household under different ids, so people are
+// matched on name and date of birth when both are known, and on id otherwise.
+func PersonKey(p model.Person) string {
+	first := strings.ToLower(strings.TrimSpace(p.FirstName))
+	last := strings.ToLower(strings.TrimSpace(p.LastName))
+	if first != "" && last != "" && p.DOB != "" {
+		return first + "|" + last + "|" + p.DOB
+	}
+	return "id:" + p.Id
+}
+
+type servedTally struct {
+	households  map[string]bool
+	individuals map[string]bool
+	visits      int
+}
+
+func newServedTally() *servedTally {
+	return &servedTally{households: map[string]bool{}, individuals: map[string]bool{}}
+}
+
+func (t *servedTally) add(h *model.Household) {
+	t.visits++
+	if t.households[h.Id] {
+		return
+	}
+	t.households[h.Id] = true
+	for _, p := range h.Persons() {
+		t.individuals[PersonKey(p)] = true
+	}
+}
+
+// CountServed counts the unique households and people served in d, overall
+// and per site.
+func CountServed(d *Dataset) ServedCounts {
+	overall := newServedTally()
+	sites := map[string]*servedTally{}
+	out := ServedCounts{From: d.From.Format("2006-01-02"), To: d.To.Format("2006-01-02"), Sites: []SiteServedCounts{}}
+
+	for _, v := range d.Visits {
+		h, ok := d.HouseholdFor(v.PersonId)
+		if !ok {
+			out.UnmatchedVisits++
+			continue
+		}
+		overall.add(h)
+		site, ok := sites[v.FoodBankId]
+		if !ok {
+			site = newServedTally()
+			sites[v.FoodBankId] = site
+		}
+		site.add(h)
+	}
+
+	out.Households = len(overall.households)
+	out.Individuals = len(overall.individuals)
+	out.Visits = overall.visits
+	for id, t := range sites {
+		out.Sites = append(out.Sites, SiteServedCounts{
+			FoodBankId:  id,
+			Households:  len(t.households),
+			Individuals: len(t.individuals),
+			Visits:      t.visits,
+		})
+	}
+	sort.Slice(out.Sites, func(i, j int) bool { return out.Sites[i].FoodBankId < out.Sites[j].FoodBankId })
+	return out
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"testing"
+
+	"foodbank/internal/model"
+)
+
+func TestCountServedAcrossHouseholdsAndSites(t *testing.T) {
+	mom := person("p1", "1985-04-04")
+	mom.LastName = "Reyes"
+	kid := person("p2", "2016-07-07")
+	kid.LastName = "Reyes"
+	dad := person("p3", "1984-02-02")
+	dad.LastName = "Reyes"
+	// the same child registered again, under a new id, in dad's household
+	kidAgain := kid
+	kidAgain.Id = "p4"
+
+	households := []model.Household{
+		{Id: "01A", Head: mom, Members: []model.Person{kid}},
+		{Id: "01B", Head: dad, Members: []model.Person{kidAgain}},
+	}
+	visits := []model.FoodBankVisit{
+		{Date: "2026-09-01", PersonId: "p1", FoodBankId: "north"},
+		{Date: "2026-09-10", PersonId: "p1", FoodBankId: "north"},
+		{Date: "2026-09-12", PersonId: "p3", FoodBankId: "south"},
+		{Date: "2026-09-20", PersonId: "p2", FoodBankId: "south"},
+	}
+
+	got := CountServed(testDataset(t, visits, households))
+
+	if got.Households != 2 || got.Individuals != 3 || got.Visits != 4 {
+		t.Errorf("Unexpected overall counts %+v", got)
+	}
+	if len(got.Sites) != 2 {
+		t.Fatalf("Expected 2 sites, got %+v", got.Sites)
+	}
+	north, south := got.Sites[0], got.Sites[1]
+	if north.FoodBankId != "north" || north.Households != 1 || north.Individuals != 2 || north.Visits != 2 {
+		t.Errorf("Unexpected north counts %+v", north)
+	}
+	if south.Households != 2 || south.Individuals != 3 || south.Visits != 2 {
+		t.Errorf("Unexpected south counts %+v", south)
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"strconv"
+	"time"
+
+	"foodbank/internal/model"
+)
+
+// AgeGroup is an inclusive range of ages. Max is -1 for no upper bound.
+type AgeGroup struct {
+	Label string
+	Min   int
+	Max   int
+}
+
+func (g AgeGroup) Contains(age int) bool {
+	return age >= g.Min && (g.Max < 0 || age <= g.Max)
+}
+
+// TEFAPAgeGroups are the age groups on the state TEFAP monthly report.
+var TEFAPAgeGroups = []AgeGroup{
+	{Label: "Ages 0-17", Min: 0, Max: 17},
+	{Label: "Ages 18-59", Min: 18, Max: 59},
+	{Label: "Ages 60+", Min: 60, Max: -1},
+}
+
+// TEFAPCounts are household and individual counts, with individuals broken
+// down by TEFAPAgeGroups. AgeUnknown counts people without a usable DOB.
+type TEFAPCounts struct {
+	Households  int
+	Individuals int
+	ByAge       []int
+	AgeUnknown  int
+}
+
+// TEFAPReport is the USDA TEFAP monthly distribution report for one site.
+// Unduplicated counts each household once per month; Duplicated counts a
+// household once for each day it was served.
+type TEFAPReport struct {
+	Month           string
+	Site            string
+	Unduplicated    TEFAPCounts
+	Duplicated      TEFAPCounts
+	UnmatchedVisits int
+}
+
+// TEFAP builds the report for the dataset's month. Ages are taken on the
+// last day of the month.
+func TEFAP(d *Dataset, site string) TEFAPReport {
+	r := TEFAPReport{
+		Month:        d.From.Format("2006-01"),
+		Site:         site,
+		Unduplicated: TEFAPCounts{ByAge: make([]int, len(TEFAPAgeGroups))},
+		Duplicated:   TEFAPCounts{ByAge: make([]int, len(TEFAPAgeGroups))},
+	}
+
+	seenMonth := map[string]bool{}
+	seenDay := map[string]bool{}
+	for _, v := range d.Visits {
+		h, ok := d.HouseholdFor(v.PersonId)
+		if !ok {
+			r.UnmatchedVisits++
+			continue
+		}
+		if dayKey := h.Id + "/" + v.Date; !seenDay[dayKey] {
+			seenDay[dayKey] = true
+			r.Duplicated.add(h.Persons(), d.To)
+		}
+		if !seenMonth[h.Id] {
+			seenMonth[h.Id] = true
+			r.Unduplicated.add(h.Persons(), d.To)
+		}
+	}
+
+	return r
+}
+
+func (c *TEFAPCounts) add(persons []model.Person, asOf time.Time) {
+	c.Households++
+	c.Individuals += len(persons)
+	for _, p := range persons {
+		age, ok := p.Age(asOf)
+		if !ok {
+			c.AgeUnknown++
+			continue
+		}
+		for i, g := range TEFAPAgeGroups {
+			if g.Contains(age) {
+				c.ByAge[i]++
+				break
+			}
+		}
+	}
+}
+
+// Table lays the report out as rows of cells for HTML, CSV and PDF output,
+// following the state agency's monthly form.
+func (r TEFAPReport) Table() [][]string {
+	header := []string{"", "Households", "Individuals"}
+	for _, g := range TEFAPAgeGroups {
+		header = append(header, g.Label)
+	}
+	header = append(header, "Age Unknown")
+
+	row := func(label string, c TEFAPCounts) []string {
+		cells := []string{label, strconv.Itoa(c.Households), strconv.Itoa(c.Individuals)}
+		for _, n := range c.ByAge {
+			cells = append(cells, strconv.Itoa(n))
+		}
+		return append(cells, strconv.Itoa(c.AgeUnknown))
+	}
+
+	return [][]string{
+		{"Agency", r.Site},
+		{"Report Month", r.Month},
+		{},
+		header,
+		row("Unduplicated", r.Unduplicated),
+		row("Duplicated", r.Duplicated),
+		{},
+		{"Visits not matched to a household", strconv.Itoa(r.UnmatchedVisits)},
+	}
+}
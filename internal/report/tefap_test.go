@@ -0,0 +1,54 @@
+package report
+
+import (
+	"testing"
+
+	"foodbank/internal/model"
+)
+
+func person(id string, dob string) model.Person {
+	return model.Person{PersonCommon: model.PersonCommon{Id: id, FirstName: id, DOB: dob}}
+}
+
+func testDataset(t *testing.T, visits []model.FoodBankVisit, households []model.Household) *Dataset {
+	from, to, err := MonthRange("2026-09")
+	if err != nil {
+		t.Fatalf("Failed to parse month: %v", err)
+	}
+	return NewDataset(from, to, visits, households)
+}
+
+func TestTEFAPCountsAgesAndDuplicates(t *testing.T) {
+	households := []model.Household{
+		{Id: "h1", Head: person("p1", "1980-10-01"), Members: []model.Person{
+			person("p2", "2015-02-03"),
+			person("p3", "1966-09-30"), // turns 60 on the last day of the month
+		}},
+		{Id: "h2", Head: person("p4", "not a date")},
+	}
+	visits := []model.FoodBankVisit{
+		{Id: "v1", Date: "2026-09-02", PersonId: "p1"},
+		{Id: "v2", Date: "2026-09-02", PersonId: "p2"}, // same household, same day
+		{Id: "v3", Date: "2026-09-16", PersonId: "p3"},
+		{Id: "v4", Date: "2026-09-16", PersonId: "p4"},
+		{Id: "v5", Date: "2026-09-20", PersonId: "unknown"},
+	}
+
+	r := TEFAP(testDataset(t, visits, households), "Main St")
+
+	if r.Month != "2026-09" {
+		t.Errorf("Expected month 2026-09, got %s", r.Month)
+	}
+	if r.Unduplicated.Households != 2 || r.Unduplicated.Individuals != 4 {
+		t.Errorf("Unexpected unduplicated counts %+v", r.Unduplicated)
+	}
+	if got := r.Unduplicated.ByAge; got[0] != 1 || got[1] != 1 || got[2] != 1 || r.Unduplicated.AgeUnknown != 1 {
+		t.Errorf("Unexpected unduplicated age counts %v unknown=%d", got, r.Unduplicated.AgeUnknown)
+	}
+	if r.Duplicated.Households != 3 || r.Duplicated.Individuals != 7 {
+		t.Errorf("Unexpected duplicated counts %+v", r.Duplicated)
+	}
+	if r.UnmatchedVisits != 1 {
+		t.Errorf("Expected 1 unmatched visit, got %d", r.UnmatchedVisits)
+	}
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+)
+
+// VisitSummary lays out visits per day followed by the unduplicated counts
+// for the whole range, for the weekly visit summary.
+func VisitSummary(d *Dataset) [][]string {
+	perDay := map[string]int{}
+	for _, v := range d.Visits {
+		perDay[v.Date]++
+	}
+	days := make([]string, 0, len(perDay))
+	for day := range perDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	served := CountServed(d)
+	rows := [][]string{
+		{"Visit Summary", served.From + " to " + served.To},
+		{},
+		{"Date", "Visits"},
+	}
+	for _, day := range days {
+		rows = append(rows, []string{day, strconv.Itoa(perDay[day])})
+	}
+	return append(rows,
+		[]string{},
+		[]string{"Total visits", strconv.Itoa(served.Visits)},
+		[]string{"Unduplicated households", strconv.Itoa(served.Households)},
+		[]string{"Unduplicated individuals", strconv.Itoa(served.Individuals)},
+		[]string{"Visits not matched to a household", strconv.Itoa(served.UnmatchedVisits)},
+	)
+}
@@ -0,0 +1,76 @@
+// Package retention finds households that have stopped visiting so their
+// personal data can be reviewed and anonymized or deleted.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Candidate is a household with no visits since the cutoff.
+type Candidate struct {
+	Household model.Household
+	// LastVisit is the date of the most recent visit by anyone in the
+	// household, formatted 2006-01-02, or "" if they never visited.
+	LastVisit string
+}
+
+// Cutoff returns the date households must have visited since to be kept.
+func Cutoff(now time.Time, months int) time.Time {
+	return now.AddDate(0, -months, 0)
+}
+
+// Check reports whether h is inactive: created before cutoff, not already
+// anonymized, and with no visit by any member on or after cutoff. lastVisit
+// maps person IDs to their latest visit date.
+func Check(h model.Household, lastVisit map[string]string, cutoff time.Time) (Candidate, bool) {
+	if h.Anonymized {
+		return Candidate{}, false
+	}
+	if id, err := ulid.Parse(h.Id); err == nil && !ulid.Time(id.Time()).Before(cutoff) {
+		return Candidate{}, false
+	}
+
+	c := Candidate{Household: h}
+	for _, p := range h.Persons() {
+		if d := lastVisit[p.Id]; d > c.LastVisit {
+			c.LastVisit = d
+		}
+	}
+	if c.LastVisit >= cutoff.Format("2006-01-02") {
+		return Candidate{}, false
+	}
+	return c, true
+}
+
+// Find returns the inactive households, oldest first.
+func Find(ctx context.Context, database *db.FirestoreDB, cutoff time.Time) ([]Candidate, error) {
+	lastVisit := map[string]string{}
+	err := database.EachFoodBankVisit(ctx, "0000-01-01", "9999-12-31", func(v model.FoodBankVisit) error {
+		if v.Date > lastVisit[v.PersonId] {
+			lastVisit[v.PersonId] = v.Date
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading visits: %w", err)
+	}
+
+	var candidates []Candidate
+	err = database.EachHousehold(ctx, func(h model.Household) error {
+		if c, ok := Check(h, lastVisit, cutoff); ok {
+			candidates = append(candidates, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading households: %w", err)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,58 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"foodbank/internal/model"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func household(created time.Time, personIDs ...string) model.Household {
+	h := model.Household{Id: ulid.MustNew(ulid.Timestamp(created), ulid.DefaultEntropy()).String()}
+	h.Head.Id = personIDs[0]
+	for _, id := range personIDs[1:] {
+		h.Members = append(h.Members, model.Person{PersonCommon: model.PersonCommon{Id: id}})
+	}
+	return h
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	cutoff := Cutoff(now, 12)
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastVisit := map[string]string{
+		"recent": "2024-01-10",
+		"stale":  "2022-03-04",
+		"member": "2023-08-01",
+	}
+
+	anonymized := household(old, "nobody")
+	anonymized.Anonymized = true
+
+	tests := []struct {
+		name      string
+		household model.Household
+		want      bool
+		lastVisit string
+	}{
+		{"recent visit", household(old, "recent"), false, ""},
+		{"stale visit", household(old, "stale"), true, "2022-03-04"},
+		{"member visited recently", household(old, "stale", "member"), false, ""},
+		{"never visited", household(old, "nobody"), true, ""},
+		{"new signup without visits", household(now.AddDate(0, -1, 0), "nobody"), false, ""},
+		{"already anonymized", anonymized, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := Check(tt.household, lastVisit, cutoff)
+			if ok != tt.want {
+				t.Fatalf("Check() = %v, want %v", ok, tt.want)
+			}
+			if ok && c.LastVisit != tt.lastVisit {
+				t.Errorf("LastVisit = %q, want %q", c.LastVisit, tt.lastVisit)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package scheduler delivers scheduled reports from inside the web server
+// process.
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"foodbank/internal/db"
+	"foodbank/internal/export"
+	"foodbank/internal/model"
+	"foodbank/internal/report"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Scheduler checks for due report schedules every Interval.
+type Scheduler struct {
+	DB       *db.FirestoreDB
+	Sender   Sender
+	Interval time.Duration
+}
+
+// Run checks schedules until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	schedules, err := s.DB.GetReportSchedules(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load report schedules")
+		return
+	}
+
+	for _, schedule := range schedules {
+		if !schedule.Enabled || schedule.NextRunAt.After(now) {
+			continue
+		}
+		claimed, err := s.DB.ClaimReportSchedule(ctx, schedule.Id, schedule.NextRunAt, schedule.NextRun(now))
+		if err != nil {
+			log.Error().Err(err).Str("schedule", schedule.Id).Msg("Failed to claim report schedule")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		runErr := ""
+		if err := s.Deliver(ctx, schedule, now); err != nil {
+			log.Error().Err(err).Str("schedule", schedule.Id).Msg("Failed to deliver scheduled report")
+			runErr = err.Error()
+		}
+		if err := s.DB.FinishReportSchedule(ctx, schedule.Id, now, runErr); err != nil {
+			log.Error().Err(err).Str("schedule", schedule.Id).Msg("Failed to record report run")
+		}
+	}
+}
+
+// Deliver builds the schedule's report as of now and sends it.
+func (s *Scheduler) Deliver(ctx context.Context, schedule model.ReportSchedule, now time.Time) error {
+	msg, err := Build(ctx, s.DB, schedule, now)
+	if err != nil {
+		return err
+	}
+	return s.Sender.Send(ctx, msg)
+}
+
+// Build generates the report for the period before now: the previous month
+// for TEFAP and the previous Monday to Sunday for the weekly summary.
+func Build(ctx context.Context, database *db.FirestoreDB, schedule model.ReportSchedule, now time.Time) (Message, error) {
+	siteName := "All sites"
+	if schedule.Site != "" {
+		site, err := database.GetFoodBank(ctx, schedule.Site)
+		if err != nil {
+			return Message{}, err
+		}
+		siteName = site.Name
+	}
+
+	from, to, title := ReportPeriod(schedule.Report, now)
+	data, err := report.Load(ctx, database, from, to, schedule.Site)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var table [][]string
+	switch schedule.Report {
+	case model.ReportTEFAPMonthly:
+		table = report.TEFAP(data, siteName).Table()
+	case model.ReportWeeklyVisits:
+		table = report.VisitSummary(data)
+	default:
+		return Message{}, fmt.Errorf("unknown report %q", schedule.Report)
+	}
+
+	var buf bytes.Buffer
+	contentType := export.ContentType(export.FormatCSV)
+	if schedule.Format == "pdf" {
+		contentType = "application/pdf"
+		err = export.WritePDF(&buf, append([]string{title, ""}, export.AlignColumns(table)...))
+	} else {
+		err = export.WriteCSV(&buf, table)
+	}
+	if err != nil {
+		return Message{}, err
+	}
+
+	period := from.Format("2006-01-02") + " to " + to.Format("2006-01-02")
+	return Message{
+		To:      schedule.Recipients,
+		Subject: fmt.Sprintf("%s: %s (%s)", schedule.Name, siteName, period),
+		Body:    fmt.Sprintf("Attached is the %s for %s, %s.\n", title, siteName, period),
+		Attachments: []Attachment{{
+			Filename:    fmt.Sprintf("%s-%s.%s", schedule.Report, from.Format("2006-01-02"), schedule.Format),
+			ContentType: contentType,
+			Data:        buf.Bytes(),
+		}},
+	}, nil
+}
+
+// ReportPeriod returns the dates covered by a report sent at now, and its title.
+func ReportPeriod(reportName string, now time.Time) (time.Time, time.Time, string) {
+	local := now.In(model.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	if reportName == model.ReportTEFAPMonthly {
+		thisMonth := today.AddDate(0, 0, 1-today.Day())
+		return thisMonth.AddDate(0, -1, 0), thisMonth.AddDate(0, 0, -1), "USDA TEFAP Monthly Distribution Report"
+	}
+
+	// the most recent Monday to Sunday that has ended
+	sunday := today.AddDate(0, 0, -int(today.Weekday()))
+	if sunday.Equal(today) {
+		sunday = sunday.AddDate(0, 0, -7)
+	}
+	return sunday.AddDate(0, 0, -6), sunday, "Weekly Visit Summary"
+}
+
+// Every calls fn every interval, starting immediately, until ctx is
+// cancelled. Errors are logged under name.
+func Every(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil {
+			log.Error().Err(err).Str("job", name).Msg("Scheduled job failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"foodbank/internal/model"
+)
+
+func TestReportPeriod(t *testing.T) {
+	monday := time.Date(2026, 10, 19, 12, 0, 0, 0, model.Location())
+
+	from, to, _ := ReportPeriod(model.ReportWeeklyVisits, monday)
+	if got := from.Format("2006-01-02") + " " + to.Format("2006-01-02"); got != "2026-10-12 2026-10-18" {
+		t.Errorf("Unexpected weekly period %s", got)
+	}
+
+	from, to, _ = ReportPeriod(model.ReportTEFAPMonthly, monday)
+	if got := from.Format("2006-01-02") + " " + to.Format("2006-01-02"); got != "2026-09-01 2026-09-30" {
+		t.Errorf("Unexpected monthly period %s", got)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	loc := model.Location()
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, loc) // a Monday
+
+	weekly := model.ReportSchedule{Report: model.ReportWeeklyVisits, Hour: 8}
+	if got := weekly.NextRun(now); !got.Equal(time.Date(2026, 10, 26, 8, 0, 0, 0, loc)) {
+		t.Errorf("Unexpected weekly next run %v", got)
+	}
+	weekly.Hour = 10
+	if got := weekly.NextRun(now); !got.Equal(time.Date(2026, 10, 19, 10, 0, 0, 0, loc)) {
+		t.Errorf("Unexpected weekly next run later today %v", got)
+	}
+
+	monthly := model.ReportSchedule{Report: model.ReportTEFAPMonthly, Hour: 6}
+	if got := monthly.NextRun(now); !got.Equal(time.Date(2026, 11, 1, 6, 0, 0, 0, loc)) {
+		t.Errorf("Unexpected monthly next run %v", got)
+	}
+}
+
+func TestDirSender(t *testing.T) {
+	dir := t.TempDir()
+	sender := &DirSender{Dir: dir}
+	err := sender.Send(context.Background(), Message{
+		To:          []string{"board@example.org"},
+		Subject:     "TEFAP: Main/St",
+		Body:        "Attached.",
+		Attachments: []Attachment{{Filename: "tefap.csv", ContentType: "text/csv", Data: []byte("a,b\n")}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), "TEFAP_Main_St") {
+		t.Fatalf("Expected one message directory, got %v", entries)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name(), "tefap.csv"))
+	if err != nil || string(data) != "a,b\n" {
+		t.Errorf("Unexpected attachment %q: %v", data, err)
+	}
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Attachment is a file sent with a Message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a delivered report.
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Sender delivers messages. SMTPSender emails them; DirSender writes them to
+// a local directory for testing.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender sends messages through an SMTP server using PLAIN auth when a
+// username is set.
+type SMTPSender struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := strings.Cut(s.Addr, ":")
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	body, err := s.mime(msg)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(s.Addr, auth, s.From, msg.To, body); err != nil {
+		return fmt.Errorf("error sending report email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPSender) mime(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", s.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	text.Write([]byte(msg.Body))
+
+	for _, att := range msg.Attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {att.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(att.Data)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DirSender writes each message to its own directory under Dir, holding the
+// body, the recipients and the attachments.
+type DirSender struct {
+	Dir string
+}
+
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (s *DirSender) Send(ctx context.Context, msg Message) error {
+	name := time.Now().Format("20060102-150405") + "-" + unsafeFilename.ReplaceAllString(msg.Subject, "_")
+	dir := filepath.Join(s.Dir, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating report directory: %w", err)
+	}
+
+	files := map[string][]byte{
+		"message.txt": []byte("To: " + strings.Join(msg.To, ", ") + "\nSubject: " + msg.Subject + "\n\n" + msg.Body),
+	}
+	for _, att := range msg.Attachments {
+		files[unsafeFilename.ReplaceAllString(att.Filename, "_")] = att.Data
+	}
+	for filename, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, filename), data, 0o644); err != nil {
+			return fmt.Errorf("error writing report file: %w", err)
+		}
+	}
+	return nil
+}
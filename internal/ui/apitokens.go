@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/middleware"
+	"foodbank/internal/model"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+// APITokenPage lets admins create and revoke API tokens for machine clients.
+type APITokenPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *APITokenPage) GET(c echo.Context) error {
+	return p.getPage(c, "", ValidationErrors{})
+}
+
+// POST creates a token and shows its plain value once.
+func (p *APITokenPage) POST(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	form, err := c.FormParams()
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, fmt.Sprintf("Invalid form: %v", err))
+	}
+
+	plain, hash, err := model.NewAPITokenSecret()
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to generate token: %v", err))
+	}
+	token := model.APIToken{
+		Id:        ulid.Make().String(),
+		Name:      strings.TrimSpace(c.FormValue("name")),
+		Hash:      hash,
+		Scope:     c.FormValue("scope"),
+		Resources: form["resources"],
+		CreatedBy: middleware.Actor(c),
+		CreatedAt: time.Now(),
+	}
+
+	if verrs := token.Validate(); verrs.HasErrors() {
+		return p.getPage(c, "", FormErrors(verrs, GetResourceBundle(c)))
+	}
+
+	if err := p.DB.PutAPIToken(ctx, token); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save token: %v", err))
+	}
+	return p.getPage(c, plain, ValidationErrors{})
+}
+
+// Revoke marks the token named by the id path parameter as revoked.
+func (p *APITokenPage) Revoke(c echo.Context) error {
+	if err := p.DB.RevokeAPIToken(c.Request().Context(), c.Param("id"), time.Now()); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to revoke token: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/tokens")
+}
+
+func (p *APITokenPage) getPage(c echo.Context, created string, errs ValidationErrors) error {
+	rb := GetResourceBundle(c)
+	tokens, err := p.DB.GetAPITokens(c.Request().Context())
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load tokens: %v", err))
+	}
+
+	rows := make([]HTML, len(tokens))
+	for i, t := range tokens {
+		var status HTML
+		if t.Revoked() {
+			status = Text(rb.Getf("tokens.revokedat", Args{"time": formatTime(t.RevokedAt, rb)}))
+		} else {
+			status = Span_(Text(rb.Get("tokens.active")+" "),
+				Form(Attr(a.Class("d-inline"), a.Action(fmt.Sprintf("/admin/tokens/%s/revoke", t.Id)), a.Method("POST"),
+					confirmSubmit(rb.Get("tokens.confirmrevoke"))),
+					Button(Attr(a.Class("btn btn-sm btn-outline-danger"), a.Type("submit")), Text(rb.Get("tokens.revoke")))))
+		}
+		rows[i] = Tr_(
+			Td_(Text(t.Name)),
+			Td_(Text(t.Scope)),
+			Td_(Text(strings.Join(t.Resources, ", "))),
+			Td_(Text(rb.Getf("tokens.createdby", Args{"time": formatTime(t.CreatedAt, rb), "actor": t.CreatedBy}))),
+			Td_(Text(formatTime(t.LastUsedAt, rb))),
+			Td_(status),
+		)
+	}
+
+	var notice HTML
+	if created != "" {
+		notice = Div(Attr(a.Class("alert alert-success")),
+			P_(Text(rb.Get("tokens.created"))),
+			Pre_(Text(created)),
+		)
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	form, _ := c.FormParams()
+	resourceBoxes := make([]HTML, len(model.APIResources))
+	for i, r := range model.APIResources {
+		attrs := []a.Attribute{a.Type("checkbox"), a.Class("form-check-input"), a.Name("resources"), a.Value(r), a.Id("res-" + r)}
+		if slices.Contains(form["resources"], r) {
+			attrs = append(attrs, a.Checked("checked"))
+		}
+		resourceBoxes[i] = Div(Attr(a.Class("form-check form-check-inline")),
+			Input(attrs),
+			Label(Attr(a.Class("form-check-label"), a.For("res-"+r)), Text(r)),
+		)
+	}
+	var resourcesErr HTML
+	if msg, ok := errs["resources"]; ok {
+		resourcesErr = Div(Attr(a.Class("small text-danger")), Text(msg))
+	}
+
+	page := StaffPage(rb, rb.Get("tokens.title"),
+		H1_(Text(rb.Get("tokens.title"))),
+		notice,
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("misc.name"))),
+				Th_(Text(rb.Get("tokens.scope"))),
+				Th_(Text(rb.Get("tokens.resources"))),
+				Th_(Text(rb.Get("misc.created"))),
+				Th_(Text(rb.Get("tokens.lastused"))),
+				Th_(Text(rb.Get("misc.status"))),
+			)),
+			Tbody_(rows...)),
+		H2(Attr(a.Class("my-4")), Text(rb.Get("tokens.new"))),
+		Form(Attr(a.Action("/admin/tokens"), a.Method("POST")),
+			Div(Attr(a.Class("form-row")),
+				fb.InputDiv("col-md-6", "name", rb.Get("misc.name")),
+				fb.SelectDiv("col-md-6", "scope", rb.Get("tokens.scope"), []ValueLabel{
+					{Value: model.APITokenScopeRead, Label: rb.Get("tokens.scope.read")},
+					{Value: model.APITokenScopeReadWrite, Label: rb.Get("tokens.scope.readwrite")},
+				}),
+			),
+			Div(Attr(a.Class("form-group")),
+				Label_(Text(rb.Get("tokens.resources"))),
+				Div_(resourceBoxes...),
+				resourcesErr,
+			),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("tokens.create"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// formatTime formats t for staff pages, or returns "never" for the zero time.
+func formatTime(t time.Time, rb *ResourceBundle) string {
+	if t.IsZero() {
+		return rb.Get("misc.never")
+	}
+	return model.FormatTimestamp(t)
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+// PublicBookingPage lets clients who have signed up book a pickup slot
+// themselves, and cancel it from the confirmation page. They are recognized
+// by the head of household's name and date of birth; the page never shows
+// anything stored about them.
+type PublicBookingPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *PublicBookingPage) GET(c echo.Context) error {
+	return p.getPage(c, ValidationErrors{})
+}
+
+// POST books the chosen slot for the client's household.
+func (p *PublicBookingPage) POST(c echo.Context) error {
+	ctx := db.WithActor(c.Request().Context(), "booking")
+	rb := GetResourceBundle(c)
+
+	head := toPerson("book", c)
+	var errs model.ValidationErrors
+	if head.FirstName == "" {
+		errs = append(errs, model.ValidationError{Field: "bookFirstName", Type: "missing", Message: "field_missing"})
+	}
+	if head.LastName == "" {
+		errs = append(errs, model.ValidationError{Field: "bookLastName", Type: "missing", Message: "field_missing"})
+	}
+	if head.DOB == "" {
+		errs = append(errs, model.ValidationError{Field: "bookDob", Type: "missing", Message: "field_missing"})
+	}
+	booking := model.Booking{
+		Id:     ulid.Make().String(),
+		SlotId: c.FormValue("slot"),
+		Date:   c.FormValue("date"),
+		Status: model.BookingBooked,
+	}
+	for _, e := range booking.Validate() {
+		if e.Field != "householdId" {
+			errs = append(errs, e)
+		}
+	}
+	if errs.HasErrors() {
+		return p.getPage(c, bookingErrors(errs, rb))
+	}
+
+	search := model.Household{Head: model.Person{PersonCommon: model.PersonCommon{
+		FirstName: head.FirstName, LastName: head.LastName, DOB: head.DOB,
+	}}}
+	matches, err := p.DB.FindHouseholdMatches(ctx, search)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to find household: %v", err))
+	}
+	household, ok := model.BestMatch(search, matches)
+	if !ok {
+		return p.getPage(c, ValidationErrors{"household": rb.Get("book.notfound")})
+	}
+
+	booking.HouseholdId = household.Id
+	errs, err = p.DB.BookPickup(ctx, booking)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to book slot: %v", err))
+	}
+	if errs.HasErrors() {
+		return p.getPage(c, bookingErrors(errs, rb))
+	}
+	return c.Redirect(http.StatusSeeOther, "/book/"+booking.Id)
+}
+
+// Booking confirms a booking made on the public page. Its address is the
+// client's way back to cancel it.
+func (p *PublicBookingPage) Booking(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	booking, err := p.DB.GetBooking(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Booking not found: %v", err))
+	}
+	var site string
+	if fb, err := p.DB.GetFoodBank(ctx, booking.FoodBankId); err == nil {
+		site = fb.Name
+	}
+
+	var cancel HTML
+	today := time.Now().In(model.Location()).Format("2006-01-02")
+	if booking.CanBecome(model.BookingCancelled) && booking.Date >= today {
+		cancel = Form(Attr(a.Action(fmt.Sprintf("/book/%s/cancel", booking.Id)), a.Method("POST")),
+			P_(Text(rb.Get("book.keeplink"))),
+			Button(Attr(a.Class("btn btn-outline-danger"), a.Type("submit"), confirmClick(rb.Get("booking.confirmcancel"))),
+				Text(rb.Get("booking.cancel"))),
+		)
+	}
+	page := p.layout(rb,
+		H1(Attr(a.Class("text-center")), Text(rb.Get("book.title"))),
+		Div(Attr(a.Class("alert alert-light border text-center h4")),
+			P_(Text(rb.Getf("book.booked", Args{
+				"date": slotDate(booking.Date, rb),
+				"time": slotTimes(booking.Start, booking.End),
+				"site": site,
+			}))),
+			bookingStatus(*booking, rb),
+		),
+		cancel,
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// Cancel cancels a booking made on the public page.
+func (p *PublicBookingPage) Cancel(c echo.Context) error {
+	ctx := db.WithActor(c.Request().Context(), "booking")
+	booking, err := p.DB.GetBooking(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Booking not found: %v", err))
+	}
+	// a booking already cancelled, visited or past is shown as it is, as the
+	// page offers no cancel button for it
+	today := time.Now().In(model.Location()).Format("2006-01-02")
+	if booking.CanBecome(model.BookingCancelled) && booking.Date >= today {
+		if err := p.DB.SetBookingStatus(ctx, booking.Id, model.BookingCancelled); err != nil {
+			return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to cancel booking: %v", err))
+		}
+	}
+	return c.Redirect(http.StatusSeeOther, "/book/"+booking.Id)
+}
+
+func (p *PublicBookingPage) getPage(c echo.Context, errs ValidationErrors) error {
+	rb := GetResourceBundle(c)
+	sites, err := p.DB.GetFoodBanks(c.Request().Context())
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	site, date := c.FormValue("site"), c.FormValue("date")
+	slots, bookings, err := slotsOn(c, p.DB, site, date)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load slots: %v", err))
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	var book HTML
+	if site != "" && date != "" {
+		book = Form(Attr(a.Action("/book"), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(site))),
+			Input(Attr(a.Type("hidden"), a.Name("date"), a.Value(date))),
+			slotChoice(slots, bookings, date, fb, rb),
+			P(Attr(a.Class("text-muted")), Text(rb.Get("book.whoareyou"))),
+			Div(Attr(a.Class("form-row")),
+				fb.InputDiv("col-md-6", "bookFirstName", rb.Get("misc.firstname")),
+				fb.InputDiv("col-md-6", "bookLastName", rb.Get("misc.lastname")),
+			),
+			dobField("", "book", fb, rb),
+			errorAlert(errs["household"]),
+			Button(Attr(a.Class("btn btn-primary btn-lg"), a.Type("submit")), Text(rb.Get("booking.book"))),
+		)
+	}
+	page := p.layout(rb,
+		H1(Attr(a.Class("text-center")), Text(rb.Get("book.title"))),
+		P(Attr(a.Class("text-center")), Text(rb.Get("book.intro"))),
+		languageSwitcher(""),
+		slotPicker("/book", sites, fb, rb),
+		book,
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+func (p *PublicBookingPage) layout(rb *ResourceBundle, body ...HTML) HTML {
+	content := append([]HTML{
+		Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
+	}, body...)
+	return Html5(pageAttrs(rb),
+		pageHead(rb, rb.Get("book.title")),
+		Body_(
+			Div(Attr(a.Class("container my-5")), content...),
+		))
+}
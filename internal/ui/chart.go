@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/report"
+	"html"
+	"strings"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+)
+
+const (
+	chartWidth      = 480
+	chartLabelWidth = 140
+	chartBarHeight  = 22
+	chartBarGap     = 6
+)
+
+// BarChart renders buckets as a horizontal bar chart in inline SVG, so charts
+// need no client-side scripts.
+func BarChart(title string, buckets []report.Bucket, rb *ResourceBundle) HTML {
+	maxCount := 0
+	total := 0
+	for _, b := range buckets {
+		maxCount = max(maxCount, b.Count)
+		total += b.Count
+	}
+
+	barSpace := chartWidth - chartLabelWidth - 60
+	height := len(buckets)*(chartBarHeight+chartBarGap) + chartBarGap
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" role="img" aria-label="%s">`,
+		chartWidth, height, html.EscapeString(title))
+	for i, b := range buckets {
+		y := chartBarGap + i*(chartBarHeight+chartBarGap)
+		w := 0
+		if maxCount > 0 {
+			w = b.Count * barSpace / maxCount
+		}
+		pct := 0.0
+		if total > 0 {
+			pct = float64(b.Count) * 100 / float64(total)
+		}
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="end" font-size="13">%s</text>`,
+			chartLabelWidth-8, y+chartBarHeight-6, html.EscapeString(bucketLabel(b, rb)))
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="#1f7bd8"></rect>`,
+			chartLabelWidth, y, w, chartBarHeight)
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" font-size="12">%d (%.0f%%)</text>`,
+			chartLabelWidth+w+6, y+chartBarHeight-6, b.Count, pct)
+	}
+	svg.WriteString(`</svg>`)
+
+	return Div(Attr(a.Class("col-md-6 mb-4")),
+		H5_(Text(title)),
+		HTML(svg.String()),
+	)
+}
+
+// bucketLabel is the text of a bar, translating the buckets a report makes up.
+func bucketLabel(b report.Bucket, rb *ResourceBundle) string {
+	if b.Key != "" {
+		return rb.Get("reports.bucket." + b.Key)
+	}
+	return b.Label
+}
@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+// CheckInPage is where staff find a household when it arrives at a site and
+// record its visit.
+type CheckInPage struct {
+	DB *db.FirestoreDB
+}
+
+// GET looks up households by the head's phone number, or name and date of
+// birth, the same way returning clients are recognized at signup.
+func (p *CheckInPage) GET(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	site := c.QueryParam("site")
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+
+	var notice HTML
+	if id := c.QueryParam("checkedin"); id != "" {
+		if h, err := p.DB.GetHouseholdByID(ctx, id); err == nil {
+			var ticket HTML
+			if number := c.QueryParam("number"); number != "" {
+				ticket = Div(Attr(a.Class("display-4")), Text(rb.Getf("queue.ticket", Args{"number": number})))
+			}
+			notice = Div(Attr(a.Class("alert alert-success")),
+				Text(rb.Getf("checkin.done", Args{"name": h.Head.FirstName + " " + h.Head.LastName})),
+				ticket)
+		}
+	}
+
+	var results HTML
+	search := model.Household{Head: toPerson("search", c)}
+	if len(search.MatchKeys()) > 0 {
+		matches, err := p.DB.FindHouseholdMatches(ctx, search)
+		if err != nil {
+			return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to find households: %v", err))
+		}
+		results = checkInResults(matches, site, rb)
+	} else if c.QueryParam("searchPhone") != "" || c.QueryParam("searchLastName") != "" {
+		results = Div(Attr(a.Class("alert alert-warning")), Text(rb.Get("checkin.needmore")))
+	}
+
+	var roster HTML
+	if site != "" {
+		query := url.Values{"site": {site}}.Encode()
+		roster = P_(
+			A(Attr(a.Href("/roster?"+query)), Text(rb.Get("roster.title"))),
+			Text(" | "),
+			A(Attr(a.Href("/queue?"+query)), Text(rb.Get("queue.title"))),
+		)
+	}
+
+	fb := &FormBuilder{Errs: ValidationErrors{}, C: c}
+	page := StaffPage(rb, rb.Get("checkin.title"),
+		H1_(Text(rb.Get("checkin.title"))),
+		notice,
+		roster,
+		Form(Attr(a.Action("/checkin"), a.Method("GET")),
+			Div(Attr(a.Class("form-row")),
+				fb.SelectDiv("col-md-6", "site", rb.Get("reports.site"), siteOptions(sites, rb)),
+				fb.InputDiv("col-md-6", "searchPhone", rb.Get("misc.phone")),
+			),
+			P(Attr(a.Class("text-muted")), Text(rb.Get("checkin.or"))),
+			Div(Attr(a.Class("form-row")),
+				fb.InputDiv("col-md-3", "searchFirstName", rb.Get("misc.firstname")),
+				fb.InputDiv("col-md-3", "searchLastName", rb.Get("misc.lastname")),
+				dobField("col-md-6", "search", fb, rb),
+			),
+			Button(Attr(a.Class("btn btn-primary mb-4"), a.Type("submit")), Text(rb.Get("checkin.find"))),
+		),
+		results,
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+func checkInResults(households []model.Household, site string, rb *ResourceBundle) HTML {
+	if len(households) == 0 {
+		return P(Attr(a.Class("alert alert-info")), Text(rb.Get("checkin.nomatch")))
+	}
+	rows := make([]HTML, len(households))
+	for i, h := range households {
+		href := fmt.Sprintf("/checkin/%s?%s", h.Id, url.Values{"site": {site}}.Encode())
+		rows[i] = Tr_(
+			Td_(Text(h.Head.FirstName+" "+h.Head.LastName)),
+			Td_(Text(rb.FormatDOB(h.Head.DOB))),
+			Td_(Text(h.Head.Phone)),
+			Td_(Text(h.Size())),
+			Td_(A(Attr(a.Class("btn btn-sm btn-primary"), a.Href(href)), Text(rb.Get("checkin.checkin")))),
+		)
+	}
+	return Table(Attr(a.Class("table table-striped")),
+		Thead_(Tr_(
+			Th_(Text(rb.Get("misc.name"))), Th_(Text(rb.Get("misc.dob"))), Th_(Text(rb.Get("misc.phone"))),
+			Th_(Text(rb.Get("reports.householdsize"))), Th_(),
+		)),
+		Tbody_(rows...))
+}
+
+// Household shows the household to check in, with its eligibility.
+func (p *CheckInPage) Household(c echo.Context) error {
+	return p.householdPage(c, ValidationErrors{})
+}
+
+// POST records the household's visit to the chosen site today and gives it a
+// number in the site's queue. Its booking for the site today, if it has one,
+// is marked visited.
+func (p *CheckInPage) POST(c echo.Context) error {
+	ctx := c.Request().Context()
+	household, err := p.DB.GetHouseholdByID(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Household not found: %v", err))
+	}
+
+	visit := model.FoodBankVisit{
+		Id:         ulid.Make().String(),
+		Date:       time.Now().In(model.Location()).Format("2006-01-02"),
+		PersonId:   household.Head.Id,
+		FoodBankId: c.FormValue("site"),
+		Notes:      strings.TrimSpace(c.FormValue("notes")),
+	}
+	if errs := visit.Validate(); errs.HasErrors() {
+		errs := FormErrors(errs, GetResourceBundle(c))
+		// the site is chosen as "site"
+		if msg, ok := errs["foodBankId"]; ok {
+			errs["site"] = msg
+		}
+		return p.householdPage(c, errs)
+	}
+	ticket, err := p.DB.CheckIn(ctx, household.Id, visit)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to record visit: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/checkin?"+url.Values{
+		"site":      {visit.FoodBankId},
+		"checkedin": {household.Id},
+		"number":    {strconv.Itoa(ticket.Number)},
+	}.Encode())
+}
+
+func (p *CheckInPage) householdPage(c echo.Context, errs ValidationErrors) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	household, err := p.DB.GetHouseholdByID(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Household not found: %v", err))
+	}
+	guidelines, err := p.DB.GetPovertyGuidelines(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load poverty guidelines: %v", err))
+	}
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+
+	head := household.Head
+	members := make([]HTML, len(household.Members))
+	for i, m := range household.Members {
+		members[i] = Li_(Text(fmt.Sprintf("%s %s (%s)", m.FirstName, m.LastName, optionLabel(relationshipOptions(rb), m.Relationship))))
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	page := StaffPage(rb, rb.Get("checkin.title"),
+		H1_(Text(head.FirstName+" "+head.LastName)),
+		certificationBanner(*household, c.QueryParam("site"), rb),
+		Table(Attr(a.Class("table table-bordered")),
+			Tbody_(
+				Tr_(Td_(Text(rb.Get("misc.dob"))), Td_(Text(rb.FormatDOB(head.DOB)))),
+				Tr_(Td_(Text(rb.Get("reports.householdsize"))), Td_(Text(household.Size()))),
+				Tr_(Td_(Text(rb.Get("signup.income"))), Td_(Text(incomeText(*household, rb)))),
+				Tr_(Td_(Text(rb.Get("eligibility.title"))), Td_(eligibilityStatus(household.Eligibility(guidelines, time.Now()), rb))),
+				Tr_(Td_(Text(rb.Get("recertify.certified"))), Td_(certificationStatus(*household, rb))),
+			),
+		),
+		Ul_(members...),
+		Form(Attr(a.Action("/checkin/"+household.Id), a.Method("POST")),
+			Div(Attr(a.Class("form-row")),
+				fb.SelectDiv("col-md-6", "site", rb.Get("reports.site"), siteOptions(sites, rb)),
+				fb.InputDiv("col-md-6", "notes", rb.Get("checkin.notes")),
+			),
+			Button(Attr(a.Class("btn btn-primary btn-lg"), a.Type("submit")), Text(rb.Get("checkin.record"))),
+			A(Attr(a.Class("btn btn-link"), a.Href("/household/"+household.Id)), Text(rb.Get("misc.view"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// siteOptions offers the sites to choose from, with an empty choice first.
+func siteOptions(sites []model.FoodBank, rb *ResourceBundle) []ValueLabel {
+	options := []ValueLabel{{Value: "", Label: rb.Get("checkin.choosesite")}}
+	for _, s := range sites {
+		options = append(options, ValueLabel{Value: s.Id, Label: s.Name})
+	}
+	return options
+}
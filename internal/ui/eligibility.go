@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"math"
+	"net/http"
+	"strconv"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// formatMoney formats a dollar amount to the whole dollar.
+func formatMoney(rb *ResourceBundle, v float64) string {
+	return "$" + rb.FormatNumber(math.Round(v))
+}
+
+// incomeText shows the household's income as reported, such as "$1,200 a
+// month".
+func incomeText(h model.Household, rb *ResourceBundle) string {
+	if h.Income == "" {
+		return rb.Get("income.notreported")
+	}
+	amount, err := model.ParseAmount(h.Income)
+	if err != nil {
+		return h.Income
+	}
+	return rb.Getf("income.amount", Args{"amount": formatMoney(rb, amount), "period": h.IncomePeriod})
+}
+
+var eligibilityClasses = map[string]string{
+	model.Eligible:           "badge-success",
+	model.Ineligible:         "badge-danger",
+	model.EligibilityUnknown: "badge-secondary",
+}
+
+// eligibilityStatus shows a household's eligibility as a badge, followed by
+// the figures it was worked out from.
+func eligibilityStatus(e model.Eligibility, rb *ResourceBundle) HTML {
+	var detail string
+	switch {
+	case e.Guideline.Year == 0:
+		detail = rb.Get("eligibility.noguideline")
+	case e.Status == model.EligibilityUnknown:
+		detail = rb.Getf("eligibility.noincome", Args{"size": e.Size, "limit": formatMoney(rb, e.Limit)})
+	default:
+		detail = rb.Getf("eligibility.detail", Args{
+			"size":    e.Size,
+			"income":  formatMoney(rb, e.AnnualIncome),
+			"limit":   formatMoney(rb, e.Limit),
+			"percent": e.Guideline.Percent,
+			"year":    strconv.Itoa(e.Guideline.Year),
+		})
+	}
+	return Span_(
+		Span(Attr(a.Class("badge "+eligibilityClasses[e.Status])), Text(rb.Get("eligibility."+e.Status))),
+		Text(" "+detail),
+	)
+}
+
+// PovertyGuidelinePage lets staff enter the federal poverty guidelines and
+// the state's TEFAP income limit for each year.
+type PovertyGuidelinePage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *PovertyGuidelinePage) GET(c echo.Context) error {
+	return p.getPage(c, ValidationErrors{})
+}
+
+// POST saves the guideline for a year, replacing that year's if there is one.
+func (p *PovertyGuidelinePage) POST(c echo.Context) error {
+	year, err := strconv.Atoi(c.FormValue("year"))
+	if err != nil {
+		year = 0
+	}
+	percent, err := strconv.Atoi(c.FormValue("percent"))
+	if err != nil {
+		percent = 0
+	}
+	first, err := model.ParseAmount(c.FormValue("firstPerson"))
+	if err != nil {
+		first = 0
+	}
+	additional, err := model.ParseAmount(c.FormValue("additionalPerson"))
+	if err != nil {
+		additional = -1
+	}
+
+	guideline := model.PovertyGuideline{
+		Id:               strconv.Itoa(year),
+		Year:             year,
+		FirstPerson:      first,
+		AdditionalPerson: additional,
+		Percent:          percent,
+	}
+	if errs := guideline.Validate(); errs.HasErrors() {
+		return p.getPage(c, FormErrors(errs, GetResourceBundle(c)))
+	}
+
+	if err := p.DB.PutPovertyGuideline(c.Request().Context(), guideline); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save guideline: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/guidelines")
+}
+
+// Delete removes a year's guideline.
+func (p *PovertyGuidelinePage) Delete(c echo.Context) error {
+	if err := p.DB.DeletePovertyGuideline(c.Request().Context(), c.Param("id")); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to delete guideline: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/guidelines")
+}
+
+func (p *PovertyGuidelinePage) getPage(c echo.Context, errs ValidationErrors) error {
+	rb := GetResourceBundle(c)
+	guidelines, err := p.DB.GetPovertyGuidelines(c.Request().Context())
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load guidelines: %v", err))
+	}
+
+	rows := make([]HTML, len(guidelines))
+	for i, g := range guidelines {
+		rows[i] = Tr_(
+			Td_(Text(g.Year)),
+			Td_(Text(formatMoney(rb, g.FirstPerson))),
+			Td_(Text(formatMoney(rb, g.AdditionalPerson))),
+			Td_(Text(fmt.Sprintf("%d%%", g.Percent))),
+			Td_(Text(formatMoney(rb, g.Limit(4)))),
+			Td_(Form(Attr(a.Class("d-inline"), a.Action(fmt.Sprintf("/admin/guidelines/%s/delete", g.Id)), a.Method("POST")),
+				Button(Attr(a.Class("btn btn-sm btn-outline-danger"), a.Type("submit"),
+					confirmClick(rb.Get("guidelines.confirmdelete"))), Text(rb.Get("misc.delete"))))),
+		)
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	page := StaffPage(rb, rb.Get("guidelines.title"),
+		H1_(Text(rb.Get("guidelines.title"))),
+		P_(Text(rb.Get("guidelines.intro"))),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("guidelines.year"))), Th_(Text(rb.Get("guidelines.firstperson"))),
+				Th_(Text(rb.Get("guidelines.additionalperson"))), Th_(Text(rb.Get("guidelines.percent"))),
+				Th_(Text(rb.Get("guidelines.limit4"))), Th_(),
+			)),
+			Tbody_(rows...)),
+		H2(Attr(a.Class("my-4")), Text(rb.Get("guidelines.new"))),
+		Form(Attr(a.Action("/admin/guidelines"), a.Method("POST")),
+			Div(Attr(a.Class("form-row")),
+				fb.TypedInputDiv("col-md-3", "number", "year", rb.Get("guidelines.year")),
+				fb.InputDiv("col-md-3", "firstPerson", rb.Get("guidelines.firstperson")),
+				fb.InputDiv("col-md-3", "additionalPerson", rb.Get("guidelines.additionalperson")),
+				fb.TypedInputDiv("col-md-3", "number", "percent", rb.Get("guidelines.percent")),
+			),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("misc.save"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/export"
+	"foodbank/internal/model"
+	"net/http"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportPage lets staff download households, persons and visits as CSV or XLSX.
+type ExportPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *ExportPage) GET(c echo.Context) error {
+	today := time.Now().Format("2006-01-02")
+	monthStart := time.Now().Format("2006-01") + "-01"
+	rb := GetResourceBundle(c)
+
+	page := StaffPage(rb, rb.Get("export.title"),
+		H1_(Text(rb.Get("export.title"))),
+		Form(Attr(a.Action("/export/download"), a.Method("GET")),
+			Div(Attr(a.Class("form-row")),
+				Div(Attr(a.Class("form-group col-md-6")),
+					Label(Attr(a.For("dataset")), Text(rb.Get("export.data"))),
+					Select(Attr(a.Class("form-control"), a.Name("dataset"), a.Id("dataset")),
+						Option(Attr(a.Value("households")), Text(rb.Get("export.households"))),
+						Option(Attr(a.Value("persons")), Text(rb.Get("export.persons"))),
+						Option(Attr(a.Value("visits")), Text(rb.Get("export.visits"))),
+					),
+				),
+				Div(Attr(a.Class("form-group col-md-6")),
+					Label(Attr(a.For("format")), Text(rb.Get("misc.format"))),
+					Select(Attr(a.Class("form-control"), a.Name("format"), a.Id("format")),
+						Option(Attr(a.Value(export.FormatCSV)), Text("CSV")),
+						Option(Attr(a.Value(export.FormatXLSX)), Text(rb.Get("export.xlsx"))),
+					),
+				),
+			),
+			Div(Attr(a.Class("form-row")),
+				dateInputDiv("col-md-6", "from", rb.Get("export.from"), monthStart),
+				dateInputDiv("col-md-6", "to", rb.Get("export.to"), today),
+			),
+			columnChoices("householdColumns", rb.Get("export.householdcolumns"), export.HouseholdColumns),
+			columnChoices("personColumns", rb.Get("export.personcolumns"), export.PersonColumns),
+			columnChoices("visitColumns", rb.Get("export.visitcolumns"), export.VisitColumns),
+			P(Attr(a.Class("text-muted")), Text(rb.Get("export.allcolumns"))),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("misc.download"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// Download streams the requested export straight from Firestore to the response.
+func (p *ExportPage) Download(c echo.Context) error {
+	ctx := c.Request().Context()
+	format := c.QueryParam("format")
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		return c.String(http.StatusBadRequest, "format must be csv or xlsx")
+	}
+	params := c.QueryParams()
+
+	switch dataset := c.QueryParam("dataset"); dataset {
+	case "households":
+		return writeExport(c, dataset, format, export.HouseholdColumns, params["householdColumns"],
+			func(fn func(model.Household) error) error {
+				return p.DB.EachHousehold(ctx, fn)
+			})
+	case "persons":
+		return writeExport(c, dataset, format, export.PersonColumns, params["personColumns"],
+			func(fn func(export.PersonRow) error) error {
+				return p.DB.EachHousehold(ctx, func(h model.Household) error {
+					for _, row := range export.PersonRows(h) {
+						if err := fn(row); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			})
+	case "visits":
+		from, to := c.QueryParam("from"), c.QueryParam("to")
+		if !isDate(from) || !isDate(to) || from > to {
+			return c.String(http.StatusBadRequest, "from and to must be dates (YYYY-MM-DD) with from on or before to")
+		}
+		return writeExport(c, fmt.Sprintf("visits-%s-to-%s", from, to), format, export.VisitColumns, params["visitColumns"],
+			func(fn func(model.FoodBankVisit) error) error {
+				return p.DB.EachFoodBankVisit(ctx, from, to, fn)
+			})
+	default:
+		return c.String(http.StatusBadRequest, "dataset must be households, persons or visits")
+	}
+}
+
+// writeExport selects columns, then streams rows produced by each. Errors
+// after the header is sent can only be logged, leaving a truncated file.
+func writeExport[T any](c echo.Context, name string, format string, all []export.Column[T], keys []string, each func(func(T) error) error) error {
+	columns, err := export.SelectColumns(all, keys)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, export.ContentType(format))
+	res.Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, time.Now().Format("2006-01-02"), format))
+	res.WriteHeader(http.StatusOK)
+
+	rw, err := export.NewRowWriter(res, format)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start export")
+		return nil
+	}
+	table, err := export.NewTable(rw, columns)
+	if err == nil {
+		err = each(table.Write)
+	}
+	if err == nil {
+		err = rw.Close()
+	}
+	if err != nil {
+		log.Error().Err(err).Str("export", name).Msg("Export failed part way through")
+	}
+	return nil
+}
+
+func columnChoices[T any](name string, label string, columns []export.Column[T]) HTML {
+	boxes := make([]HTML, len(columns))
+	for i, col := range columns {
+		id := name + "-" + col.Key
+		boxes[i] = Div(Attr(a.Class("form-check form-check-inline")),
+			Input(Attr(a.Type("checkbox"), a.Class("form-check-input"), a.Name(name), a.Value(col.Key), a.Id(id))),
+			Label(Attr(a.Class("form-check-label"), a.For(id)), Text(col.Header)),
+		)
+	}
+	return Div(Attr(a.Class("form-group")), Label_(Text(label)), Div_(boxes...))
+}
+
+func dateInputDiv(class string, name string, label string, value string) HTML {
+	return Div(Attr(a.Class("form-group "+class)),
+		Label(Attr(a.For(name)), Text(label)),
+		Input(Attr(a.Type("date"), a.Class("form-control"), a.Name(name), a.Id(name), a.Value(value))),
+	)
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"foodbank/internal/model"
 	"strconv"
 	"time"
 
@@ -17,11 +18,21 @@ type FormBuilder struct {
 }
 
 func (f *FormBuilder) InputDiv(class string, name string, label string) HTML {
+	return f.TypedInputDiv(class, "text", name, label)
+}
+
+// TypedInputDiv is InputDiv for an input of another type, such as "number"
+// or "date".
+func (f *FormBuilder) TypedInputDiv(class string, inputType string, name string, label string) HTML {
 	inputClass, errorEl := f.GetFormClassAndValidationElem(name)
 	val := f.C.FormValue(name)
+	attrs := []a.Attribute{a.Type(inputType), a.Class(inputClass), a.Name(name), a.Id(name), a.Value(val)}
+	if inputType == "number" {
+		attrs = append(attrs, a.Step("any"))
+	}
 	return Div(Attr(a.Class("form-group "+class)),
 		Label(Attr(a.For(name)), Text(label)),
-		Input(Attr(a.Type("text"), a.Class(inputClass), a.Name(name), a.Id(name), a.Value(val))),
+		Input(attrs),
 		errorEl,
 	)
 }
@@ -61,6 +72,24 @@ func (f *FormBuilder) selectOptions(name string, vals []ValueLabel) []HTML {
 
 type ValidationErrors map[string]string
 
+// FormErrors converts model validation errors to form errors keyed by field,
+// keeping the first error reported for each field.
+func FormErrors(errs model.ValidationErrors, rb *ResourceBundle) ValidationErrors {
+	out := ValidationErrors{}
+	for _, e := range errs {
+		if _, exists := out[e.Field]; !exists {
+			out[e.Field] = ValidationMessage(e, rb)
+		}
+	}
+	return out
+}
+
+// ValidationMessage returns the text for a model validation error, from the
+// "validation.<message>" key.
+func ValidationMessage(e model.ValidationError, rb *ResourceBundle) string {
+	return rb.Get("validation." + e.Message)
+}
+
 type ValueLabel struct {
 	Value string
 	Label string
@@ -3,7 +3,9 @@ package ui
 import (
 	"fmt"
 	"foodbank/internal/db"
+	"foodbank/internal/model"
 	"net/http"
+	"time"
 
 	. "github.com/julvo/htmlgo"
 	a "github.com/julvo/htmlgo/attributes"
@@ -16,12 +18,17 @@ type HouseholdListPage struct {
 
 func (p *HouseholdListPage) GET(c echo.Context) error {
 	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
 
+	var notice HTML
 	deleteID := c.QueryParam("delete")
 	if deleteID != "" {
 		if err := p.DB.DeleteHousehold(ctx, deleteID); err != nil {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		}
+		notice = Div(Attr(a.Class("alert alert-info")),
+			Text(rb.Get("households.deleted")+" "),
+			A(Attr(a.Href("/households/deleted")), Text(rb.Get("households.recentlydeleted"))))
 	}
 
 	households, _, err := p.DB.GetHouseholds(ctx, 50, "")
@@ -32,41 +39,34 @@ func (p *HouseholdListPage) GET(c echo.Context) error {
 	for i, h := range households {
 		if h.Id != "" {
 			rows[i] = Tr_(
-				Td_(HTML(h.Created())),
-				Td_(HTML(h.Head.LastName)),
-				Td_(HTML(h.Head.FirstName)),
-				Td_(HTML(FormatDOB(h.Head.DOB))),
-				Td_(A(Attr(a.Href(fmt.Sprintf("/household/%s", h.Id))), HTML("view"))),
+				Td_(Text(h.Created())),
+				Td_(Text(h.Head.FirstName)),
+				Td_(Text(h.Head.LastName), duplicateBadge(h, rb)),
+				Td_(Text(rb.FormatDOB(h.Head.DOB))),
+				Td_(A(Attr(a.Href(fmt.Sprintf("/household/%s", h.Id))), Text(rb.Get("misc.view")))),
 				Td_(A(Attr(a.Href(fmt.Sprintf("/households?delete=%s", h.Id)),
-					a.Onclick("{.}", "return confirm('Are you sure you want to delete this household?')"),
-				), HTML("delete"))),
+					confirmClick(rb.Get("households.confirmdelete")),
+				), Text(rb.Get("misc.delete")))),
 			)
 		}
 	}
 
-	page := Html5_(
-		Head_(
-			Meta(Attr(a.Charset("UTF-8"))),
-			Meta(Attr(a.Name("viewport"), a.Content("width=device-width, initial-scale=1.0"))),
-			Title_(HTML("Households")),
-			Link(Attr(a.Rel("stylesheet"), a.Href("https://maxcdn.bootstrapcdn.com/bootstrap/4.5.2/css/bootstrap.min.css"))),
-		),
-		Body_(
-			FontScalingStyle("1.1rem"),
-			Div(Attr(a.Class("container my-5")),
-				Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
-
-				H1_(HTML("Household Signups")),
-				Table(Attr(a.Class("table table-striped")),
-					Thead_(
-						Th_(HTML("Created")),
-						Th_(HTML("First Name")),
-						Th_(HTML("Last Name")),
-						Th_(HTML("Date of Birth")),
-						Th_(HTML("View")),
-						Th_(HTML("Delete")),
-					),
-					Tbody_(rows...)))))
+	page := StaffPage(rb, rb.Get("households.title"),
+		H1_(Text(rb.Get("households.title"))),
+		notice,
+		P_(A(Attr(a.Href("/households/deleted")), Text(rb.Get("households.recentlydeleted"))),
+			Text(" | "),
+			A(Attr(a.Href("/recertification")), Text(rb.Get("recertify.listtitle")))),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(
+				Th_(Text(rb.Get("misc.created"))),
+				Th_(Text(rb.Get("misc.firstname"))),
+				Th_(Text(rb.Get("misc.lastname"))),
+				Th_(Text(rb.Get("misc.dob"))),
+				Th_(Text(rb.Get("misc.view"))),
+				Th_(Text(rb.Get("misc.delete"))),
+			),
+			Tbody_(rows...)))
 
 	return c.HTML(http.StatusOK, string(page))
 }
@@ -77,6 +77,7 @@ type HouseholdDetailPage struct {
 
 func (p *HouseholdDetailPage) GET(c echo.Context) error {
 	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
 	id := c.Param("id")
 
 	household, err := p.DB.GetHouseholdByID(ctx, id)
@@ -86,65 +87,326 @@ func (p *HouseholdDetailPage) GET(c echo.Context) error {
 		})
 	}
 
-	page := Html5_(
-		Head_(
-			Meta(Attr(a.Charset("UTF-8"))),
-			Meta(Attr(a.Name("viewport"), a.Content("width=device-width, initial-scale=1.0"))),
-			Title_(HTML("Households")),
-			Link(Attr(a.Rel("stylesheet"), a.Href("https://maxcdn.bootstrapcdn.com/bootstrap/4.5.2/css/bootstrap.min.css"))),
+	audit, err := p.DB.GetAuditEntries(ctx, "households", id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve audit trail for household %s: %v", id, err),
+		})
+	}
+
+	forms, err := p.DB.GetIntakeForms(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve intake forms: %v", err),
+		})
+	}
+
+	guidelines, err := p.DB.GetPovertyGuidelines(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve poverty guidelines: %v", err),
+		})
+	}
+
+	history, err := p.DB.GetHouseholdHistory(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve history of household %s: %v", id, err),
+		})
+	}
+
+	bookings, err := p.DB.GetHouseholdBookings(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve bookings of household %s: %v", id, err),
+		})
+	}
+
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve sites: %v", err),
+		})
+	}
+
+	head := household.Head
+	page := StaffPage(rb, rb.Get("households.title"),
+		P_(A(Attr(a.Class("btn btn-outline-secondary"), a.Href(fmt.Sprintf("/household/%s/print", household.Id))),
+			Text(rb.Getf("household.print", Args{"language": HouseholdBundle(*household).Get("meta.name")}))),
+			Text(" "),
+			A(Attr(a.Class("btn btn-primary"), a.Href(fmt.Sprintf("/checkin/%s", household.Id))), Text(rb.Get("checkin.checkin"))),
+			Text(" "),
+			A(Attr(a.Class("btn btn-outline-primary"), a.Href(fmt.Sprintf("/household/%s/recertify", household.Id))), Text(rb.Get("recertify.recertify"))),
+			Text(" "),
+			A(Attr(a.Class("btn btn-outline-primary"), a.Href(fmt.Sprintf("/household/%s/book", household.Id))), Text(rb.Get("booking.title")))),
+		duplicateNotice(*household, rb),
+		// Household head details
+		H2_(Text(rb.Get("signup.hoh"))),
+		Table(Attr(a.Class("table table-bordered")),
+			Tbody_(
+				Tr_(Td_(Text(rb.Get("misc.datecreated"))), Td_(Text(household.Created()))),
+				Tr_(Td_(Text(rb.Get("misc.firstname"))), Td_(Text(head.FirstName))),
+				Tr_(Td_(Text(rb.Get("misc.lastname"))), Td_(Text(head.LastName))),
+				Tr_(Td_(Text(rb.Get("misc.dob"))), Td_(Text(rb.FormatDOB(head.DOB)))),
+				Tr_(Td_(Text(rb.Get("misc.gender"))), Td_(Text(optionLabel(genderOptions(rb), head.Gender)))),
+				Tr_(Td_(Text(rb.Get("misc.race"))), Td_(Text(optionLabel(raceOptions(rb), head.Race)))),
+				Tr_(Td_(Text(rb.Get("misc.primarylang"))), Td_(Text(optionLabel(languageOptions(rb), head.Language)))),
+				Tr_(Td_(Text(rb.Get("misc.email"))), Td_(Text(head.Email))),
+				Tr_(Td_(Text(rb.Get("misc.phone"))), Td_(Text(head.Phone))),
+				Tr_(Td_(Text(rb.Get("misc.address"))), Td_(Text(fmt.Sprintf("%s, %s, %s %s",
+					head.Street, head.City, head.State, head.PostalCode)))),
+				Tr_(Td_(Text(rb.Get("signup.income"))), Td_(Text(incomeText(*household, rb)))),
+				Tr_(Td_(Text(rb.Get("eligibility.title"))), Td_(eligibilityStatus(household.Eligibility(guidelines, time.Now()), rb))),
+				Tr_(Td_(Text(rb.Get("recertify.certified"))), Td_(certificationStatus(*household, rb))),
+			),
 		),
-		Body_(
-			FontScalingStyle("1.1rem"),
-			Style_(Text(``)),
-			Div(Attr(a.Class("container my-5")),
-				Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
-
-				// Household head details
-				H2_(HTML("Head of Household")),
-				Table(Attr(a.Class("table table-bordered")),
-					Tbody_(
-						Tr_(Td_(HTML("Date Created")), Td_(HTML(household.Created()))),
-						Tr_(Td_(HTML("First Name")), Td_(HTML(household.Head.FirstName))),
-						Tr_(Td_(HTML("Last Name")), Td_(HTML(household.Head.LastName))),
-						Tr_(Td_(HTML("Date of Birth")), Td_(HTML(FormatDOB(household.Head.DOB)))),
-						Tr_(Td_(HTML("Gender")), Td_(HTML(household.Head.Gender))),
-						Tr_(Td_(HTML("Race")), Td_(HTML(household.Head.Race))),
-						Tr_(Td_(HTML("Language")), Td_(HTML(household.Head.Language))),
-						Tr_(Td_(HTML("Email")), Td_(HTML(household.Head.Email))),
-						Tr_(Td_(HTML("Phone")), Td_(HTML(household.Head.Phone))),
-						Tr_(Td_(HTML("Address")), Td_(HTML(fmt.Sprintf("%s, %s, %s %s",
-							household.Head.Street, household.Head.City, household.Head.State, household.Head.PostalCode)))),
-					),
-				),
-				// Household members details
-				H2_(HTML("Other Household Members")),
-				Table(Attr(a.Class("table table-striped")),
-					Thead_(
-						Tr_(
-							Th_(HTML("First Name")),
-							Th_(HTML("Last Name")),
-							Th_(HTML("Date of Birth")),
-							Th_(HTML("Relationship")),
-							Th_(HTML("Gender")),
-							Th_(HTML("Race")),
-						),
-					),
-					Tbody_(func() []HTML {
-						rows := make([]HTML, len(household.Members))
-						for i, member := range household.Members {
-							rows[i] = Tr_(
-								Td_(HTML(member.FirstName)),
-								Td_(HTML(member.LastName)),
-								Td_(HTML(FormatDOB(member.DOB))),
-								Td_(HTML(member.Relationship)),
-								Td_(HTML(member.Gender)),
-								Td_(HTML(member.Race)),
-							)
-						}
-						return rows
-					}()...),
+		// Household members details
+		H2_(Text(rb.Get("household.othermembers"))),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(
+				Tr_(
+					Th_(Text(rb.Get("misc.firstname"))),
+					Th_(Text(rb.Get("misc.lastname"))),
+					Th_(Text(rb.Get("misc.dob"))),
+					Th_(Text(rb.Get("misc.relationship"))),
+					Th_(Text(rb.Get("misc.gender"))),
+					Th_(Text(rb.Get("misc.race"))),
 				),
-			)))
+			),
+			Tbody_(func() []HTML {
+				rows := make([]HTML, len(household.Members))
+				for i, member := range household.Members {
+					rows[i] = Tr_(
+						Td_(Text(member.FirstName)),
+						Td_(Text(member.LastName)),
+						Td_(Text(rb.FormatDOB(member.DOB))),
+						Td_(Text(optionLabel(relationshipOptions(rb), member.Relationship))),
+						Td_(Text(optionLabel(genderOptions(rb), member.Gender))),
+						Td_(Text(optionLabel(raceOptions(rb), member.Race))),
+					)
+				}
+				return rows
+			}()...),
+		),
+		intakeAnswersTable(*household, forms, rb),
+		H2_(Text(rb.Get("signature.title"))),
+		signatureBlock(household.Signature, rb),
+		H2_(Text(rb.Get("booking.pickups"))),
+		bookingsTable(bookings, siteNames(sites), "/household/"+household.Id, rb),
+		H2_(Text(rb.Get("recertify.history"))),
+		historyTable(history, rb),
+		H2_(Text(rb.Get("audit.title"))),
+		auditTable(audit, rb),
+		eraseForm(*household, rb),
+	)
 
 	return c.HTML(http.StatusOK, string(page))
 }
+
+// duplicateBadge marks a household in the list that staff should review.
+func duplicateBadge(h model.Household, rb *ResourceBundle) HTML {
+	if h.DuplicateOf == "" {
+		return ""
+	}
+	return Span(Attr(a.Class("badge badge-warning ml-2")), Text(rb.Get("households.review")))
+}
+
+// duplicateNotice asks staff to check a household that signed up with
+// another household's phone number, and to clear it if it is not a
+// duplicate.
+func duplicateNotice(h model.Household, rb *ResourceBundle) HTML {
+	if h.DuplicateOf == "" {
+		return ""
+	}
+	return Div(Attr(a.Class("alert alert-warning")),
+		P_(Text(rb.Get("household.duplicate"))),
+		A(Attr(a.Class("btn btn-outline-secondary mr-2"), a.Href("/household/"+h.DuplicateOf)), Text(rb.Get("household.viewother"))),
+		Form(Attr(a.Class("d-inline"), a.Action(fmt.Sprintf("/household/%s/reviewed", h.Id)), a.Method("POST")),
+			Button(Attr(a.Class("btn btn-outline-primary"), a.Type("submit")), Text(rb.Get("household.notduplicate")))),
+	)
+}
+
+// Reviewed clears a household's possible duplicate once staff have found it
+// is a different household.
+func (p *HouseholdDetailPage) Reviewed(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	household, err := p.DB.GetHouseholdByID(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve household with id %s: %v", id, err),
+		})
+	}
+	household.DuplicateOf = ""
+	if err := p.DB.PutHousehold(ctx, *household); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to save household with id %s: %v", id, err),
+		})
+	}
+	return c.Redirect(http.StatusSeeOther, "/household/"+id)
+}
+
+// Print renders a sheet for staff to print and hand to the client, in the
+// household's primary language unless a lang parameter asks for another.
+func (p *HouseholdDetailPage) Print(c echo.Context) error {
+	id := c.Param("id")
+	household, err := p.DB.GetHouseholdByID(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to retrieve household with id %s: %v", id, err),
+		})
+	}
+
+	rb := HouseholdBundle(*household)
+	if lang := c.QueryParam("lang"); lang != "" {
+		rb = Bundle(lang)
+	}
+	head := household.Head
+
+	rows := make([]HTML, len(household.Members))
+	for i, member := range household.Members {
+		rows[i] = Tr_(
+			Td_(Text(member.FirstName+" "+member.LastName)),
+			Td_(Text(rb.FormatDOB(member.DOB))),
+			Td_(Text(optionLabel(relationshipOptions(rb), member.Relationship))),
+		)
+	}
+
+	page := StaffPage(rb, rb.Get("print.title"),
+		Style_(Text("@media print { .d-print-none { display: none; } }")),
+		H1_(Text(rb.Get("print.title"))),
+		P_(Text(rb.Getf("print.printed", Args{"date": rb.FormatDate(time.Now())}))),
+		Table(Attr(a.Class("table table-bordered")),
+			Tbody_(
+				Tr_(Td_(Text(rb.Get("signup.hoh"))), Td_(Text(head.FirstName+" "+head.LastName))),
+				Tr_(Td_(Text(rb.Get("misc.dob"))), Td_(Text(rb.FormatDOB(head.DOB)))),
+				Tr_(Td_(Text(rb.Get("misc.phone"))), Td_(Text(head.Phone))),
+				Tr_(Td_(Text(rb.Get("misc.address"))), Td_(Text(fmt.Sprintf("%s, %s, %s %s",
+					head.Street, head.City, head.State, head.PostalCode)))),
+				Tr_(Td_(Text(rb.Get("reports.householdsize"))), Td_(Text(household.Size()))),
+			),
+		),
+		H2_(Text(rb.Get("signup.othermembers"))),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(Th_(Text(rb.Get("misc.name"))), Th_(Text(rb.Get("misc.dob"))), Th_(Text(rb.Get("misc.relationship"))))),
+			Tbody_(rows...)),
+		P_(Text(rb.Get("print.check"))),
+		H2_(Text(rb.Get("signature.title"))),
+		signatureBlock(household.Signature, rb),
+		Button(Attr(a.Class("btn btn-primary d-print-none"), a.Type("button"), a.Onclick(nil, "window.print()")),
+			Text(rb.Get("print.print"))),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// auditTable lists audit entries with each changed field on its own line.
+func auditTable(entries []model.AuditEntry, rb *ResourceBundle) HTML {
+	rows := make([]HTML, len(entries))
+	for i, e := range entries {
+		changes := make([]HTML, len(e.Changes))
+		for j, change := range e.Changes {
+			if e.Redacted {
+				changes[j] = Div_(Code_(Text(change.Field)))
+			} else {
+				changes[j] = Div_(Code_(Text(change.Field)), Text(": "),
+					Del_(Text(auditValue(change.Before))), Text(" → "), Text(auditValue(change.After)))
+			}
+		}
+		if e.Redacted {
+			changes = append(changes, Em_(Text(rb.Get("audit.erased"))))
+		}
+		rows[i] = Tr_(
+			Td_(Text(model.FormatTimestamp(e.At))),
+			Td_(Text(e.Actor)),
+			Td_(Text(rb.Get("audit.action."+e.Action))),
+			Td(Attr(a.Class("small")), changes...),
+		)
+	}
+	return Table(Attr(a.Class("table table-sm")),
+		Thead_(Tr_(Th_(Text(rb.Get("audit.when"))), Th_(Text(rb.Get("audit.who"))), Th_(Text(rb.Get("audit.action"))), Th_(Text(rb.Get("audit.changes"))))),
+		Tbody_(rows...))
+}
+
+// auditValue shortens long values, such as drawn signatures, for the table.
+func auditValue(v string) string {
+	const max = 60
+	if r := []rune(v); len(r) > max {
+		return string(r[:max]) + "…"
+	}
+	return v
+}
+
+// Erase removes the household's personal data at the client's request. The
+// household is anonymized rather than deleted so its visits still count in
+// reports.
+func (p *HouseholdDetailPage) Erase(c echo.Context) error {
+	id := c.Param("id")
+	if err := p.DB.AnonymizeHousehold(c.Request().Context(), id, model.AuditErase); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to erase household with id %s: %v", id, err),
+		})
+	}
+	return c.Redirect(http.StatusSeeOther, "/household/"+id)
+}
+
+func eraseForm(household model.Household, rb *ResourceBundle) HTML {
+	if household.Anonymized {
+		return P(Attr(a.Class("text-muted")), Text(rb.Get("household.erased")))
+	}
+	return Form(Attr(a.Action(fmt.Sprintf("/household/%s/erase", household.Id)), a.Method("POST")),
+		Button(Attr(a.Class("btn btn-outline-danger"), a.Type("submit"),
+			confirmClick(rb.Get("household.confirmerase")),
+		), Text(rb.Get("household.erase"))))
+}
+
+// DeletedHouseholdsPage lists recently deleted households so they can be
+// restored before they are purged.
+type DeletedHouseholdsPage struct {
+	DB         *db.FirestoreDB
+	PurgeAfter time.Duration
+}
+
+func (p *DeletedHouseholdsPage) GET(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	households, err := p.DB.GetDeletedHouseholds(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	rows := make([]HTML, len(households))
+	for i, h := range households {
+		rows[i] = Tr_(
+			Td_(Text(model.FormatTimestamp(h.DeletedAt))),
+			Td_(Text(h.DeletedBy)),
+			Td_(Text(h.Head.LastName)),
+			Td_(Text(h.Head.FirstName)),
+			Td_(Text(rb.FormatDOB(h.Head.DOB))),
+			Td_(Text(model.FormatTimestamp(h.DeletedAt.Add(p.PurgeAfter)))),
+			Td_(Form(Attr(a.Action(fmt.Sprintf("/households/deleted/%s/restore", h.Id)), a.Method("POST")),
+				Button(Attr(a.Class("btn btn-sm btn-outline-primary"), a.Type("submit")), Text(rb.Get("deleted.restore"))))),
+		)
+	}
+
+	page := StaffPage(rb, rb.Get("deleted.title"),
+		H1_(Text(rb.Get("deleted.title"))),
+		P_(Text(rb.Getf("deleted.intro", Args{"days": int(p.PurgeAfter.Hours() / 24)}))),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("deleted.deleted"))), Th_(Text(rb.Get("deleted.deletedby"))), Th_(Text(rb.Get("misc.lastname"))),
+				Th_(Text(rb.Get("misc.firstname"))), Th_(Text(rb.Get("misc.dob"))), Th_(Text(rb.Get("deleted.purgeafter"))), Th_(),
+			)),
+			Tbody_(rows...)),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// Restore moves a deleted household back to the household list.
+func (p *DeletedHouseholdsPage) Restore(c echo.Context) error {
+	id := c.Param("id")
+	if err := p.DB.RestoreHousehold(c.Request().Context(), id); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Failed to restore household with id %s: %v", id, err),
+		})
+	}
+	return c.Redirect(http.StatusSeeOther, "/household/"+id)
+}
@@ -1,124 +1,289 @@
 package ui
 
 import (
+	"embed"
+	"encoding/json"
 	"fmt"
+	"foodbank/internal/model"
+	"foodbank/internal/msgformat"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	a "github.com/julvo/htmlgo/attributes"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
 )
 
+// Translations live in locales/<lang>.json, one flat object of key to
+// message per language, written in the msgformat syntax. Every language must
+// have the same keys as en.json; the tests check this. A regional locale
+// such as es-MX.json holds only the messages that differ from its language.
+// The "meta.name" key holds the language's own name for the language
+// switcher and "meta.dir" is "rtl" for languages written right to left.
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var resources = loadResources()
+
+// Languages lists the available locales in the order they are offered to
+// clients, with English first.
+var Languages = languages()
+
+func loadResources() map[string]map[string]string {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	out := map[string]map[string]string{}
+	for _, f := range files {
+		data, err := localeFS.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var res map[string]string
+		if err := json.Unmarshal(data, &res); err != nil {
+			panic(fmt.Sprintf("locales/%s: %v", f.Name(), err))
+		}
+		out[strings.TrimSuffix(f.Name(), ".json")] = res
+	}
+	return out
+}
+
+func languages() []string {
+	var langs []string
+	for lang := range resources {
+		// regional locales such as es-MX only override some messages, and
+		// are not offered separately
+		if lang != "en" && !strings.Contains(lang, "-") {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return append([]string{"en"}, langs...)
+}
+
+// langCookie remembers the language chosen with a lang parameter.
+const langCookie = "lang"
+
+// GetResourceBundle picks the request's language from, in order, a lang query
+// or form parameter, the language cookie and the Accept-Language header. A
+// lang parameter is saved in the cookie so later pages use it too.
 func GetResourceBundle(c echo.Context) *ResourceBundle {
+	if rb, ok := c.Get(langCookie).(*ResourceBundle); ok {
+		return rb
+	}
+
 	lang := c.QueryParam("lang")
 	if lang == "" {
 		lang = c.FormValue("lang")
 	}
+	if available(lang) {
+		if cookie, err := c.Cookie(langCookie); err != nil || cookie.Value != lang {
+			c.SetCookie(&http.Cookie{
+				Name:     langCookie,
+				Value:    lang,
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+	} else if cookie, err := c.Cookie(langCookie); err == nil && available(cookie.Value) {
+		lang = cookie.Value
+	} else {
+		lang = negotiate(c.Request().Header.Get("Accept-Language"))
+	}
 
-	res, ok := resources[lang]
+	rb := Bundle(lang)
+	c.Set(langCookie, rb)
+	return rb
+}
+
+// Bundle returns the resources for a locale such as "es-MX". Messages are
+// looked up in the locale, then its language, then English, so es-MX falls
+// back to es and then en.
+func Bundle(locale string) *ResourceBundle {
+	rb := &ResourceBundle{}
+	tag, base := canonical(locale)
+	for _, lang := range []string{tag, base, "en"} {
+		if res, ok := resources[lang]; ok && !slices.Contains(rb.langs, lang) {
+			rb.langs = append(rb.langs, lang)
+			rb.chain = append(rb.chain, res)
+		}
+	}
+	rb.Lang = rb.langs[0]
+	return rb
+}
+
+// canonical returns a locale in the form used by the locale files, such as
+// "es-MX", and its language, "es".
+func canonical(locale string) (string, string) {
+	lang, region, ok := strings.Cut(strings.TrimSpace(locale), "-")
+	lang = strings.ToLower(lang)
 	if !ok {
-		lang = "en"
-		res = resources[lang]
-	}
-
-	return &ResourceBundle{Lang: lang, Resources: res}
-}
-
-var resources = map[string]map[string]string{
-	"en": {
-		"signup.title":        "Community Cupboard Sign-Up Form",
-		"signup.intro":        `This information is helpful in providing our services. None of your information will be shared.`,
-		"signup.success":      `We have saved your information. Please ask for a shopping sheet from a staff member.`,
-		"signup.hoh":          "Head of Household",
-		"signup.othermembers": "Others Living in the Household",
-		"misc.firstname":      "First Name",
-		"misc.lastname":       "Last Name",
-		"misc.address":        "Address",
-		"misc.city":           "City",
-		"misc.zipcode":        "ZIP Code",
-		"misc.email":          "Email",
-		"misc.phone":          "Phone",
-		"misc.gender":         "Gender",
-		"misc.male":           "Male",
-		"misc.female":         "Female",
-		"misc.prefernottosay": "Prefer not to say",
-		"misc.dob":            "Date of Birth",
-		"misc.month":          "Month",
-		"misc.day":            "Day",
-		"misc.year":           "Year",
-		"misc.primarylang":    "Primary Language",
-		"misc.english":        "English",
-		"misc.spanish":        "Spanish",
-		"misc.other":          "Other",
-		"misc.relationship":   "Relationship",
-		"misc.child":          "Child",
-		"misc.grandchild":     "Grandchild",
-		"misc.spouse":         "Spouse",
-		"misc.parent":         "Parent",
-		"misc.person":         "Person",
-		"misc.grandparent":    "Grandparent",
-		"misc.sibling":        "Sibling",
-		"misc.friend":         "Friend",
-		"misc.race":           "Race",
-		"misc.race.white":     "White/Anglo",
-		"misc.race.latino":    "Latina/Latino",
-		"misc.race.black":     "Black/Afr. American",
-		"misc.race.asian":     "Asian/Pacific Islander",
-		"misc.submit":         "Submit",
-		"misc.thankyou":       "Thank You",
-		"misc.fieldrequired":  "This field is required",
-	},
-	"es": {
-		"signup.title":        "Formulario de Registro de Community Cupboard",
-		"signup.intro":        `Esta información es útil para proporcionar nuestros servicios. Ninguna de su información será compartida.`,
-		"signup.success":      `Hemos guardado su información. Por favor, solicite una hoja de compras a un miembro del personal.`,
-		"signup.hoh":          "Cabeza de Familia",
-		"signup.othermembers": "Otras Personas en el Hogar",
-		"misc.firstname":      "Nombre",
-		"misc.lastname":       "Apellido",
-		"misc.address":        "Dirección",
-		"misc.city":           "Ciudad",
-		"misc.zipcode":        "Código Postal",
-		"misc.email":          "Correo Electrónico",
-		"misc.phone":          "Teléfono",
-		"misc.gender":         "Género",
-		"misc.male":           "Hombre",
-		"misc.female":         "Mujer",
-		"misc.person":         "Persona",
-		"misc.prefernottosay": "Prefiere no decir",
-		"misc.dob":            "Fecha de Nacimiento",
-		"misc.month":          "Mes",
-		"misc.day":            "Día",
-		"misc.year":           "Año",
-		"misc.primarylang":    "Idioma Principal",
-		"misc.english":        "Inglés",
-		"misc.spanish":        "Español",
-		"misc.other":          "Otro",
-		"misc.relationship":   "Relación",
-		"misc.child":          "Hijo/a",
-		"misc.grandchild":     "Nieto/a",
-		"misc.spouse":         "Esposo/a",
-		"misc.parent":         "Padre/Madre",
-		"misc.grandparent":    "Abuelo/a",
-		"misc.sibling":        "Hermano/a",
-		"misc.friend":         "Amigo/a",
-		"misc.race":           "Raza",
-		"misc.race.white":     "Blanco/Anglo",
-		"misc.race.latino":    "Latino/Latina",
-		"misc.race.black":     "Negro/Afroamericano",
-		"misc.race.asian":     "Asiático/Isleño del Pacífico",
-		"misc.submit":         "Enviar",
-		"misc.thankyou":       "Gracias",
-		"misc.fieldrequired":  "Este campo es obligatorio",
-	},
+		return lang, lang
+	}
+	return lang + "-" + strings.ToUpper(region), lang
+}
+
+// available reports whether locale or its language has a locale file.
+func available(locale string) bool {
+	tag, base := canonical(locale)
+	return resources[tag] != nil || resources[base] != nil
+}
+
+// HouseholdBundle returns the resources for the household's primary
+// language, for text meant for the client rather than staff.
+func HouseholdBundle(h model.Household) *ResourceBundle {
+	return Bundle(LocaleFor(h.Head.Language))
+}
+
+// languageLocales maps the primary language values stored on a person to
+// locales.
+var languageLocales = map[string]string{
+	"english":    "en",
+	"spanish":    "es",
+	"vietnamese": "vi",
+	"arabic":     "ar",
+	"french":     "fr",
+	"nepali":     "ne",
+	"swahili":    "sw",
+}
+
+// LocaleFor returns the locale for a stored primary language, which may be
+// one of the signup form's values, an imported name such as "Spanish" or a
+// locale code. Unknown languages get English.
+func LocaleFor(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if locale, ok := languageLocales[language]; ok {
+		return locale
+	}
+	if _, ok := resources[language]; ok {
+		return language
+	}
+	return "en"
+}
+
+// negotiate returns the available locale the Accept-Language header prefers
+// most, or English. A tag matches if its locale or its language is available.
+func negotiate(header string) string {
+	best, bestQ := "en", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if available(tag) && q > bestQ {
+			best, _ = canonical(tag)
+			bestQ = q
+		}
+	}
+	return best
 }
 
 type ResourceBundle struct {
-	Lang      string
-	Resources map[string]string
+	Lang  string
+	langs []string
+	chain []map[string]string
 }
 
+// Args holds the named arguments of a message.
+type Args = msgformat.Args
+
+// reported remembers missing messages that have been logged.
+var reported sync.Map
+
+// Get returns the message for key from the first locale in the fallback
+// chain that has it. A key missing from every locale is logged, once, and
+// shown as nothing.
 func (r *ResourceBundle) Get(key string) string {
-	v, ok := r.Resources[key]
-	if !ok {
-		return fmt.Sprintf("resource not found. lang=%s key=%s", r.Lang, key)
+	if v, ok := r.lookup(key); ok {
+		return v
+	}
+	if _, logged := reported.LoadOrStore(r.Lang+" "+key, true); !logged {
+		log.Warn().Str("lang", r.Lang).Str("key", key).Msg("Missing translation")
 	}
-	return v
+	return ""
+}
+
+// lookup returns the message for key like Get, but reports a missing key to
+// the caller instead of logging it.
+func (r *ResourceBundle) lookup(key string) (string, bool) {
+	for _, res := range r.chain {
+		if v, ok := res[key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Dir returns the text direction of the language, "ltr" or "rtl".
+func (r *ResourceBundle) Dir() string {
+	return r.Get("meta.dir")
+}
+
+// Getf formats the message for key with named arguments. See package
+// msgformat for the message syntax.
+func (r *ResourceBundle) Getf(key string, args Args) string {
+	msg := r.Get(key)
+	out, err := msgformat.Format(r.Lang, msg, args)
+	if err != nil {
+		log.Error().Err(err).Str("lang", r.Lang).Str("key", key).Msg("Failed to format message")
+		return msg
+	}
+	return out
+}
+
+// FormatDate formats t's date in the food bank's time zone as the
+// language writes dates, such as "January 2, 2006" or "2 de enero de 2006".
+func (r *ResourceBundle) FormatDate(t time.Time) string {
+	t = t.In(model.Location())
+	return r.formatDate(t.Year(), t.Month(), t.Day())
+}
+
+// FormatDOB formats a YYYY-MM-DD date of birth, or returns it unchanged if
+// it is not a valid date.
+func (r *ResourceBundle) FormatDOB(dob string) string {
+	t, err := time.Parse("2006-01-02", dob)
+	if err != nil {
+		return dob
+	}
+	return r.formatDate(t.Year(), t.Month(), t.Day())
+}
+
+// FormatNumber formats an integer or float with the language's digit
+// grouping and decimal separators.
+func (r *ResourceBundle) FormatNumber(v any) string {
+	return msgformat.FormatNumber(r.Lang, v)
+}
+
+func (r *ResourceBundle) formatDate(year int, month time.Month, day int) string {
+	return r.Getf("date.format", Args{
+		"day":   strconv.Itoa(day),
+		"month": r.Get("date.month." + strconv.Itoa(int(month))),
+		"year":  strconv.Itoa(year),
+	})
+}
+
+// confirmClick asks the user to confirm msg before following a link. msg is
+// passed as data so quotes in translations are escaped.
+func confirmClick(msg string) a.Attribute {
+	return a.Onclick(msg, "return confirm({{.}})")
+}
+
+// confirmSubmit is confirmClick for forms.
+func confirmSubmit(msg string) a.Attribute {
+	return a.Onsubmit(msg, "return confirm({{.}})")
 }
@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"foodbank/internal/model"
+	"foodbank/internal/msgformat"
+	"foodbank/internal/report"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestLocalesHaveAllKeys(t *testing.T) {
+	en := resources["en"]
+	for lang, res := range resources {
+		// regional locales only hold the messages that differ
+		if !strings.Contains(lang, "-") {
+			for key := range en {
+				if _, ok := res[key]; !ok {
+					t.Errorf("%s: missing key %q", lang, key)
+				}
+			}
+		}
+		for key, translated := range res {
+			msg, ok := en[key]
+			if !ok {
+				t.Errorf("%s: key %q is not in en", lang, key)
+				continue
+			}
+			if strings.TrimSpace(translated) == "" {
+				t.Errorf("%s: empty message for %q", lang, key)
+			}
+			want, err := msgformat.Params(msg)
+			if err != nil {
+				t.Errorf("en: %q: %v", key, err)
+			}
+			got, err := msgformat.Params(translated)
+			if err != nil {
+				t.Errorf("%s: %q: %v", lang, key, err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("%s: %q has arguments %v, want %v", lang, key, got, want)
+			}
+		}
+	}
+}
+
+var keyLiteral = regexp.MustCompile(`\.Getf?\("([a-z0-9._]+)"`)
+
+// TestKeysUsedExist checks that every key the pages look up is in en.json,
+// including keys built from stored values.
+func TestKeysUsedExist(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range keyLiteral.FindAllStringSubmatch(string(src), -1) {
+			// keys ending in "." are prefixes, checked below
+			if !strings.HasSuffix(m[1], ".") {
+				keys = append(keys, m[1])
+			}
+		}
+	}
+	for _, action := range []string{model.AuditCreate, model.AuditUpdate, model.AuditDelete, model.AuditRestore,
+		model.AuditPurge, model.AuditAnonymize, model.AuditErase, model.AuditRecertify} {
+		keys = append(keys, "audit.action."+action)
+	}
+	for report := range model.ReportNames {
+		keys = append(keys, "schedules.report."+report)
+	}
+	for _, status := range []string{model.Eligible, model.Ineligible, model.EligibilityUnknown} {
+		keys = append(keys, "eligibility."+status)
+	}
+	keys = append(keys, "attestation."+model.AttestationVersion)
+	for _, status := range []string{model.CertificationCurrent, model.CertificationDue, model.CertificationOverdue} {
+		keys = append(keys, "recertify.status."+status)
+	}
+	for _, status := range model.BookingStatuses {
+		keys = append(keys, "booking.status."+status)
+	}
+	for _, status := range []string{model.QueueWaiting, model.QueueServing, model.QueueServed, model.QueueLeft} {
+		keys = append(keys, "queue.status."+status)
+	}
+	for _, bucket := range []string{report.BucketUnknown, report.BucketOther, report.BucketNew, report.BucketReturning} {
+		keys = append(keys, "reports.bucket."+bucket)
+	}
+	keys = append(keys, "schedules.enable", "schedules.disable", "retention.done.anonymize", "retention.done.delete")
+	for _, msg := range []string{"field_missing", "invalid_date", "future_date", "invalid_email", "invalid_phone",
+		"invalid_postal_code", "invalid_report", "invalid_format", "invalid_hour", "invalid_scope", "invalid_resource",
+		"invalid_number", "invalid_option", "invalid_key", "duplicate_key", "invalid_type", "invalid_year", "invalid_signature",
+		"invalid_time", "end_before_start", "invalid_slot", "past_date", "already_booked", "slot_full"} {
+		keys = append(keys, "validation."+msg)
+	}
+	for month := 1; month <= 12; month++ {
+		keys = append(keys, fmt.Sprintf("date.month.%d", month))
+	}
+	for weekday := 0; weekday < 7; weekday++ {
+		keys = append(keys, fmt.Sprintf("date.weekday.%d", weekday))
+	}
+
+	for _, key := range keys {
+		if _, ok := resources["en"][key]; !ok {
+			t.Errorf("key %q is used but not in en.json", key)
+		}
+	}
+}
+
+func TestLanguagesEnglishFirst(t *testing.T) {
+	if len(Languages) < 7 || Languages[0] != "en" {
+		t.Errorf("Languages = %v", Languages)
+	}
+	for _, lang := range Languages {
+		if resources[lang]["meta.name"] == "" {
+			t.Errorf("%s has no meta.name", lang)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"es-mx,es;q=0.9,en;q=0.8", "es-MX"},
+		{"es;q=0.9,en;q=0.8", "es"},
+		{"de-DE,fr;q=0.5,ar;q=0.7", "ar"},
+		{"de, zh;q=0.5", "en"},
+		{"vi;q=bad, sw;q=0.2", "sw"},
+		{"en;q=0.1, ne", "ne"},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.header); got != tt.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleFor(t *testing.T) {
+	for language, want := range map[string]string{
+		"spanish": "es", "Spanish": "es", " arabic ": "ar", "vi": "vi", "other": "en", "": "en",
+	} {
+		if got := LocaleFor(language); got != want {
+			t.Errorf("LocaleFor(%q) = %q, want %q", language, got, want)
+		}
+	}
+}
+
+func TestGetResourceBundle(t *testing.T) {
+	e := echo.New()
+	get := func(target string, header string, cookie string) (*ResourceBundle, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept-Language", header)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: langCookie, Value: cookie})
+		}
+		rec := httptest.NewRecorder()
+		return GetResourceBundle(e.NewContext(req, rec)), rec
+	}
+
+	rb, rec := get("/signup?lang=fr", "es", "")
+	if rb.Lang != "fr" || !strings.Contains(rec.Header().Get("Set-Cookie"), "lang=fr") {
+		t.Errorf("lang parameter: got %s, Set-Cookie %q", rb.Lang, rec.Header().Get("Set-Cookie"))
+	}
+	if rb, rec = get("/signup", "es", "ar"); rb.Lang != "ar" || rb.Dir() != "rtl" || rec.Header().Get("Set-Cookie") != "" {
+		t.Errorf("cookie: got %s %s, Set-Cookie %q", rb.Lang, rb.Dir(), rec.Header().Get("Set-Cookie"))
+	}
+	if rb, _ = get("/signup", "es-MX,en;q=0.5", "xx"); rb.Lang != "es" {
+		t.Errorf("Accept-Language: got %s", rb.Lang)
+	}
+	if rb, _ = get("/signup?lang=xx", "", ""); rb.Lang != "en" || rb.Dir() != "ltr" {
+		t.Errorf("default: got %s %s", rb.Lang, rb.Dir())
+	}
+}
+
+func TestBundleFallback(t *testing.T) {
+	resources["es-MX"] = map[string]string{"misc.submit": "Mandar"}
+	defer delete(resources, "es-MX")
+
+	rb := Bundle("es-mx")
+	if rb.Lang != "es-MX" {
+		t.Errorf("Lang = %q", rb.Lang)
+	}
+	for key, want := range map[string]string{
+		"misc.submit":   "Mandar",
+		"misc.thankyou": resources["es"]["misc.thankyou"],
+		"no.such.key":   "",
+		"date.month.1":  "enero",
+		"meta.dir":      "ltr",
+	} {
+		if got := rb.Get(key); got != want {
+			t.Errorf("Get(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if rb := Bundle("pt-BR"); rb.Lang != "en" || rb.Get("misc.submit") != "Submit" {
+		t.Errorf("pt-BR: got %s %q", rb.Lang, rb.Get("misc.submit"))
+	}
+}
+
+func TestGetf(t *testing.T) {
+	tests := []struct {
+		lang string
+		key  string
+		args Args
+		want string
+	}{
+		{"en", "import.run", Args{"count": 1}, "Import 1 household"},
+		{"en", "import.run", Args{"count": 1200}, "Import 1,200 households"},
+		{"es", "import.run", Args{"count": 1200}, "Importar 1.200 hogares"},
+		{"fr", "retention.done.delete", Args{"count": 0}, "0 foyer supprimé."},
+		{"en", "tokens.createdby", Args{"time": "2024-01-02 10:00", "actor": "ana"}, "2024-01-02 10:00 by ana"},
+		// a missing argument shows the message rather than nothing
+		{"en", "tokens.revokedat", Args{}, "revoked {time}"},
+	}
+	for _, tt := range tests {
+		if got := Bundle(tt.lang).Getf(tt.key, tt.args); got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.lang, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	for lang, want := range map[string]string{
+		"en": "March 4, 1980",
+		"es": "4 de marzo de 1980",
+		"fr": "4 mars 1980",
+	} {
+		if got := Bundle(lang).FormatDOB("1980-03-04"); got != want {
+			t.Errorf("%s: FormatDOB = %q, want %q", lang, got, want)
+		}
+	}
+	if got := Bundle("en").FormatDOB("1980-02-31"); got != "1980-02-31" {
+		t.Errorf("invalid DOB: got %q", got)
+	}
+	noon := time.Date(2024, time.December, 25, 12, 0, 0, 0, model.Location())
+	if got := Bundle("es").FormatDate(noon); got != "25 de diciembre de 2024" {
+		t.Errorf("FormatDate = %q", got)
+	}
+}
@@ -0,0 +1,233 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/importer"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportSize caps uploaded client lists.
+const maxImportSize = 10 << 20
+
+// ImportPage imports client lists from CSV in three steps: upload and map
+// columns, review a dry-run report, then import the valid households. The file
+// is carried between steps in a hidden field so nothing is stored until the
+// final step. Every step posts multipart forms, as a URL-encoded body is
+// limited to 10 MB and the base64-encoded file is a third larger.
+type ImportPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *ImportPage) GET(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	return p.render(c,
+		P_(Text(rb.Get("import.intro"))),
+		Form(Attr(a.Action("/import"), a.Method("POST"), a.Enctype("multipart/form-data")),
+			Input(Attr(a.Type("hidden"), a.Name("step"), a.Value("map"))),
+			Div(Attr(a.Class("form-group")),
+				Input(Attr(a.Type("file"), a.Class("form-control-file"), a.Name("file"), a.Accept(".csv,text/csv"))),
+			),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("import.upload"))),
+		),
+	)
+}
+
+func (p *ImportPage) POST(c echo.Context) error {
+	switch c.FormValue("step") {
+	case "map":
+		return p.mapColumns(c)
+	case "dryrun":
+		return p.dryRun(c)
+	case "import":
+		return p.runImport(c)
+	default:
+		return c.Redirect(http.StatusSeeOther, "/import")
+	}
+}
+
+func (p *ImportPage) mapColumns(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, rb.Get("import.nofile"))
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, fmt.Sprintf("Failed to read upload: %v", err))
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxImportSize+1))
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, fmt.Sprintf("Failed to read upload: %v", err))
+	}
+	if len(data) > maxImportSize {
+		return c.HTML(http.StatusRequestEntityTooLarge, rb.Get("import.toolarge"))
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel's UTF-8 byte order mark
+
+	headers, err := csv.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, fmt.Sprintf("Failed to read CSV header: %v", err))
+	}
+
+	return p.render(c,
+		P_(Text(rb.Get("import.mapintro"))),
+		Form(Attr(a.Action("/import"), a.Method("POST"), a.Enctype("multipart/form-data")),
+			Input(Attr(a.Type("hidden"), a.Name("step"), a.Value("dryrun"))),
+			Input(Attr(a.Type("hidden"), a.Name("data"), a.Value(base64.StdEncoding.EncodeToString(data)))),
+			mappingTable(headers, importer.GuessMapping(headers), rb),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("import.dryrun"))),
+		),
+	)
+}
+
+func (p *ImportPage) dryRun(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	result, mapping, err := parseImport(c)
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, err.Error())
+	}
+
+	rows := []HTML{}
+	for _, row := range result.Rows {
+		if !row.Errors.HasErrors() {
+			continue
+		}
+		msgs := make([]string, len(row.Errors))
+		for i, e := range row.Errors {
+			msgs[i] = fmt.Sprintf("%s: %s", columnFor(result.Headers, mapping, e.Field), ValidationMessage(e, rb))
+		}
+		rows = append(rows, Tr_(
+			Td_(Text(row.Line)),
+			Td_(Text(row.Household)),
+			Td_(Text(strings.Join(msgs, "; "))),
+		))
+	}
+
+	var errorsTable HTML
+	if len(rows) > 0 {
+		errorsTable = Table(Attr(a.Class("table table-sm table-striped")),
+			Thead_(Tr_(Th_(Text(rb.Get("import.line"))), Th_(Text(rb.Get("import.household"))), Th_(Text(rb.Get("import.errors"))))),
+			Tbody_(rows...))
+	}
+
+	var importForm HTML
+	if len(result.Households) > 0 {
+		hidden := []HTML{
+			Input(Attr(a.Type("hidden"), a.Name("step"), a.Value("import"))),
+			Input(Attr(a.Type("hidden"), a.Name("data"), a.Value(c.FormValue("data")))),
+		}
+		for i, field := range mapping {
+			hidden = append(hidden, Input(Attr(a.Type("hidden"), a.Name("col"+strconv.Itoa(i)), a.Value(field))))
+		}
+		importForm = Form(Attr(a.Action("/import"), a.Method("POST"), a.Enctype("multipart/form-data")), append(hidden,
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")),
+				Text(rb.Getf("import.run", Args{"count": len(result.Households)}))))...)
+	}
+
+	return p.render(c,
+		H2_(Text(rb.Get("import.dryrun"))),
+		Ul_(
+			Li_(Text(rb.Getf("import.rowsread", Args{"count": len(result.Rows)}))),
+			Li_(Text(rb.Getf("import.rowswitherrors", Args{"count": result.ErrorCount()}))),
+			Li_(Text(rb.Getf("import.ready", Args{"count": len(result.Households)}))),
+			Li_(Text(rb.Getf("import.skipped", Args{"count": result.Skipped}))),
+		),
+		errorsTable,
+		importForm,
+		A(Attr(a.Href("/import")), Text(rb.Get("import.startover"))),
+	)
+}
+
+func (p *ImportPage) runImport(c echo.Context) error {
+	result, _, err := parseImport(c)
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, err.Error())
+	}
+
+	written, err := importer.Write(c.Request().Context(), p.DB, result.Households)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError,
+			fmt.Sprintf("Imported %d of %d households before failing: %v", written, len(result.Households), err))
+	}
+
+	rb := GetResourceBundle(c)
+	return p.render(c,
+		H2_(Text(rb.Get("import.complete"))),
+		P_(Text(rb.Getf("import.imported", Args{"imported": written, "skipped": result.Skipped}))),
+		A(Attr(a.Href("/households")), Text(rb.Get("import.viewhouseholds"))),
+	)
+}
+
+func (p *ImportPage) render(c echo.Context, body ...HTML) error {
+	rb := GetResourceBundle(c)
+	title := rb.Get("import.title")
+	page := StaffPage(rb, title, append([]HTML{H1_(Text(title))}, body...)...)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// parseImport decodes the carried file and column mapping and parses it.
+func parseImport(c echo.Context) (*importer.Result, importer.Mapping, error) {
+	data, err := base64.StdEncoding.DecodeString(c.FormValue("data"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("import data is corrupt, please upload the file again")
+	}
+	headers, err := csv.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	mapping := make(importer.Mapping, len(headers))
+	for i := range mapping {
+		mapping[i] = c.FormValue("col" + strconv.Itoa(i))
+	}
+
+	result, err := importer.Parse(bytes.NewReader(data), mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, mapping, nil
+}
+
+func mappingTable(headers []string, guess importer.Mapping, rb *ResourceBundle) HTML {
+	rows := make([]HTML, len(headers))
+	for i, h := range headers {
+		options := []HTML{Option(Attr(a.Value(importer.FieldIgnore)), Text(rb.Get("import.ignore")))}
+		for _, f := range importer.Fields {
+			attrs := []a.Attribute{a.Value(f)}
+			if f == guess[i] {
+				attrs = append(attrs, a.Selected("selected"))
+			}
+			options = append(options, Option(attrs, Text(f)))
+		}
+		rows[i] = Tr_(
+			Td_(Text(h)),
+			Td_(Select(Attr(a.Class("form-control"), a.Name("col"+strconv.Itoa(i))), options...)),
+		)
+	}
+	return Table(Attr(a.Class("table table-sm")),
+		Thead_(Tr_(Th_(Text(rb.Get("import.column"))), Th_(Text(rb.Get("import.field"))))),
+		Tbody_(rows...))
+}
+
+// columnFor returns the CSV header mapped to field, falling back to the field key.
+func columnFor(headers []string, mapping importer.Mapping, field string) string {
+	for i, f := range mapping {
+		if f == field && i < len(headers) {
+			return headers[i]
+		}
+	}
+	return field
+}
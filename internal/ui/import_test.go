@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// multipartRequest posts fields, and file as the upload if not nil, the way
+// the import forms do.
+func multipartRequest(t *testing.T, fields map[string]string, file []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if file != nil {
+		part, err := w.CreateFormFile("file", "clients.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(file)
+	}
+	w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/import", &body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	return req
+}
+
+func TestImportAtSizeCap(t *testing.T) {
+	// rows padded with an ignored column, so the file is exactly the cap
+	header := "First Name,Last Name,Date of Birth,Notes\n"
+	row := "Ana,Lopez,1980-01-02," + strings.Repeat("x", 1000) + "\n"
+	csv := []byte(header)
+	rows := 0
+	for len(csv)+len(row) <= maxImportSize {
+		csv = append(csv, row...)
+		rows++
+	}
+	csv = append(csv[:len(csv)-1], bytes.Repeat([]byte("x"), maxImportSize-len(csv))...)
+	csv = append(csv, '\n')
+	if len(csv) != maxImportSize {
+		t.Fatalf("file is %d bytes, want %d", len(csv), maxImportSize)
+	}
+
+	p := &ImportPage{}
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := p.POST(e.NewContext(multipartRequest(t, map[string]string{"step": "map", "lang": "en"}, csv), rec)); err != nil {
+		t.Fatal(err)
+	}
+	html := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(html, `enctype="multipart/form-data"`) {
+		t.Fatalf("map step: %d, multipart form missing", rec.Code)
+	}
+	data := regexp.MustCompile(`name="data" value="([^"]*)"`).FindStringSubmatch(html)
+	if data == nil {
+		t.Fatal("map step: no data field")
+	}
+
+	fields := map[string]string{"step": "dryrun", "lang": "en", "data": data[1],
+		"col0": "firstName", "col1": "lastName", "col2": "dob", "col3": ""}
+	rec = httptest.NewRecorder()
+	if err := p.POST(e.NewContext(multipartRequest(t, fields, nil), rec)); err != nil {
+		t.Fatal(err)
+	}
+	if want := Bundle("en").Getf("import.rowsread", Args{"count": rows}); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("dry run: %d, want %q in\n%.500s", rec.Code, want, rec.Body.String())
+	}
+}
@@ -0,0 +1,290 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// intakeForm returns the intake form of site, or an empty form when no site
+// is given.
+func intakeForm(ctx context.Context, database *db.FirestoreDB, site string) (model.IntakeForm, error) {
+	if site == "" {
+		return model.IntakeForm{}, nil
+	}
+	form, err := database.GetIntakeForm(ctx, site)
+	if err != nil {
+		return model.IntakeForm{}, err
+	}
+	return *form, nil
+}
+
+// intakeAnswers returns the answers to the form's questions in scope from
+// the form fields named prefix+"Extra."+key.
+func intakeAnswers(c echo.Context, form model.IntakeForm, scope string, prefix string) map[string]string {
+	params, _ := c.FormParams()
+	return form.Answers(scope, func(key string) []string { return params[prefix+"Extra."+key] })
+}
+
+// intakeFields renders questions as form fields named prefix+"Extra."+key,
+// two to a row.
+func intakeFields(prefix string, fields []model.IntakeField, fb *FormBuilder, rb *ResourceBundle) []HTML {
+	var rows []HTML
+	for i := 0; i < len(fields); i += 2 {
+		var cols []HTML
+		for _, f := range fields[i:min(i+2, len(fields))] {
+			cols = append(cols, intakeField("col-md-6", prefix, f, fb, rb))
+		}
+		rows = append(rows, Div(Attr(a.Class("form-row")), cols...))
+	}
+	return rows
+}
+
+func intakeField(class string, prefix string, f model.IntakeField, fb *FormBuilder, rb *ResourceBundle) HTML {
+	name := prefix + "Extra." + f.Key
+	label := intakeLabel(rb, f.LabelKey, f.Label)
+	switch f.Type {
+	case model.IntakeSelect:
+		return fb.SelectDiv(class, name, label, append([]ValueLabel{{Value: "", Label: ""}}, intakeOptions(f, rb)...))
+	case model.IntakeMultiSelect:
+		return checkboxesDiv(class, name, label, intakeOptions(f, rb), fb)
+	case model.IntakeCheckbox:
+		return checkboxesDiv(class, name, "", []ValueLabel{{Value: "yes", Label: label}}, fb)
+	case model.IntakeNumber, model.IntakeDate:
+		return fb.TypedInputDiv(class, f.Type, name, label)
+	default:
+		return fb.InputDiv(class, name, label)
+	}
+}
+
+// checkboxesDiv is a group of checkboxes sharing one name, under label if
+// it isn't empty.
+func checkboxesDiv(class string, name string, label string, vals []ValueLabel, fb *FormBuilder) HTML {
+	inputClass, _ := fb.GetFormClassAndValidationElem(name)
+	inputClass = strings.Replace(inputClass, "form-control", "form-check-input", 1)
+	params, _ := fb.C.FormParams()
+
+	h := []HTML{}
+	if label != "" {
+		h = append(h, Label_(Text(label)))
+	}
+	for i, v := range vals {
+		id := fmt.Sprintf("%s-%d", name, i)
+		attrs := []a.Attribute{a.Type("checkbox"), a.Class(inputClass), a.Name(name), a.Id(id), a.Value(v.Value)}
+		if slices.Contains(params[name], v.Value) {
+			attrs = append(attrs, a.Checked("checked"))
+		}
+		h = append(h, Div(Attr(a.Class("form-check")),
+			Input(attrs),
+			Label(Attr(a.Class("form-check-label"), a.For(id)), Text(v.Label)),
+		))
+	}
+	if msg, ok := fb.Errs[name]; ok {
+		// as for the date of birth, the message is below all the boxes
+		h = append(h, Div(Attr(a.Class("invalid-feedback d-block")), Text(msg)))
+	}
+	return Div(Attr(a.Class("form-group "+class)), h...)
+}
+
+func intakeOptions(f model.IntakeField, rb *ResourceBundle) []ValueLabel {
+	options := make([]ValueLabel, len(f.Options))
+	for i, o := range f.Options {
+		options[i] = ValueLabel{Value: o.Value, Label: intakeLabel(rb, o.LabelKey, o.Label)}
+	}
+	return options
+}
+
+// intakeLabel returns the message for labelKey, or label when the language
+// has no such message.
+func intakeLabel(rb *ResourceBundle, labelKey string, label string) string {
+	if labelKey != "" {
+		if msg, ok := rb.lookup(labelKey); ok {
+			return msg
+		}
+	}
+	if label == "" {
+		return labelKey
+	}
+	return label
+}
+
+// intakeAnswersTable lists the household's answers to intake questions,
+// those of the household first and then each person's. It is empty when
+// there are none.
+func intakeAnswersTable(h model.Household, forms []model.IntakeForm, rb *ResourceBundle) HTML {
+	rows := intakeRows(rb.Get("intake.household"), h.Extra, forms, rb)
+	for _, p := range h.Persons() {
+		rows = append(rows, intakeRows(p.FirstName+" "+p.LastName, p.Extra, forms, rb)...)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return Div_(
+		H2_(Text(rb.Get("intake.answers"))),
+		Table(Attr(a.Class("table table-sm")), Tbody_(rows...)),
+	)
+}
+
+// intakeRows lists the answers in extra as table rows, labelled by the
+// first form that has the question. Answers to questions no form has any
+// more are listed by key.
+func intakeRows(who string, extra map[string]string, forms []model.IntakeForm, rb *ResourceBundle) []HTML {
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rows := make([]HTML, len(keys))
+	for i, key := range keys {
+		label, answer := key, extra[key]
+		for _, form := range forms {
+			if f, ok := form.Field(key); ok {
+				label, answer = intakeLabel(rb, f.LabelKey, f.Label), intakeAnswerText(f, answer, rb)
+				break
+			}
+		}
+		rows[i] = Tr_(Td_(Text(who)), Td_(Text(label)), Td_(Text(answer)))
+	}
+	return rows
+}
+
+// intakeAnswerText shows an answer as the labels of the options chosen.
+func intakeAnswerText(f model.IntakeField, answer string, rb *ResourceBundle) string {
+	switch f.Type {
+	case model.IntakeSelect, model.IntakeMultiSelect:
+		options := intakeOptions(f, rb)
+		var labels []string
+		for _, v := range strings.Split(answer, ",") {
+			labels = append(labels, optionLabel(options, v))
+		}
+		return strings.Join(labels, ", ")
+	case model.IntakeCheckbox:
+		return rb.Get("misc.yes")
+	case model.IntakeDate:
+		return rb.FormatDOB(answer)
+	}
+	return answer
+}
+
+// IntakeFormPage lets staff set the questions each site asks at signup in
+// addition to the standard ones. The questions are edited as JSON.
+type IntakeFormPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *IntakeFormPage) GET(c echo.Context) error {
+	site := c.QueryParam("site")
+	form, err := intakeForm(c.Request().Context(), p.DB, site)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load intake form: %v", err))
+	}
+	return p.getPage(c, form, "", nil)
+}
+
+// POST saves the questions of the site in the form.
+func (p *IntakeFormPage) POST(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	form := model.IntakeForm{Id: c.FormValue("site")}
+	if form.Id == "" {
+		return c.HTML(http.StatusBadRequest, "No site given")
+	}
+
+	dec := json.NewDecoder(strings.NewReader(c.FormValue("fields")))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&form.Fields); err != nil {
+		return p.getPage(c, form, "", model.ValidationErrors{{Field: "fields", Type: "invalid", Message: "invalid_json"}})
+	}
+	if errs := form.Validate(); errs.HasErrors() {
+		return p.getPage(c, form, "", errs)
+	}
+
+	if err := p.DB.PutIntakeForm(c.Request().Context(), form); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save intake form: %v", err))
+	}
+	return p.getPage(c, form, rb.Get("intake.saved"), nil)
+}
+
+// getPage shows the form's questions for editing, with a preview. When errs
+// is set the submitted text is shown again as it was.
+func (p *IntakeFormPage) getPage(c echo.Context, form model.IntakeForm, notice string, errs model.ValidationErrors) error {
+	rb := GetResourceBundle(c)
+	sites, err := p.DB.GetFoodBanks(c.Request().Context())
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	siteOptions := []ValueLabel{{Value: "", Label: rb.Get("intake.choosesite")}}
+	for _, s := range sites {
+		siteOptions = append(siteOptions, ValueLabel{Value: s.Id, Label: s.Name})
+	}
+
+	fb := &FormBuilder{Errs: ValidationErrors{}, C: c}
+	body := []HTML{
+		H1_(Text(rb.Get("intake.title"))),
+		P_(Text(rb.Get("intake.intro"))),
+		Form(Attr(a.Action("/admin/intake"), a.Method("GET")),
+			Select(Attr(a.Class("form-control mb-4"), a.Name("site"), a.Onchange(nil, "this.form.submit()")),
+				fb.selectOptions("site", siteOptions)...),
+		),
+	}
+	if form.Id == "" {
+		return c.HTML(http.StatusOK, string(StaffPage(rb, rb.Get("intake.title"), body...)))
+	}
+
+	text := c.FormValue("fields")
+	if errs == nil {
+		fields := form.Fields
+		if fields == nil {
+			fields = []model.IntakeField{}
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(fields)
+		text = buf.String()
+	}
+
+	if notice != "" {
+		body = append(body, Div(Attr(a.Class("alert alert-success")), Text(notice)))
+	}
+	if len(errs) > 0 {
+		items := make([]HTML, len(errs))
+		for i, e := range errs {
+			items[i] = Li_(Code_(Text(e.Field)), Text(": "+ValidationMessage(e, rb)))
+		}
+		body = append(body, Div(Attr(a.Class("alert alert-danger")), Ul(Attr(a.Class("mb-0")), items...)))
+	}
+
+	body = append(body,
+		Form(Attr(a.Action("/admin/intake"), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(form.Id))),
+			Div(Attr(a.Class("form-group")),
+				Label(Attr(a.For("fields")), Text(rb.Get("intake.fields"))),
+				Textarea(Attr(a.Class("form-control text-monospace"), a.Name("fields"), a.Id("fields"), a.Rows("20"), a.Spellcheck("false")),
+					Text(text)),
+				Small(Attr(a.Class("form-text text-muted")), Text(rb.Get("intake.help"))),
+			),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("misc.save"))),
+		),
+	)
+	if len(errs) == 0 && len(form.Fields) > 0 {
+		preview := &FormBuilder{Errs: ValidationErrors{}, C: c}
+		body = append(body, H2(Attr(a.Class("my-4")), Text(rb.Get("intake.preview"))))
+		body = append(body, intakeFields("preview", form.Scoped(model.IntakeHousehold), preview, rb)...)
+		body = append(body, intakeFields("preview", form.Scoped(model.IntakePerson), preview, rb)...)
+	}
+
+	return c.HTML(http.StatusOK, string(StaffPage(rb, rb.Get("intake.title"), body...)))
+}
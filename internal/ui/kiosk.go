@@ -0,0 +1,279 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/middleware"
+	"foodbank/internal/model"
+	"net/http"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// KioskPage is the signup form as a step-by-step wizard for a tablet in the
+// lobby. The form starts over after Timeout without a touch, and the
+// confirmation screen returns to the start after ResetAfter. While a browser
+// is in kiosk mode middleware.KioskMiddleware keeps it off the other pages.
+type KioskPage struct {
+	SignupPage
+	Timeout    time.Duration
+	ResetAfter time.Duration
+}
+
+// kioskStart clears the previous client's language and shows the first step.
+const kioskStart = "/kiosk/start"
+
+// Start puts the browser in kiosk mode and starts a new signup in the
+// browser's default language. The kiosk asks the intake questions of the
+// site given by the site parameter when it is first started, which the
+// kiosk cookie keeps for later signups.
+func (p *KioskPage) Start(c echo.Context) error {
+	site := c.QueryParam("site")
+	if site == "" {
+		site = kioskSite(c)
+	}
+	c.SetCookie(&http.Cookie{Name: middleware.KioskCookie, Value: site, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+	c.SetCookie(&http.Cookie{Name: langCookie, Path: "/", MaxAge: -1})
+	return c.Redirect(http.StatusSeeOther, "/kiosk")
+}
+
+// Exit takes the browser out of kiosk mode. It is for staff only.
+func (p *KioskPage) Exit(c echo.Context) error {
+	c.SetCookie(&http.Cookie{Name: middleware.KioskCookie, Path: "/", MaxAge: -1})
+	return c.Redirect(http.StatusSeeOther, "/households")
+}
+
+// kioskSite returns the site the kiosk is at, or "" if none was given.
+func kioskSite(c echo.Context) string {
+	cookie, err := c.Cookie(middleware.KioskCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (p *KioskPage) GET(c echo.Context) error {
+	if _, err := c.Cookie(middleware.KioskCookie); err != nil {
+		return c.Redirect(http.StatusSeeOther, kioskStart)
+	}
+	form, err := intakeForm(c.Request().Context(), p.DB, kioskSite(c))
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load intake form: %v", err))
+	}
+	return p.getPage(c, form, ValidationErrors{})
+}
+
+func (p *KioskPage) POST(c echo.Context) error {
+	ctx := db.WithActor(c.Request().Context(), "kiosk")
+	form, err := intakeForm(ctx, p.DB, kioskSite(c))
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load intake form: %v", err))
+	}
+
+	rb := GetResourceBundle(c)
+	errs := p.validate(c, rb, form)
+	if len(errs) > 0 {
+		return p.getPage(c, form, errs)
+	}
+	match, err := p.save(ctx, c, form)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save household: %v", err))
+	}
+	if match != nil {
+		return c.HTML(http.StatusOK, string(kioskPage(rb, p.Timeout, confirmDetails(c, rb, "/kiosk", match.Id))))
+	}
+
+	// the confirmation starts over sooner than the form
+	page := kioskPage(rb, p.ResetAfter,
+		H1(Attr(a.Class("text-center display-4 my-5")), Text(rb.Get("misc.thankyou"))),
+		P(Attr(a.Class("text-center lead")), Text(rb.Get("signup.success"))),
+		P(Attr(a.Class("text-center text-muted")), Text(rb.Getf("kiosk.reset", Args{"seconds": int(p.ResetAfter.Seconds())}))),
+		Div(Attr(a.Class("text-center my-5")),
+			A(Attr(a.Class("btn btn-primary btn-lg"), a.Href(kioskStart)), Text(rb.Get("kiosk.done"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// kioskStep is one screen of the wizard. The fields of every step are in one
+// form, and the script shows one step at a time.
+type kioskStep struct {
+	title  string
+	fields []HTML
+}
+
+func (p *KioskPage) getPage(c echo.Context, form model.IntakeForm, errs ValidationErrors) error {
+	fb := &FormBuilder{Errs: errs, C: c}
+	rb := GetResourceBundle(c)
+
+	steps := []kioskStep{
+		{rb.Get("signup.title"), []HTML{
+			P(Attr(a.Class("lead")), Text(rb.Get("signup.intro"))),
+			kioskLanguages(rb),
+		}},
+		{rb.Get("kiosk.step.name"), []HTML{
+			fb.InputDiv("", "hohFirstName", rb.Get("misc.firstname")),
+			fb.InputDiv("", "hohLastName", rb.Get("misc.lastname")),
+		}},
+		{rb.Get("kiosk.step.dob"), []HTML{
+			dobField("", "hoh", fb, rb),
+		}},
+		{rb.Get("kiosk.step.contact"), []HTML{
+			fb.InputDiv("", "hohStreet", rb.Get("misc.address")),
+			Div(Attr(a.Class("form-row")),
+				fb.InputDiv("col-8", "hohCity", rb.Get("misc.city")),
+				fb.InputDiv("col-4", "hohZip", rb.Get("misc.zipcode")),
+			),
+			fb.InputDiv("", "hohPhone", rb.Get("misc.phone")),
+			fb.InputDiv("", "hohEmail", rb.Get("misc.email")),
+		}},
+		{rb.Get("kiosk.step.about"), []HTML{
+			fb.SelectDiv("", "hohGender", rb.Get("misc.gender"), genderOptions(rb)),
+			fb.SelectDiv("", "hohRace", rb.Get("misc.race"), raceOptions(rb)),
+			fb.SelectDiv("", "hohLanguage", rb.Get("misc.primarylang"), languageOptions(rb)),
+		}},
+	}
+	steps = append(steps, kioskStep{rb.Get("kiosk.step.income"), []HTML{incomeFields(fb, rb)}})
+	// the site's questions for the head of household, if it has any
+	questions := append(intakeFields("hoh", form.Scoped(model.IntakeHousehold), fb, rb),
+		intakeFields("hoh", form.Scoped(model.IntakePerson), fb, rb)...)
+	if len(questions) > 0 {
+		steps = append(steps, kioskStep{rb.Get("kiosk.step.questions"), questions})
+	}
+	steps = append(steps, kioskStep{rb.Get("kiosk.step.household"), p.members(form, fb, rb, errs)})
+	steps = append(steps, kioskStep{rb.Get("kiosk.step.sign"), []HTML{signatureField(fb, rb)}})
+
+	var fieldsets []HTML
+	for i, step := range steps {
+		nav := []HTML{}
+		if i > 0 {
+			nav = append(nav, Button(Attr(a.Type("button"), a.Class("btn btn-outline-secondary btn-lg kiosk-back")), Text(rb.Get("kiosk.back"))))
+		}
+		switch {
+		case i == 0:
+			nav = append(nav, Button(Attr(a.Type("button"), a.Class("btn btn-primary btn-lg kiosk-next")), Text(rb.Get("kiosk.start"))))
+		case i < len(steps)-1:
+			nav = append(nav, Button(Attr(a.Type("button"), a.Class("btn btn-primary btn-lg kiosk-next")), Text(rb.Get("kiosk.next"))))
+		default:
+			nav = append(nav, Button(Attr(a.Type("submit"), a.Class("btn btn-success btn-lg")), Text(rb.Get("misc.submit"))))
+		}
+
+		content := []HTML{
+			Legend(Attr(a.Class("h2 mb-4")), Text(step.title)),
+		}
+		if i > 0 {
+			content = append(content, P(Attr(a.Class("text-muted")),
+				Text(rb.Getf("kiosk.stepof", Args{"step": i, "steps": len(steps) - 1}))))
+		}
+		content = append(content, step.fields...)
+		content = append(content, Div(Attr(a.Class("d-flex justify-content-between mt-5")), nav...))
+		fieldsets = append(fieldsets, Fieldset(Attr(a.Class("kiosk-step")), content...))
+	}
+
+	page := kioskPage(rb, p.Timeout,
+		Form(Attr(a.Action("/kiosk"), a.Method("POST"), a.Autocomplete("off")),
+			append([]HTML{Input(Attr(a.Type("hidden"), a.Name("lang"), a.Value(rb.Lang)))}, fieldsets...)...),
+		kioskWizardScript(),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// members is the household step. Forms for other people are hidden until
+// "Add a person" is touched, except those already filled in.
+func (p *KioskPage) members(form model.IntakeForm, fb *FormBuilder, rb *ResourceBundle, errs ValidationErrors) []HTML {
+	var h []HTML
+	for i := 0; i < 5; i++ {
+		prefix := fmt.Sprintf("person%d", i)
+		class := "kiosk-person"
+		if fb.C.FormValue(prefix+"FirstName") == "" {
+			class += " d-none"
+		}
+		fields := append([]HTML{H4(Attr(a.Class("my-3")), Text(fmt.Sprintf("%s %d", rb.Get("misc.person"), i+1)))},
+			p.personForm(prefix, false, form, fb, rb, errs)...)
+		h = append(h, Div(Attr(a.Class(class)), fields...))
+	}
+	return append(h, Button(Attr(a.Type("button"), a.Class("btn btn-outline-primary btn-lg btn-block kiosk-add")),
+		Text(rb.Get("kiosk.addperson"))))
+}
+
+// kioskLanguages offers each language as a large button.
+func kioskLanguages(rb *ResourceBundle) HTML {
+	var buttons []HTML
+	for _, lang := range Languages {
+		class := "btn btn-lg btn-outline-primary m-2"
+		if lang == rb.Lang {
+			class = "btn btn-lg btn-primary m-2"
+		}
+		buttons = append(buttons, A(Attr(a.Class(class), a.Href("/kiosk?lang="+lang), a.Lang(lang)),
+			Text(resources[lang]["meta.name"])))
+	}
+	return Div(Attr(a.Class("text-center my-4")), buttons...)
+}
+
+// kioskPage wraps body in the kiosk's page chrome: large controls, no links
+// to other pages, and a script that starts over after timeout without a
+// touch or key press.
+func kioskPage(rb *ResourceBundle, timeout time.Duration, body ...HTML) HTML {
+	content := append([]HTML{
+		Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-4"))),
+	}, body...)
+	return Html5(pageAttrs(rb),
+		pageHead(rb, rb.Get("signup.title")),
+		Body_(
+			FontScalingStyle("1.4rem"),
+			kioskStyle,
+			Div(Attr(a.Class("container my-4 kiosk")), content...),
+			Script_(JavaScript(map[string]any{"timeout": timeout.Milliseconds(), "start": kioskStart}, `
+var idle;
+function restartIdle() {
+  clearTimeout(idle);
+  idle = setTimeout(function () { location.replace({{.start}}); }, {{.timeout}});
+}
+["touchstart", "mousedown", "keydown", "input", "scroll"].forEach(function (name) {
+  document.addEventListener(name, restartIdle, {passive: true});
+});
+restartIdle();`)),
+		))
+}
+
+var kioskStyle = Style_(Text(`
+	.kiosk .form-control { font-size: 1.5rem; height: auto; padding: .75rem 1rem; }
+	.kiosk label { font-size: 1.25rem; }
+	.kiosk .btn-lg { font-size: 1.5rem; padding: 1rem 2rem; min-width: 10rem; }
+`))
+
+// kioskWizardScript shows one step at a time, starting at the first step with
+// an error when the form comes back from the server.
+func kioskWizardScript() HTML {
+	return Script_(JavaScript_(`
+var steps = Array.from(document.querySelectorAll(".kiosk-step"));
+var current = 0;
+function show(i) {
+  current = i;
+  steps.forEach(function (step, j) { step.classList.toggle("d-none", j !== i); });
+  window.scrollTo(0, 0);
+}
+document.querySelectorAll(".kiosk-next").forEach(function (b) {
+  b.addEventListener("click", function () { show(current + 1); });
+});
+document.querySelectorAll(".kiosk-back").forEach(function (b) {
+  b.addEventListener("click", function () { show(current - 1); });
+});
+document.querySelectorAll(".kiosk-add").forEach(function (b) {
+  b.addEventListener("click", function () {
+    var hidden = document.querySelector(".kiosk-person.d-none");
+    if (hidden) {
+      hidden.classList.remove("d-none");
+      hidden.querySelector("input").focus();
+    }
+    if (!document.querySelector(".kiosk-person.d-none")) {
+      b.classList.add("d-none");
+    }
+  });
+});
+var invalid = document.querySelector(".is-invalid");
+show(invalid ? steps.indexOf(invalid.closest(".kiosk-step")) : 0);`))
+}
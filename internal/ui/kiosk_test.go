@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"foodbank/internal/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestKioskPage(t *testing.T) {
+	p := &KioskPage{Timeout: 2 * time.Minute, ResetAfter: 15 * time.Second}
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/kiosk", nil)
+	rec := httptest.NewRecorder()
+	if err := p.GET(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != kioskStart {
+		t.Errorf("without kiosk cookie: got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/kiosk?lang=es", nil)
+	// a kiosk that wasn't given a site asks no intake questions
+	req.AddCookie(&http.Cookie{Name: middleware.KioskCookie, Value: ""})
+	rec = httptest.NewRecorder()
+	if err := p.GET(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	body := rec.Body.String()
+	if n := strings.Count(body, `class="kiosk-step"`); n != 8 {
+		t.Errorf("got %d steps, want 8", n)
+	}
+	// the only links are to the kiosk itself
+	for _, m := range regexp.MustCompile(`href="([^"]*)"`).FindAllStringSubmatch(body, -1) {
+		if !strings.HasPrefix(m[1], "/kiosk") && !strings.HasPrefix(m[1], "https://") {
+			t.Errorf("link to %s", m[1])
+		}
+	}
+	if !strings.Contains(body, "120000") || !strings.Contains(body, "Paso 1 de 7") {
+		t.Errorf("page is missing the timeout or step text:\n%s", body)
+	}
+
+	// starting over keeps the site the kiosk was started for
+	req = httptest.NewRequest(http.MethodGet, kioskStart, nil)
+	req.AddCookie(&http.Cookie{Name: middleware.KioskCookie, Value: "site1"})
+	rec = httptest.NewRecorder()
+	if err := p.Start(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if cookie := rec.Result().Cookies()[0]; cookie.Name != middleware.KioskCookie || cookie.Value != "site1" {
+		t.Errorf("got cookie %v, want the site kept", cookie)
+	}
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// queueRefresh reloads a queue page every few seconds, so screens left open
+// keep up with the queue.
+var queueRefresh = Script_(JavaScript_(`setTimeout(function () { location.reload(); }, 10000);`))
+
+// minutes writes a duration in whole minutes.
+func minutes(d time.Duration, rb *ResourceBundle) string {
+	return rb.Getf("queue.minutes", Args{"count": int(d.Round(time.Minute) / time.Minute)})
+}
+
+// QueuePage is the staff screen of a site's queue on a day: who is being
+// served and who is waiting, with buttons to call the next household and
+// record when each is done.
+type QueuePage struct {
+	DB *db.FirestoreDB
+}
+
+// GET shows the queue of the chosen site, today unless another date is given.
+// Only today's queue can be changed.
+func (p *QueuePage) GET(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	today := time.Now().In(model.Location()).Format("2006-01-02")
+	site, date := c.QueryParam("site"), c.QueryParam("date")
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		date = today
+	}
+	live := date == today
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	var tickets []model.QueueTicket
+	if site != "" {
+		if tickets, err = p.DB.GetQueue(ctx, site, date); err != nil {
+			return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load queue: %v", err))
+		}
+	}
+
+	// names are shown only of households still in the queue
+	var ids []string
+	for _, t := range tickets {
+		if t.Status == model.QueueWaiting || t.Status == model.QueueServing {
+			ids = append(ids, t.HouseholdId)
+		}
+	}
+	households, err := p.DB.GetHouseholdsByID(ctx, ids)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load households: %v", err))
+	}
+
+	now := time.Now()
+	counts := map[string]int{}
+	var serving, waiting, done []HTML
+	for _, t := range tickets {
+		counts[t.Status]++
+		name := ""
+		if household, ok := households[t.HouseholdId]; ok {
+			name = household.Head.LastName + ", " + household.Head.FirstName
+		}
+		number := Td(Attr(a.Class("h4")), Text(t.Number))
+		joined := Td_(Text(t.JoinedAt.In(model.Location()).Format("15:04")))
+		switch t.Status {
+		case model.QueueServing:
+			wait, _ := t.Wait()
+			serving = append(serving, Tr_(number, Td_(Text(name)), joined, Td_(Text(minutes(wait, rb))),
+				Td_(ticketActions(t, site, live, rb))))
+		case model.QueueWaiting:
+			waiting = append(waiting, Tr_(number, Td_(Text(name)), joined, Td_(Text(minutes(now.Sub(t.JoinedAt), rb))),
+				Td_(ticketActions(t, site, live, rb))))
+		default:
+			var wait string
+			if d, ok := t.Wait(); ok {
+				wait = minutes(d, rb)
+			}
+			done = append(done, Tr_(number, Td_(Text(rb.Get("queue.status."+t.Status))), joined, Td_(Text(wait))))
+		}
+	}
+
+	var controls, refresh HTML
+	if site != "" && live {
+		refresh = queueRefresh
+		controls = Div(Attr(a.Class("my-3")),
+			Form(Attr(a.Class("d-inline"), a.Action("/queue/next"), a.Method("POST")),
+				Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(site))),
+				Button(Attr(a.Class("btn btn-primary btn-lg mr-2"), a.Type("submit")), Text(rb.Get("queue.callnext"))),
+			),
+			A(Attr(a.Class("btn btn-outline-secondary"), a.Target("_blank"),
+				a.Href("/queue/display?"+url.Values{"site": {site}}.Encode())), Text(rb.Get("queue.display"))),
+		)
+	}
+
+	average, called := model.AverageWait(tickets)
+	header := Thead_(Tr_(
+		Th_(Text(rb.Get("queue.number"))), Th_(Text(rb.Get("misc.name"))),
+		Th_(Text(rb.Get("queue.joined"))), Th_(Text(rb.Get("queue.wait"))), Th_(),
+	))
+	fb := &FormBuilder{Errs: ValidationErrors{}, C: c}
+	page := StaffPage(rb, rb.Get("queue.title"),
+		H1_(Text(rb.Get("queue.title"))),
+		Form(Attr(a.Action("/queue"), a.Method("GET")),
+			Div(Attr(a.Class("form-row align-items-end")),
+				fb.SelectDiv("col-md-5", "site", rb.Get("reports.site"), siteOptions(sites, rb)),
+				Div(Attr(a.Class("form-group col-md-4")),
+					Label(Attr(a.For("date")), Text(rb.Get("booking.date"))),
+					Input(Attr(a.Type("date"), a.Class("form-control"), a.Name("date"), a.Id("date"), a.Value(date))),
+				),
+				Div(Attr(a.Class("form-group col-md-3")),
+					Button(Attr(a.Class("btn btn-secondary"), a.Type("submit")), Text(rb.Get("slots.show")))),
+			),
+		),
+		P_(Text(rb.Getf("queue.summary", Args{
+			"waiting": counts[model.QueueWaiting],
+			"served":  counts[model.QueueServed],
+			"left":    counts[model.QueueLeft],
+		}))),
+		P_(Text(rb.Getf("queue.average", Args{"wait": minutes(average, rb), "count": called}))),
+		controls,
+		H2_(Text(rb.Get("queue.serving"))),
+		Table(Attr(a.Class("table")), header, Tbody_(serving...)),
+		H2_(Text(rb.Get("queue.waiting"))),
+		Table(Attr(a.Class("table table-striped")), header, Tbody_(waiting...)),
+		H2_(Text(rb.Get("queue.done"))),
+		Table(Attr(a.Class("table table-sm")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("queue.number"))), Th_(Text(rb.Get("booking.status"))),
+				Th_(Text(rb.Get("queue.joined"))), Th_(Text(rb.Get("queue.wait"))),
+			)),
+			Tbody_(done...)),
+		refresh,
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// ticketActions are the buttons that move a ticket along, for today's queue.
+func ticketActions(t model.QueueTicket, site string, live bool, rb *ResourceBundle) HTML {
+	if !live {
+		return ""
+	}
+	var buttons []HTML
+	for _, action := range []struct{ status, label, class string }{
+		{model.QueueServing, "queue.call", "btn-primary"},
+		{model.QueueServed, "queue.served", "btn-success"},
+		{model.QueueLeft, "queue.left", "btn-outline-secondary"},
+	} {
+		if !t.CanBecome(action.status) {
+			continue
+		}
+		buttons = append(buttons, Form(Attr(a.Class("d-inline mr-1"), a.Action(fmt.Sprintf("/queue/%s/%s", t.Id, action.status)), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(site))),
+			Button(Attr(a.Class("btn btn-sm "+action.class), a.Type("submit")), Text(rb.Get(action.label))),
+		))
+	}
+	return Span_(buttons...)
+}
+
+// Next calls the household that has waited longest.
+func (p *QueuePage) Next(c echo.Context) error {
+	site := c.FormValue("site")
+	today := time.Now().In(model.Location()).Format("2006-01-02")
+	if _, _, err := p.DB.CallNext(c.Request().Context(), site, today); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to call next household: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/queue?"+url.Values{"site": {site}}.Encode())
+}
+
+// Action calls a household, or records that it was served or left.
+func (p *QueuePage) Action(c echo.Context) error {
+	status := c.Param("action")
+	switch status {
+	case model.QueueServing, model.QueueServed, model.QueueLeft:
+	default:
+		return c.HTML(http.StatusBadRequest, "Unknown action")
+	}
+	if err := p.DB.SetTicketStatus(c.Request().Context(), c.Param("id"), status); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to update queue: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/queue?"+url.Values{"site": {c.FormValue("site")}}.Encode())
+}
+
+// Display is the "now serving" screen for the waiting area of a site. It
+// shows only queue numbers, never names.
+func (p *QueuePage) Display(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	today := time.Now().In(model.Location()).Format("2006-01-02")
+	tickets, err := p.DB.GetQueue(c.Request().Context(), c.QueryParam("site"), today)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load queue: %v", err))
+	}
+
+	var serving, next []HTML
+	for _, t := range tickets {
+		switch {
+		case t.Status == model.QueueServing:
+			serving = append(serving, Span(Attr(a.Class("badge badge-success mx-2")), Text(t.Number)))
+		case t.Status == model.QueueWaiting && len(next) < 10:
+			next = append(next, Span(Attr(a.Class("badge badge-light border mx-2")), Text(t.Number)))
+		}
+	}
+	var wait HTML
+	if average, n := model.AverageWait(tickets); n > 0 {
+		wait = P(Attr(a.Class("h3 text-muted")), Text(rb.Getf("queue.expectedwait", Args{"wait": minutes(average, rb)})))
+	}
+
+	page := Html5(pageAttrs(rb),
+		pageHead(rb, rb.Get("queue.nowserving")),
+		Body_(
+			Div(Attr(a.Class("container my-5 text-center")),
+				H1(Attr(a.Class("display-4")), Text(rb.Get("queue.nowserving"))),
+				P(Attr(a.Class("display-1 my-4")), serving...),
+				H2(Attr(a.Class("mt-5")), Text(rb.Get("queue.next"))),
+				P(Attr(a.Class("display-4")), next...),
+				wait,
+			),
+			queueRefresh,
+		))
+	return c.HTML(http.StatusOK, string(page))
+}
@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+var certificationClasses = map[string]string{
+	model.CertificationCurrent: "badge-success",
+	model.CertificationDue:     "badge-warning",
+	model.CertificationOverdue: "badge-danger",
+}
+
+// certificationStatus shows when a household was certified and when it is
+// due to recertify, with its status as a badge.
+func certificationStatus(h model.Household, rb *ResourceBundle) HTML {
+	status := h.CertificationStatus(time.Now())
+	badge := Span(Attr(a.Class("badge "+certificationClasses[status])), Text(rb.Get("recertify.status."+status)))
+	certified, ok := h.CertifiedDate()
+	if !ok {
+		return badge
+	}
+	due, _ := h.RecertificationDue()
+	return Span_(badge, Text(" "+rb.Getf("recertify.dates", Args{
+		"certified": rb.FormatDate(certified),
+		"due":       rb.FormatDate(due),
+	})))
+}
+
+// certificationBanner prompts staff checking a household in to review its
+// details when it is due or overdue to recertify, or is nothing otherwise.
+func certificationBanner(h model.Household, site string, rb *ResourceBundle) HTML {
+	status := h.CertificationStatus(time.Now())
+	if status == model.CertificationCurrent {
+		return ""
+	}
+	class := "alert alert-warning"
+	if status == model.CertificationOverdue {
+		class = "alert alert-danger"
+	}
+	var due string
+	if t, ok := h.RecertificationDue(); ok {
+		due = rb.FormatDate(t)
+	}
+	href := fmt.Sprintf("/household/%s/recertify?%s", h.Id, url.Values{"site": {site}}.Encode())
+	return Div(Attr(a.Class(class+" d-flex justify-content-between align-items-center")),
+		Span_(Text(rb.Getf("recertify.banner", Args{"status": status, "date": due}))),
+		A(Attr(a.Class("btn btn-primary"), a.Href(href)), Text(rb.Get("recertify.recertify"))),
+	)
+}
+
+// historyTable lists a household's certification history: the household as
+// it was before each recertification.
+func historyTable(snapshots []model.HouseholdSnapshot, rb *ResourceBundle) HTML {
+	if len(snapshots) == 0 {
+		return P(Attr(a.Class("text-muted")), Text(rb.Get("recertify.nohistory")))
+	}
+	rows := make([]HTML, len(snapshots))
+	for i, s := range snapshots {
+		h := s.Household
+		var certified, signed string
+		if t, ok := h.CertifiedDate(); ok {
+			certified = rb.FormatDate(t)
+		}
+		if h.Signature != nil {
+			signed = model.FormatTimestamp(h.Signature.SignedAt)
+		}
+		rows[i] = Tr_(
+			Td_(Text(model.FormatTimestamp(s.TakenAt))),
+			Td_(Text(s.TakenBy)),
+			Td_(Text(certified)),
+			Td_(Text(h.Size())),
+			Td_(Text(incomeText(h, rb))),
+			Td_(Text(signed)),
+		)
+	}
+	return Table(Attr(a.Class("table table-sm")),
+		Thead_(Tr_(
+			Th_(Text(rb.Get("recertify.recertified"))), Th_(Text(rb.Get("audit.who"))),
+			Th_(Text(rb.Get("recertify.certified"))), Th_(Text(rb.Get("reports.householdsize"))),
+			Th_(Text(rb.Get("signup.income"))), Th_(Text(rb.Get("signature.title"))),
+		)),
+		Tbody_(rows...))
+}
+
+// RecertificationPage lists the households due or overdue to recertify,
+// those due soonest first.
+type RecertificationPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *RecertificationPage) GET(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	now := time.Now()
+
+	type due struct {
+		household model.Household
+		date      time.Time
+	}
+	var households []due
+	err := p.DB.EachHousehold(c.Request().Context(), func(h model.Household) error {
+		if h.Anonymized || h.CertificationStatus(now) == model.CertificationCurrent {
+			return nil
+		}
+		date, _ := h.RecertificationDue()
+		households = append(households, due{h, date})
+		return nil
+	})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to find households due to recertify: %v", err))
+	}
+	sort.SliceStable(households, func(i, j int) bool { return households[i].date.Before(households[j].date) })
+
+	rows := make([]HTML, len(households))
+	for i, d := range households {
+		h := d.household
+		rows[i] = Tr_(
+			Td_(A(Attr(a.Href("/household/"+h.Id)), Text(h.Head.LastName+", "+h.Head.FirstName))),
+			Td_(Text(h.Head.Phone)),
+			Td_(certificationStatus(h, rb)),
+			Td_(A(Attr(a.Class("btn btn-sm btn-outline-primary"), a.Href(fmt.Sprintf("/household/%s/recertify", h.Id))),
+				Text(rb.Get("recertify.recertify")))),
+		)
+	}
+
+	page := StaffPage(rb, rb.Get("recertify.listtitle"),
+		H1_(Text(rb.Get("recertify.listtitle"))),
+		P_(Text(rb.Getf("recertify.listintro", Args{"count": len(households), "days": model.RecertifyNoticeDays}))),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("misc.name"))), Th_(Text(rb.Get("misc.phone"))),
+				Th_(Text(rb.Get("recertify.certified"))), Th_(),
+			)),
+			Tbody_(rows...)),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// RecertifyPage is where staff review a household's details with the client
+// once a year, take their current income and have them sign the
+// self-declaration again.
+type RecertifyPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *RecertifyPage) GET(c echo.Context) error {
+	return p.getPage(c, ValidationErrors{})
+}
+
+// POST recertifies the household, keeping its earlier details in its history.
+// A site given when the page was opened from check-in returns there.
+func (p *RecertifyPage) POST(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	household, err := p.DB.GetHouseholdByID(ctx, id)
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Household not found: %v", err))
+	}
+
+	var with model.Household
+	with.Income, with.IncomePeriod = toIncome(c)
+	with.Signature = toSignature(c)
+	if errs := recertifyErrors(*household, with, GetResourceBundle(c)); len(errs) > 0 {
+		return p.getPage(c, errs)
+	}
+
+	if err := p.DB.RecertifyHousehold(ctx, id, with); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to recertify household: %v", err))
+	}
+	if site := c.FormValue("site"); site != "" {
+		return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/checkin/%s?%s", id, url.Values{"site": {site}}.Encode()))
+	}
+	return c.Redirect(http.StatusSeeOther, "/household/"+id)
+}
+
+// recertifyErrors checks the income and signature given at recertification,
+// keyed by the page's fields. A signature is required.
+func recertifyErrors(household model.Household, with model.Household, rb *ResourceBundle) ValidationErrors {
+	recertified, _ := household.Recertify(with, time.Now(), "")
+	var errs model.ValidationErrors
+	for _, e := range recertified.Validate() {
+		// other details aren't changed here, so only these errors can be fixed
+		switch e.Field = signupField(e.Field, nil); e.Field {
+		case "hohIncome", "hohIncomePeriod", "signature":
+			errs = append(errs, e)
+		}
+	}
+	if with.Signature == nil {
+		errs = append(errs, model.ValidationError{Field: "signature", Type: "missing", Message: "field_missing"})
+	}
+	return FormErrors(errs, rb)
+}
+
+func (p *RecertifyPage) getPage(c echo.Context, errs ValidationErrors) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	household, err := p.DB.GetHouseholdByID(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Household not found: %v", err))
+	}
+	guidelines, err := p.DB.GetPovertyGuidelines(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load poverty guidelines: %v", err))
+	}
+
+	head := household.Head
+	members := make([]HTML, len(household.Members))
+	for i, m := range household.Members {
+		members[i] = Li_(Text(fmt.Sprintf("%s %s, %s (%s)", m.FirstName, m.LastName, rb.FormatDOB(m.DOB),
+			optionLabel(relationshipOptions(rb), m.Relationship))))
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	page := StaffPage(rb, rb.Get("recertify.title"),
+		H1_(Text(rb.Getf("recertify.heading", Args{"name": head.FirstName + " " + head.LastName}))),
+		P_(Text(rb.Get("recertify.intro"))),
+		Table(Attr(a.Class("table table-bordered")),
+			Tbody_(
+				Tr_(Td_(Text(rb.Get("recertify.certified"))), Td_(certificationStatus(*household, rb))),
+				Tr_(Td_(Text(rb.Get("misc.dob"))), Td_(Text(rb.FormatDOB(head.DOB)))),
+				Tr_(Td_(Text(rb.Get("misc.phone"))), Td_(Text(head.Phone))),
+				Tr_(Td_(Text(rb.Get("misc.email"))), Td_(Text(head.Email))),
+				Tr_(Td_(Text(rb.Get("misc.address"))), Td_(Text(fmt.Sprintf("%s, %s, %s %s",
+					head.Street, head.City, head.State, head.PostalCode)))),
+				Tr_(Td_(Text(rb.Get("reports.householdsize"))), Td_(Text(household.Size()))),
+				Tr_(Td_(Text(rb.Get("signup.income"))), Td_(Text(incomeText(*household, rb)))),
+				Tr_(Td_(Text(rb.Get("eligibility.title"))), Td_(eligibilityStatus(household.Eligibility(guidelines, time.Now()), rb))),
+			),
+		),
+		Ul_(members...),
+		Form(Attr(a.Action(fmt.Sprintf("/household/%s/recertify", household.Id)), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(c.FormValue("site")))),
+			H2(Attr(a.Class("my-4")), Text(rb.Get("signup.income"))),
+			P(Attr(a.Class("text-muted")), Text(rb.Get("recertify.keepincome"))),
+			incomeFields(fb, rb),
+			signatureField(fb, rb),
+			Button(Attr(a.Class("btn btn-primary btn-lg"), a.Type("submit")), Text(rb.Get("recertify.recertify"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/export"
+	"foodbank/internal/model"
+	"foodbank/internal/report"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// TEFAPReportPage renders the USDA TEFAP monthly distribution report for a
+// month and site as HTML, CSV or PDF.
+type TEFAPReportPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *TEFAPReportPage) GET(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+
+	month := c.QueryParam("month")
+	if month == "" {
+		month = time.Now().AddDate(0, -1, 0).Format("2006-01")
+	}
+	from, to, err := report.MonthRange(month)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	siteID := c.QueryParam("site")
+	siteName := rb.Get("reports.allsites")
+	for _, s := range sites {
+		if s.Id == siteID {
+			siteName = s.Name
+		}
+	}
+
+	data, err := report.Load(ctx, p.DB, from, to, siteID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to build report: %v", err))
+	}
+	table := report.TEFAP(data, siteName).Table()
+
+	filename := fmt.Sprintf("tefap-%s", month)
+	switch c.QueryParam("format") {
+	case "csv":
+		c.Response().Header().Set(echo.HeaderContentType, export.ContentType(export.FormatCSV))
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		return export.WriteCSV(c.Response(), table)
+	case "pdf":
+		lines := append([]string{"USDA TEFAP Monthly Distribution Report", ""}, export.AlignColumns(table)...)
+		c.Response().Header().Set(echo.HeaderContentType, "application/pdf")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+		return export.WritePDF(c.Response(), lines)
+	}
+
+	query := url.Values{"month": {month}, "site": {siteID}}.Encode()
+	page := StaffPage(rb, rb.Get("reports.tefap.title"),
+		H1_(Text(rb.Get("reports.tefap.title"))),
+		reportFilterForm("/reports/tefap", sites, siteID, rb,
+			Div(Attr(a.Class("form-group col-md-4")),
+				Label(Attr(a.For("month")), Text(rb.Get("misc.month"))),
+				Input(Attr(a.Type("month"), a.Class("form-control"), a.Name("month"), a.Id("month"), a.Value(month))),
+			),
+		),
+		reportTable(table),
+		P_(
+			A(Attr(a.Class("btn btn-outline-secondary mr-2"), a.Href("/reports/tefap?format=csv&"+query)), Text(rb.Get("reports.downloadcsv"))),
+			A(Attr(a.Class("btn btn-outline-secondary"), a.Href("/reports/tefap?format=pdf&"+query)), Text(rb.Get("reports.downloadpdf"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// reportFilterForm renders a GET form with a site selector after fields.
+func reportFilterForm(action string, sites []model.FoodBank, siteID string, rb *ResourceBundle, fields ...HTML) HTML {
+	options := []HTML{Option(Attr(a.Value("")), Text(rb.Get("reports.allsites")))}
+	for _, s := range sites {
+		attrs := []a.Attribute{a.Value(s.Id)}
+		if s.Id == siteID {
+			attrs = append(attrs, a.Selected("selected"))
+		}
+		options = append(options, Option(attrs, Text(s.Name)))
+	}
+
+	row := append(fields,
+		Div(Attr(a.Class("form-group col-md-4")),
+			Label(Attr(a.For("site")), Text(rb.Get("reports.site"))),
+			Select(Attr(a.Class("form-control"), a.Name("site"), a.Id("site")), options...),
+		),
+		Div(Attr(a.Class("form-group col-md-2 d-flex align-items-end")),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("reports.run"))),
+		),
+	)
+	return Form(Attr(a.Action(action), a.Method("GET")), Div(Attr(a.Class("form-row")), row...))
+}
+
+// reportTable renders rows of cells as a table, treating the first row with
+// more than two cells as the header.
+func reportTable(rows [][]string) HTML {
+	trs := make([]HTML, 0, len(rows))
+	headerDone := false
+	for _, row := range rows {
+		cells := make([]HTML, len(row))
+		for i, cell := range row {
+			if !headerDone && len(row) > 2 {
+				cells[i] = Th_(Text(cell))
+			} else {
+				cells[i] = Td_(Text(cell))
+			}
+		}
+		if len(row) > 2 {
+			headerDone = true
+		}
+		trs = append(trs, Tr_(cells...))
+	}
+	return Table(Attr(a.Class("table table-bordered")), Tbody_(trs...))
+}
+
+// DemographicsPage is the staff dashboard summarizing who was served in a
+// date range.
+type DemographicsPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *DemographicsPage) GET(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+
+	now := time.Now()
+	fromStr, toStr := c.QueryParam("from"), c.QueryParam("to")
+	if fromStr == "" {
+		fromStr = now.AddDate(0, -1, 0).Format("2006-01-02")
+	}
+	if toStr == "" {
+		toStr = now.Format("2006-01-02")
+	}
+	from, fromErr := time.Parse("2006-01-02", fromStr)
+	to, toErr := time.Parse("2006-01-02", toStr)
+	if fromErr != nil || toErr != nil || to.Before(from) {
+		return c.String(http.StatusBadRequest, "from and to must be dates (YYYY-MM-DD) with from on or before to")
+	}
+
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	siteID := c.QueryParam("site")
+
+	data, err := report.Load(ctx, p.DB, from, to, siteID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to load visits: %v", err))
+	}
+	prior, err := report.LoadPriorVisitors(ctx, p.DB, data)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to load visit history: %v", err))
+	}
+	d := report.BuildDemographics(data, prior)
+	served := report.CountServed(data)
+
+	page := StaffPage(rb, rb.Get("reports.demographics.title"),
+		H1_(Text(rb.Get("reports.demographics.title"))),
+		reportFilterForm("/reports/demographics", sites, siteID, rb,
+			dateInputDiv("col-md-3", "from", rb.Get("misc.from"), fromStr),
+			dateInputDiv("col-md-3", "to", rb.Get("misc.to"), toStr),
+		),
+		H4_(Text(rb.Get("reports.served"))),
+		servedTable(served, sites, rb),
+		Div(Attr(a.Class("row")),
+			BarChart(rb.Get("reports.age"), d.AgeBrackets, rb),
+			BarChart(rb.Get("reports.householdsize"), d.HouseholdSizes, rb),
+			BarChart(rb.Get("reports.newvsreturning"), d.NewVsReturning, rb),
+			BarChart(rb.Get("misc.primarylang"), d.Languages, rb),
+			BarChart(rb.Get("misc.race"), d.Races, rb),
+			BarChart(rb.Get("misc.gender"), d.Genders, rb),
+			BarChart(rb.Get("misc.zipcode"), d.ZIPCodes, rb),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// servedTable shows unduplicated counts overall and for each site.
+func servedTable(served report.ServedCounts, sites []model.FoodBank, rb *ResourceBundle) HTML {
+	names := map[string]string{}
+	for _, s := range sites {
+		names[s.Id] = s.Name
+	}
+
+	row := func(label string, households, individuals, visits int) HTML {
+		return Tr_(Td_(Text(label)), Td_(Text(rb.FormatNumber(households))), Td_(Text(rb.FormatNumber(individuals))),
+			Td_(Text(rb.FormatNumber(visits))))
+	}
+	rows := []HTML{row(rb.Get("reports.allsites"), served.Households, served.Individuals, served.Visits)}
+	for _, s := range served.Sites {
+		name, ok := names[s.FoodBankId]
+		if !ok {
+			name = s.FoodBankId
+		}
+		rows = append(rows, row(name, s.Households, s.Individuals, s.Visits))
+	}
+
+	return Table(Attr(a.Class("table table-sm table-bordered")),
+		Thead_(Tr_(Th_(Text(rb.Get("reports.site"))), Th_(Text(rb.Get("reports.households"))), Th_(Text(rb.Get("reports.individuals"))), Th_(Text(rb.Get("reports.visits"))))),
+		Tbody_(rows...))
+}
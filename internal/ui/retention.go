@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"foodbank/internal/retention"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// RetentionPage lists households with no visits for a number of months so
+// staff can review them and anonymize or delete them.
+type RetentionPage struct {
+	DB *db.FirestoreDB
+	// Months is the default inactivity period offered for review.
+	Months int
+}
+
+func (p *RetentionPage) GET(c echo.Context) error {
+	return p.getPage(c, "")
+}
+
+// POST anonymizes or permanently deletes the selected households. Only
+// households that are still inactive are changed.
+func (p *RetentionPage) POST(c echo.Context) error {
+	ctx := c.Request().Context()
+	months := p.months(c)
+	candidates, err := retention.Find(ctx, p.DB, retention.Cutoff(time.Now(), months))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to find inactive households: %v", err))
+	}
+	inactive := map[string]bool{}
+	for _, candidate := range candidates {
+		inactive[candidate.Household.Id] = true
+	}
+
+	action := c.FormValue("action")
+	form, err := c.FormParams()
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	done := 0
+	for _, id := range form["id"] {
+		if !inactive[id] {
+			continue
+		}
+		switch action {
+		case "anonymize":
+			err = p.DB.AnonymizeHousehold(ctx, id, model.AuditAnonymize)
+		case "delete":
+			err = p.DB.PurgeHousehold(ctx, id)
+		default:
+			return c.String(http.StatusBadRequest, "Unknown action")
+		}
+		if err != nil {
+			return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed after %d households: %v", done, err))
+		}
+		done++
+	}
+
+	return p.getPage(c, GetResourceBundle(c).Getf("retention.done."+action, Args{"count": done}))
+}
+
+func (p *RetentionPage) months(c echo.Context) int {
+	if n, err := strconv.Atoi(c.FormValue("months")); err == nil && n > 0 {
+		return n
+	}
+	return p.Months
+}
+
+func (p *RetentionPage) getPage(c echo.Context, notice string) error {
+	rb := GetResourceBundle(c)
+	months := p.months(c)
+	cutoff := retention.Cutoff(time.Now(), months)
+	candidates, err := retention.Find(c.Request().Context(), p.DB, cutoff)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("Failed to find inactive households: %v", err))
+	}
+
+	rows := make([]HTML, len(candidates))
+	for i, candidate := range candidates {
+		h := candidate.Household
+		lastVisit := candidate.LastVisit
+		if lastVisit == "" {
+			lastVisit = rb.Get("misc.never")
+		}
+		rows[i] = Tr_(
+			Td_(Input(Attr(a.Type("checkbox"), a.Name("id"), a.Value(h.Id), a.Checked_()))),
+			Td_(A(Attr(a.Href("/household/"+h.Id)), Text(h.Head.LastName+", "+h.Head.FirstName))),
+			Td_(Text(h.Created())),
+			Td_(Text(lastVisit)),
+			Td_(Text(strconv.Itoa(h.Size()))),
+		)
+	}
+
+	var alert HTML
+	if notice != "" {
+		alert = Div(Attr(a.Class("alert alert-success")), Text(notice))
+	}
+	monthsValue := strconv.Itoa(months)
+
+	page := StaffPage(rb, rb.Get("retention.title"),
+		H1_(Text(rb.Get("retention.title"))),
+		alert,
+		Form(Attr(a.Class("form-inline mb-3"), a.Action("/admin/retention"), a.Method("GET")),
+			Label(Attr(a.For("months"), a.Class("mr-2")), Text(rb.Get("retention.novisitsfor"))),
+			Input(Attr(a.Type("number"), a.Min("1"), a.Class("form-control mr-2"), a.Name("months"), a.Id("months"), a.Value(monthsValue))),
+			Span(Attr(a.Class("mr-2")), Text(rb.Get("retention.months"))),
+			Button(Attr(a.Class("btn btn-outline-primary"), a.Type("submit")), Text(rb.Get("retention.review"))),
+		),
+		P_(Text(rb.Getf("retention.intro", Args{"count": len(candidates), "date": rb.FormatDate(cutoff)}))),
+		Form(Attr(a.Action("/admin/retention"), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("months"), a.Value(monthsValue))),
+			Table(Attr(a.Class("table table-striped")),
+				Thead_(Tr_(Th_(), Th_(Text(rb.Get("signup.hoh"))), Th_(Text(rb.Get("misc.created"))),
+					Th_(Text(rb.Get("retention.lastvisit"))), Th_(Text(rb.Get("reports.householdsize"))))),
+				Tbody_(rows...)),
+			Button(Attr(a.Class("btn btn-primary mr-2"), a.Type("submit"), a.Name("action"), a.Value("anonymize"),
+				confirmClick(rb.Get("retention.confirmanonymize"))), Text(rb.Get("retention.anonymize"))),
+			Button(Attr(a.Class("btn btn-danger"), a.Type("submit"), a.Name("action"), a.Value("delete"),
+				confirmClick(rb.Get("retention.confirmdelete"))), Text(rb.Get("retention.delete"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// RosterPage lists the households expected at a site on a day, by pickup
+// slot, for staff to check them in or mark them no-shows.
+type RosterPage struct {
+	DB *db.FirestoreDB
+}
+
+// GET shows the roster of the chosen site, today unless another date is given.
+func (p *RosterPage) GET(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	site, date := c.QueryParam("site"), c.QueryParam("date")
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		date = time.Now().In(model.Location()).Format("2006-01-02")
+	}
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	slots, bookings, err := slotsOn(c, p.DB, site, date)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load bookings: %v", err))
+	}
+
+	var ids []string
+	for _, b := range bookings {
+		if b.Holds() {
+			ids = append(ids, b.HouseholdId)
+		}
+	}
+	households, err := p.DB.GetHouseholdsByID(ctx, ids)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load households: %v", err))
+	}
+
+	back := "/roster?" + url.Values{"site": {site}, "date": {date}}.Encode()
+	counts := map[string]int{}
+	var rows []HTML
+	for _, b := range bookings {
+		counts[b.Status]++
+		if !b.Holds() {
+			continue
+		}
+		var household *model.Household
+		if h, ok := households[b.HouseholdId]; ok {
+			household = &h
+		}
+		name, phone, size := Text(rb.Get("roster.deleted")), "", ""
+		if household != nil {
+			name = A(Attr(a.Href("/household/"+household.Id)), Text(household.Head.LastName+", "+household.Head.FirstName))
+			phone, size = household.Head.Phone, fmt.Sprint(household.Size())
+		}
+		var checkIn HTML
+		if household != nil && b.CanBecome(model.BookingVisited) {
+			checkIn = A(Attr(a.Class("btn btn-sm btn-primary mr-1"),
+				a.Href(fmt.Sprintf("/checkin/%s?%s", household.Id, url.Values{"site": {site}}.Encode()))),
+				Text(rb.Get("checkin.checkin")))
+		}
+		rows = append(rows, Tr_(
+			Td_(Text(slotTimes(b.Start, b.End))),
+			Td_(name),
+			Td_(Text(phone)),
+			Td_(Text(size)),
+			Td_(bookingStatus(b, rb)),
+			Td_(checkIn, bookingActions(b, back, rb)),
+		))
+	}
+
+	var capacity []HTML
+	for _, s := range slots {
+		if s.On(date) {
+			capacity = append(capacity, Li_(Text(slotTimes(s.Start, s.End)+": "+rb.Getf("roster.capacity", Args{
+				"booked":   s.Capacity - s.Remaining(bookings),
+				"capacity": s.Capacity,
+			}))))
+		}
+	}
+
+	fb := &FormBuilder{Errs: ValidationErrors{}, C: c}
+	page := StaffPage(rb, rb.Get("roster.title"),
+		H1_(Text(rb.Get("roster.title"))),
+		Form(Attr(a.Action("/roster"), a.Method("GET")),
+			Div(Attr(a.Class("form-row align-items-end")),
+				fb.SelectDiv("col-md-5", "site", rb.Get("reports.site"), siteOptions(sites, rb)),
+				Div(Attr(a.Class("form-group col-md-4")),
+					Label(Attr(a.For("date")), Text(rb.Get("booking.date"))),
+					Input(Attr(a.Type("date"), a.Class("form-control"), a.Name("date"), a.Id("date"), a.Value(date))),
+				),
+				Div(Attr(a.Class("form-group col-md-3")),
+					Button(Attr(a.Class("btn btn-secondary"), a.Type("submit")), Text(rb.Get("slots.show")))),
+			),
+		),
+		H2_(Text(slotDate(date, rb))),
+		P_(Text(rb.Getf("roster.summary", Args{
+			"expected":  len(rows),
+			"visited":   counts[model.BookingVisited],
+			"noshow":    counts[model.BookingNoShow],
+			"cancelled": counts[model.BookingCancelled],
+		}))),
+		Ul_(capacity...),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("slots.time"))), Th_(Text(rb.Get("misc.name"))), Th_(Text(rb.Get("misc.phone"))),
+				Th_(Text(rb.Get("reports.householdsize"))), Th_(Text(rb.Get("booking.status"))), Th_(),
+			)),
+			Tbody_(rows...)),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
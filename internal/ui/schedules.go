@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"foodbank/internal/scheduler"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+// ReportSchedulePage lets staff manage scheduled report delivery.
+type ReportSchedulePage struct {
+	DB        *db.FirestoreDB
+	Scheduler *scheduler.Scheduler
+}
+
+func (p *ReportSchedulePage) GET(c echo.Context) error {
+	return p.getPage(c, "", ValidationErrors{})
+}
+
+// POST creates a schedule.
+func (p *ReportSchedulePage) POST(c echo.Context) error {
+	hour, err := strconv.Atoi(c.FormValue("hour"))
+	if err != nil {
+		hour = -1
+	}
+	var recipients []string
+	for _, r := range strings.FieldsFunc(c.FormValue("recipients"), func(r rune) bool { return r == ',' || r == '\n' || r == ' ' }) {
+		recipients = append(recipients, strings.TrimSpace(r))
+	}
+
+	schedule := model.ReportSchedule{
+		Id:         ulid.Make().String(),
+		Name:       strings.TrimSpace(c.FormValue("name")),
+		Report:     c.FormValue("report"),
+		Site:       c.FormValue("site"),
+		Format:     c.FormValue("format"),
+		Recipients: recipients,
+		Hour:       hour,
+		Enabled:    true,
+	}
+	if errs := schedule.Validate(); errs.HasErrors() {
+		return p.getPage(c, "", FormErrors(errs, GetResourceBundle(c)))
+	}
+	schedule.NextRunAt = schedule.NextRun(time.Now())
+
+	if err := p.DB.PutReportSchedule(c.Request().Context(), schedule); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save schedule: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/schedules")
+}
+
+// Action handles the per-schedule buttons: enable, disable, send now and delete.
+func (p *ReportSchedulePage) Action(c echo.Context) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	schedule, err := p.DB.GetReportSchedule(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Schedule not found: %v", err))
+	}
+
+	notice := ""
+	switch c.Param("action") {
+	case "enable":
+		schedule.Enabled = true
+		schedule.NextRunAt = schedule.NextRun(time.Now())
+		err = p.DB.PutReportSchedule(ctx, *schedule)
+	case "disable":
+		schedule.Enabled = false
+		err = p.DB.PutReportSchedule(ctx, *schedule)
+	case "delete":
+		err = p.DB.DeleteReportSchedule(ctx, schedule.Id)
+	case "send":
+		if err = p.Scheduler.Deliver(ctx, *schedule, time.Now()); err == nil {
+			notice = rb.Getf("schedules.sent", Args{"name": schedule.Name, "recipients": strings.Join(schedule.Recipients, ", ")})
+		}
+	default:
+		return c.HTML(http.StatusBadRequest, "Unknown action")
+	}
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to %s schedule: %v", c.Param("action"), err))
+	}
+	if notice != "" {
+		return p.getPage(c, notice, ValidationErrors{})
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/schedules")
+}
+
+func (p *ReportSchedulePage) getPage(c echo.Context, notice string, errs ValidationErrors) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	schedules, err := p.DB.GetReportSchedules(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load schedules: %v", err))
+	}
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	siteNames := map[string]string{"": rb.Get("reports.allsites")}
+	siteOptions := []ValueLabel{{Value: "", Label: rb.Get("reports.allsites")}}
+	for _, s := range sites {
+		siteNames[s.Id] = s.Name
+		siteOptions = append(siteOptions, ValueLabel{Value: s.Id, Label: s.Name})
+	}
+
+	rows := make([]HTML, len(schedules))
+	for i, s := range schedules {
+		toggle := "disable"
+		if !s.Enabled {
+			toggle = "enable"
+		}
+		status := rb.Getf("schedules.lastrun", Args{"time": formatTime(s.LastRunAt, rb)})
+		if s.LastError != "" {
+			status += ": " + s.LastError
+		}
+		next := rb.Get("schedules.disabled")
+		if s.Enabled {
+			next = formatTime(s.NextRunAt, rb)
+		}
+		rows[i] = Tr_(
+			Td_(Text(s.Name)),
+			Td_(Text(rb.Get("schedules.report."+s.Report))),
+			Td_(Text(siteNames[s.Site])),
+			Td_(Text(strings.ToUpper(s.Format))),
+			Td_(Text(strings.Join(s.Recipients, ", "))),
+			Td_(Text(next)),
+			Td_(Text(status)),
+			Td_(
+				scheduleButton(s.Id, "send", rb.Get("schedules.send"), "btn-outline-primary"),
+				scheduleButton(s.Id, toggle, rb.Get("schedules."+toggle), "btn-outline-secondary"),
+				scheduleButton(s.Id, "delete", rb.Get("misc.delete"), "btn-outline-danger"),
+			),
+		)
+	}
+
+	var alert HTML
+	if notice != "" {
+		alert = Div(Attr(a.Class("alert alert-success")), Text(notice))
+	}
+
+	reports := make([]ValueLabel, 0, len(model.ReportNames))
+	for value := range model.ReportNames {
+		reports = append(reports, ValueLabel{Value: value, Label: rb.Get("schedules.report." + value)})
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Value < reports[j].Value })
+	hours := make([]ValueLabel, 24)
+	for h := range hours {
+		hours[h] = ValueLabel{Value: strconv.Itoa(h), Label: fmt.Sprintf("%02d:00", h)}
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	page := StaffPage(rb, rb.Get("schedules.title"),
+		H1_(Text(rb.Get("schedules.title"))),
+		alert,
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("misc.name"))), Th_(Text(rb.Get("schedules.report"))), Th_(Text(rb.Get("reports.site"))),
+				Th_(Text(rb.Get("misc.format"))), Th_(Text(rb.Get("schedules.recipients"))), Th_(Text(rb.Get("schedules.nextrun"))),
+				Th_(Text(rb.Get("misc.status"))), Th_(),
+			)),
+			Tbody_(rows...)),
+		H2(Attr(a.Class("my-4")), Text(rb.Get("schedules.new"))),
+		Form(Attr(a.Action("/admin/schedules"), a.Method("POST")),
+			Div(Attr(a.Class("form-row")),
+				fb.InputDiv("col-md-6", "name", rb.Get("misc.name")),
+				fb.SelectDiv("col-md-6", "report", rb.Get("schedules.report"), reports),
+			),
+			Div(Attr(a.Class("form-row")),
+				fb.SelectDiv("col-md-4", "site", rb.Get("reports.site"), siteOptions),
+				fb.SelectDiv("col-md-4", "format", rb.Get("misc.format"), []ValueLabel{{Value: "pdf", Label: "PDF"}, {Value: "csv", Label: "CSV"}}),
+				fb.SelectDiv("col-md-4", "hour", rb.Get("schedules.sendat"), hours),
+			),
+			fb.InputDiv("", "recipients", rb.Get("schedules.recipientshelp")),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("schedules.create"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+func scheduleButton(id string, action string, label string, class string) HTML {
+	return Form(Attr(a.Class("d-inline"), a.Action(fmt.Sprintf("/admin/schedules/%s/%s", id, action)), a.Method("POST")),
+		Button(Attr(a.Class("btn btn-sm mr-1 "+class), a.Type("submit")), Text(label)))
+}
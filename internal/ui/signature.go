@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"foodbank/internal/model"
+	"html/template"
+	"strings"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+)
+
+// toSignature reads the self-declaration signature from the form, or nil if
+// the client neither drew nor typed one. It is timestamped now, with the
+// version of the attestation text the form shows.
+func toSignature(c echo.Context) *model.Signature {
+	name, image := strings.TrimSpace(c.FormValue("signatureName")), c.FormValue("signatureImage")
+	if name == "" && image == "" {
+		return nil
+	}
+	return &model.Signature{
+		Name:        name,
+		Image:       image,
+		SignedAt:    time.Now(),
+		Attestation: model.AttestationVersion,
+	}
+}
+
+// signatureField shows the self-declaration of need and asks the head of
+// household to sign it, by drawing on the pad or typing their full name. A
+// drawing is kept in the signatureImage input as a PNG data URL, so it comes
+// back with the form when there are errors. Errors for the signature as a
+// whole are keyed "signature".
+func signatureField(fb *FormBuilder, rb *ResourceBundle) HTML {
+	padClass := "signature-pad border rounded d-block w-100 bg-white"
+	var errorEl HTML
+	if msg, ok := fb.Errs["signature"]; ok {
+		padClass += " is-invalid border-danger"
+		errorEl = Div(Attr(a.Class("invalid-feedback d-block")), Text(msg))
+	}
+
+	return Div(Attr(a.Class("signature my-4")),
+		H2(Attr(a.Class("my-4")), Text(rb.Get("signature.title"))),
+		P(Attr(a.Class("border rounded p-3 bg-light")), Text(rb.Get("attestation."+model.AttestationVersion))),
+		Label_(Text(rb.Get("signature.draw"))),
+		Canvas(Attr(a.Class(padClass), a.Width("600"), a.Height("150"), a.Style_("touch-action: none"))),
+		Input(Attr(a.Type("hidden"), a.Name("signatureImage"), a.Value(fb.C.FormValue("signatureImage")))),
+		Button(Attr(a.Type("button"), a.Class("btn btn-sm btn-outline-secondary mt-2 mb-3 signature-clear")),
+			Text(rb.Get("signature.clear"))),
+		fb.InputDiv("", "signatureName", rb.Get("signature.type")),
+		errorEl,
+		signatureScript,
+	)
+}
+
+var signatureScript = Script_(JavaScript_(`
+document.querySelectorAll(".signature").forEach(function (sig) {
+  var canvas = sig.querySelector("canvas");
+  var input = sig.querySelector("input[name=signatureImage]");
+  var ctx = canvas.getContext("2d");
+  var drawing = false;
+  ctx.lineWidth = 3;
+  ctx.lineCap = "round";
+  ctx.lineJoin = "round";
+  function point(e) {
+    var r = canvas.getBoundingClientRect();
+    return [(e.clientX - r.left) * canvas.width / r.width, (e.clientY - r.top) * canvas.height / r.height];
+  }
+  canvas.addEventListener("pointerdown", function (e) {
+    var p = point(e);
+    drawing = true;
+    canvas.setPointerCapture(e.pointerId);
+    ctx.beginPath();
+    ctx.moveTo(p[0], p[1]);
+  });
+  canvas.addEventListener("pointermove", function (e) {
+    if (!drawing) return;
+    var p = point(e);
+    ctx.lineTo(p[0], p[1]);
+    ctx.stroke();
+  });
+  ["pointerup", "pointercancel"].forEach(function (name) {
+    canvas.addEventListener(name, function () {
+      if (!drawing) return;
+      drawing = false;
+      input.value = canvas.toDataURL("image/png");
+    });
+  });
+  sig.querySelector(".signature-clear").addEventListener("click", function () {
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    input.value = "";
+  });
+  if (input.value) {
+    var img = new Image();
+    img.onload = function () { ctx.drawImage(img, 0, 0); };
+    img.src = input.value;
+  }
+});`))
+
+// signatureBlock shows a stored signature with the attestation text that was
+// signed, for the household detail page and the printed sheet.
+func signatureBlock(s *model.Signature, rb *ResourceBundle) HTML {
+	if s == nil {
+		return P(Attr(a.Class("text-muted")), Text(rb.Get("signature.none")))
+	}
+	var mark HTML
+	if s.Drawn() {
+		// the image was checked to be a PNG data URL when saved
+		mark = Img(Attr(a.Src(template.URL(s.Image)), a.Alt(rb.Get("signature.title")), a.Class("border rounded bg-white"), a.Style_("max-width: 100%; height: 100px")))
+	} else {
+		mark = P(Attr(a.Class("h3"), a.Style_("font-family: cursive")), Text(s.Name))
+	}
+	return Div_(
+		P(Attr(a.Class("border rounded p-3 bg-light")), Text(rb.Get("attestation."+s.Attestation))),
+		mark,
+		P(Attr(a.Class("small text-muted")), Text(rb.Getf("signature.signed", Args{
+			"date":    model.FormatTimestamp(s.SignedAt),
+			"version": s.Attestation,
+		}))),
+	)
+}
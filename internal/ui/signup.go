@@ -1,10 +1,17 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"foodbank/internal/db"
 	"foodbank/internal/model"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/julvo/htmlgo"
 	. "github.com/julvo/htmlgo"
@@ -18,30 +25,93 @@ type SignupPage struct {
 }
 
 func (p *SignupPage) GET(c echo.Context) error {
-	return p.getPage(c, map[string]string{})
+	form, err := intakeForm(c.Request().Context(), p.DB, c.QueryParam("site"))
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load intake form: %v", err))
+	}
+	return p.getPage(c, form, map[string]string{})
 }
 
-func toHousehold(c echo.Context) model.Household {
+// toHousehold reads the household from the signup form, with the answers to
+// the questions of the site's intake form.
+func toHousehold(c echo.Context, form model.IntakeForm) model.Household {
 	h := model.Household{
 		Id:      ulid.Make().String(),
 		Head:    toPerson("hoh", c),
 		Members: []model.Person{},
+		Extra:   intakeAnswers(c, form, model.IntakeHousehold, "hoh"),
+	}
+	h.Head.Extra = intakeAnswers(c, form, model.IntakePerson, "hoh")
+	h.Income, h.IncomePeriod = toIncome(c)
+	h.Signature = toSignature(c)
+	h.CertifiedOn = time.Now().In(model.Location()).Format("2006-01-02")
+	for _, prefix := range memberPrefixes(c) {
+		m := toPerson(prefix, c)
+		m.Extra = intakeAnswers(c, form, model.IntakePerson, prefix)
+		h.Members = append(h.Members, m)
 	}
+	return h
+}
+
+// memberPrefixes returns the form field prefixes of the other members that
+// were filled in, in the order toHousehold adds them.
+func memberPrefixes(c echo.Context) []string {
+	var prefixes []string
 	for i := 0; i < 5; i++ {
 		prefix := fmt.Sprintf("person%d", i)
-		val := c.FormValue(prefix + "FirstName")
-		if val != "" {
-			h.Members = append(h.Members, toPerson(prefix, c))
+		if c.FormValue(prefix+"FirstName") != "" {
+			prefixes = append(prefixes, prefix)
 		}
 	}
-	return h
+	return prefixes
+}
+
+// signupField returns the form field for a household field reported by
+// Household.Validate, such as "hohZip" for "head.postalCode" or "person2Dob"
+// for "members.1.dob" when person1 was left empty. Intake answers, such as
+// "extra.snap" or "members.0.extra.veteran", are fields like "hohExtra.snap".
+// Errors for any part of the signature are keyed "signature".
+func signupField(field string, members []string) string {
+	if field == "signature" || strings.HasPrefix(field, "signature.") {
+		return "signature"
+	}
+	prefix, name := "hoh", strings.TrimPrefix(field, "head.")
+	if rest, ok := strings.CutPrefix(field, "members."); ok {
+		index, member, _ := strings.Cut(rest, ".")
+		i, err := strconv.Atoi(index)
+		if err != nil || i >= len(members) {
+			return field
+		}
+		prefix, name = members[i], member
+	}
+	if name == "postalCode" {
+		return prefix + "Zip"
+	}
+	return prefix + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// toIncome reads the household's income, written the standard way when it
+// can be read. The period is only kept with an income.
+func toIncome(c echo.Context) (income string, period string) {
+	income = strings.TrimSpace(c.FormValue("hohIncome"))
+	if income == "" {
+		return "", ""
+	}
+	if amount, err := model.ParseAmount(income); err == nil {
+		income = strconv.FormatFloat(amount, 'f', -1, 64)
+	}
+	return income, c.FormValue("hohIncomePeriod")
 }
 
 func toPerson(prefix string, c echo.Context) model.Person {
-	dob := fmt.Sprintf("%04s-%02s-%02s", c.FormValue(prefix+"DobYear"), c.FormValue(prefix+"DobMonth"),
-		c.FormValue(prefix+"DobDay"))
+	var dob string
+	year, month, day := c.FormValue(prefix+"DobYear"), c.FormValue(prefix+"DobMonth"), c.FormValue(prefix+"DobDay")
+	if year != "" || month != "" || day != "" {
+		dob = fmt.Sprintf("%04s-%02s-%02s", year, month, day)
+	}
 	return model.Person{
 		PersonCommon: model.PersonCommon{
+			Id:           ulid.Make().String(),
 			FirstName:    c.FormValue(prefix + "FirstName"),
 			LastName:     c.FormValue(prefix + "LastName"),
 			Email:        c.FormValue(prefix + "Email"),
@@ -59,27 +129,36 @@ func toPerson(prefix string, c echo.Context) model.Person {
 }
 
 func (p *SignupPage) POST(c echo.Context) error {
-	ctx := c.Request().Context()
+	ctx := db.WithActor(c.Request().Context(), "signup")
+	form, err := intakeForm(ctx, p.DB, c.FormValue("site"))
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load intake form: %v", err))
+	}
 
 	rb := GetResourceBundle(c)
-	errs := p.validate(c, rb)
+	errs := p.validate(c, rb, form)
 	if len(errs) == 0 {
-		household := toHousehold(c)
-
-		// save signup data
-		if err := p.DB.AddHousehold(ctx, household); err != nil {
+		// save signup data, unless the client should first confirm an
+		// earlier signup is theirs
+		match, err := p.save(ctx, c, form)
+		if err != nil {
 			return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save household: %v", err))
 		}
+		if match != nil {
+			page := Html5(pageAttrs(rb),
+				pageHead(rb, rb.Get("signup.title")),
+				Body_(
+					Div(Attr(a.Class("container my-5")),
+						Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
+						confirmDetails(c, rb, "/signup", match.Id),
+					)))
+			return c.HTML(http.StatusOK, string(page))
+		}
 
 		// success page
 		page :=
-			Html5_(
-				Head_(
-					Meta(Attr(a.Charset("UTF-8"))),
-					Meta(Attr(a.Name("viewport"), a.Content("width=device-width, initial-scale=1.0"))),
-					Title_(HTML(rb.Get("signup.title"))),
-					Link(Attr(a.Rel("stylesheet"), a.Href("https://maxcdn.bootstrapcdn.com/bootstrap/4.5.2/css/bootstrap.min.css"))),
-				),
+			Html5(pageAttrs(rb),
+				pageHead(rb, rb.Get("signup.title")),
 				Body_(
 					Div(Attr(a.Class("container my-5")),
 						Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
@@ -89,34 +168,125 @@ func (p *SignupPage) POST(c echo.Context) error {
 					)))
 		return c.HTML(200, string(page))
 	} else {
-		return p.getPage(c, errs)
+		return p.getPage(c, form, errs)
 	}
 }
 
-func (p *SignupPage) validate(c echo.Context, rb *ResourceBundle) ValidationErrors {
-	requiredFields := []string{"hohFirstName", "hohLastName", "hohDobMonth", "hohDobDay", "hohDobYear"}
-	errs := ValidationErrors{}
+// save stores the signup. A client who has signed up before is asked to
+// confirm that before anything is saved: save returns the earlier household
+// and the form comes back with confirm set to "update", which updates that
+// household, or "new", which saves a new one.
+func (p *SignupPage) save(ctx context.Context, c echo.Context, form model.IntakeForm) (*model.Household, error) {
+	household := toHousehold(c, form)
+	if c.FormValue("confirm") == "new" {
+		return nil, p.DB.AddHousehold(ctx, household)
+	}
+
+	matches, err := p.DB.FindHouseholdMatches(ctx, household)
+	if err != nil {
+		return nil, err
+	}
+	match, household := returningMatch(household, matches)
+	if match == nil {
+		return nil, p.DB.AddHousehold(ctx, household)
+	}
+	// the match is looked up again rather than trusting the submitted ID, so
+	// a signup can only update the household its details match
+	if c.FormValue("confirm") == "update" && c.FormValue("match") == match.Id {
+		return nil, p.DB.PutHousehold(ctx, match.Update(household))
+	}
+	return match, nil
+}
 
-	for _, f := range requiredFields {
-		val := c.FormValue(f)
-		if val == "" {
-			errs[f] = rb.Get("misc.fieldrequired")
+// returningMatch returns the stored household a signup may update, one whose
+// head has the same name and date of birth, or nil. A household that only has
+// the same phone number is not offered, since anyone who knows the number
+// could then learn that it is stored and overwrite it; the signup is returned
+// marked as its possible duplicate for staff to review instead.
+func returningMatch(household model.Household, matches []model.Household) (*model.Household, model.Household) {
+	match, ok := model.BestMatch(household, matches)
+	if !ok {
+		return nil, household
+	}
+	if !household.SameHead(match) {
+		household.DuplicateOf = match.Id
+		return nil, household
+	}
+	return &match, household
+}
+
+// confirmDetails asks a returning client to check the details they entered
+// and choose between updating their earlier signup and signing up as new.
+// The stored household's details are not shown, since anyone who knows a
+// client's phone number could otherwise see them.
+func confirmDetails(c echo.Context, rb *ResourceBundle, action string, matchID string) HTML {
+	household := toHousehold(c, model.IntakeForm{})
+	head := household.Head
+
+	fields := []HTML{Input(Attr(a.Type("hidden"), a.Name("match"), a.Value(matchID)))}
+	params, _ := c.FormParams()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != "confirm" && name != "match" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range params[name] {
+			fields = append(fields, Input(Attr(a.Type("hidden"), a.Name(name), a.Value(value))))
 		}
 	}
-	return errs
+
+	address := strings.Join(slices.DeleteFunc([]string{head.Street, head.City, head.PostalCode}, func(s string) bool { return s == "" }), ", ")
+	rows := []HTML{
+		Tr_(Th_(Text(rb.Get("misc.name"))), Td_(Text(head.FirstName+" "+head.LastName))),
+		Tr_(Th_(Text(rb.Get("misc.dob"))), Td_(Text(rb.FormatDOB(head.DOB)))),
+		Tr_(Th_(Text(rb.Get("misc.address"))), Td_(Text(address))),
+		Tr_(Th_(Text(rb.Get("misc.phone"))), Td_(Text(head.Phone))),
+		Tr_(Th_(Text(rb.Get("misc.email"))), Td_(Text(head.Email))),
+	}
+	for _, m := range household.Members {
+		rows = append(rows, Tr_(Th_(Text(optionLabel(relationshipOptions(rb), m.Relationship))),
+			Td_(Text(m.FirstName+" "+m.LastName))))
+	}
+
+	return Form(Attr(a.Action(action), a.Method("POST")),
+		append(fields,
+			H1(Attr(a.Class("text-center my-4")), Text(rb.Get("signup.confirm.title"))),
+			P(Attr(a.Class("lead")), Text(rb.Get("signup.confirm.intro"))),
+			Table(Attr(a.Class("table")), Tbody_(rows...)),
+			Div(Attr(a.Class("d-flex justify-content-between flex-wrap mt-4")),
+				Button(Attr(a.Type("submit"), a.Name("confirm"), a.Value("new"), a.Class("btn btn-outline-secondary btn-lg mb-2")),
+					Text(rb.Get("signup.confirm.new"))),
+				Button(Attr(a.Type("submit"), a.Name("confirm"), a.Value("update"), a.Class("btn btn-primary btn-lg mb-2")),
+					Text(rb.Get("signup.confirm.update"))),
+			),
+		)...)
+}
+
+// validate checks the signup with Household.Validate and the answers with
+// the intake form, keying the errors to the form's fields. Signing the
+// self-declaration of need is required to sign up.
+func (p *SignupPage) validate(c echo.Context, rb *ResourceBundle, form model.IntakeForm) ValidationErrors {
+	household := toHousehold(c, form)
+	errs := append(household.Validate(), form.ValidateAnswers(household)...)
+	if household.Signature == nil {
+		errs = append(errs, model.ValidationError{Field: "signature", Type: "missing", Message: "field_missing"})
+	}
+	members := memberPrefixes(c)
+	for i := range errs {
+		errs[i].Field = signupField(errs[i].Field, members)
+	}
+	return FormErrors(errs, rb)
 }
 
-func (p *SignupPage) getPage(c echo.Context, errs ValidationErrors) error {
+func (p *SignupPage) getPage(c echo.Context, form model.IntakeForm, errs ValidationErrors) error {
 	fb := &FormBuilder{Errs: errs, C: c}
 	rb := GetResourceBundle(c)
 	page :=
-		Html5_(
-			Head_(
-				Meta(Attr(a.Charset("UTF-8"))),
-				Meta(Attr(a.Name("viewport"), a.Content("width=device-width, initial-scale=1.0"))),
-				Title_(HTML(rb.Get("signup.title"))),
-				Link(Attr(a.Rel("stylesheet"), a.Href("https://maxcdn.bootstrapcdn.com/bootstrap/4.5.2/css/bootstrap.min.css"))),
-			),
+		Html5(pageAttrs(rb),
+			pageHead(rb, rb.Get("signup.title")),
 			Body_(
 				Div(Attr(a.Class("container my-5")),
 					Img(Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
@@ -124,35 +294,37 @@ func (p *SignupPage) getPage(c echo.Context, errs ValidationErrors) error {
 					H1(Attr(a.Class("text-center")), Text(rb.Get("signup.title"))),
 					P(Attr(a.Class("text-center")), Text(rb.Get("signup.intro"))),
 
-					Div(Attr(a.Class("text-center")),
-						A(Attr(a.Href("?lang=en")), Text("English")),
-						Text(" | "),
-						A(Attr(a.Href("?lang=es")), Text("Español")),
-					),
+					languageSwitcher(form.Id),
 
-					Form(Attr(a.Action("/signup"), a.Method("POST")), p.formBody(fb, rb, errs)...),
+					Form(Attr(a.Action("/signup"), a.Method("POST")), p.formBody(form, fb, rb, errs)...),
 				),
 			))
 	return c.HTML(200, string(page))
 }
 
-func (p *SignupPage) formBody(fb *FormBuilder, rb *ResourceBundle, errs ValidationErrors) []HTML {
+func (p *SignupPage) formBody(form model.IntakeForm, fb *FormBuilder, rb *ResourceBundle, errs ValidationErrors) []HTML {
 	h := []htmlgo.HTML{H2(Attr(a.Class("my-4")), Text(rb.Get("signup.hoh")))}
 	h = append(h, Input(Attr(a.Type("hidden"), a.Name("lang"), a.Value(rb.Lang))))
-	h = append(h, p.personForm("hoh", true, fb, rb, errs)...)
+	h = append(h, Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(form.Id))))
+	h = append(h, p.personForm("hoh", true, form, fb, rb, errs)...)
+	h = append(h, incomeFields(fb, rb))
 	h = append(h, H2(Attr(a.Class("my-4")), Text(rb.Get("signup.othermembers"))))
 	for i := 0; i < 5; i++ {
 		h = append(h, H5(Attr(a.Class("my-3")), Text(fmt.Sprintf("%s %d", rb.Get("misc.person"), i+1))))
-		h = append(h, p.personForm(fmt.Sprintf("person%d", i), false, fb, rb, errs)...)
+		h = append(h, p.personForm(fmt.Sprintf("person%d", i), false, form, fb, rb, errs)...)
 		h = append(h, Hr_())
 	}
+	h = append(h, signatureField(fb, rb))
 	h = append(h, Div(Attr(a.Class("text-center mt-4")),
 		Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("misc.submit"))),
 	))
 	return h
 }
 
-func (p *SignupPage) personForm(prefix string, headOfHousehold bool, fb *FormBuilder, rb *ResourceBundle, errs ValidationErrors) []HTML {
+// personForm is the fields for one person, followed by the site's intake
+// questions: for the head of household both the household and the person
+// questions, and for others the person questions.
+func (p *SignupPage) personForm(prefix string, headOfHousehold bool, form model.IntakeForm, fb *FormBuilder, rb *ResourceBundle, errs ValidationErrors) []HTML {
 	h := []htmlgo.HTML{
 		Div(Attr(a.Class("form-row")),
 			fb.InputDiv("col-md-6", prefix+"FirstName", rb.Get("misc.firstname")),
@@ -171,71 +343,153 @@ func (p *SignupPage) personForm(prefix string, headOfHousehold bool, fb *FormBui
 		))
 	}
 
-	monthClass, monthErrEl := fb.GetFormClassAndValidationElem(prefix + "DobMonth")
-	dayClass, dayErrEl := fb.GetFormClassAndValidationElem(prefix + "DobDay")
-	yearClass, yearErrEl := fb.GetFormClassAndValidationElem(prefix + "DobYear")
-
 	h = append(h, Div(Attr(a.Class("form-row")),
-		fb.SelectDiv("col-md-6", prefix+"Gender", rb.Get("misc.gender"), []ValueLabel{
-			{Value: "male", Label: rb.Get("misc.male")},
-			{Value: "female", Label: rb.Get("misc.female")},
-			{Value: "optout", Label: rb.Get("misc.prefernottosay")},
-		}),
-		Div(Attr(a.Class("form-group col-md-6")),
-			Label_(Text(rb.Get("misc.dob"))),
-			Div(Attr(a.Class("form-row")),
-				Div(Attr(a.Class("col")),
-					Select(Attr(a.Class(monthClass), a.Name(prefix+"DobMonth")),
-						fb.selectOptions(prefix+"DobMonth", monthValueLabels(rb.Get("misc.month")))...,
-					),
-					monthErrEl,
-				),
-				Div(Attr(a.Class("col")),
-					Select(Attr(a.Class(dayClass), a.Name(prefix+"DobDay")),
-						fb.selectOptions(prefix+"DobDay", dayValueLabels(rb.Get("misc.day")))...,
-					),
-					dayErrEl,
-				),
-				Div(Attr(a.Class("col")),
-					Select(Attr(a.Class(yearClass), a.Name(prefix+"DobYear")),
-						fb.selectOptions(prefix+"DobYear", yearValueLabels(rb.Get("misc.year")))...,
-					),
-					yearErrEl,
-				),
-			),
-		),
+		fb.SelectDiv("col-md-6", prefix+"Gender", rb.Get("misc.gender"), genderOptions(rb)),
+		dobField("col-md-6", prefix, fb, rb),
 	))
 
 	var col2 htmlgo.HTML
 	if headOfHousehold {
-		col2 = fb.SelectDiv("col-md-6", prefix+"Language", rb.Get("misc.primarylang"), []ValueLabel{
-			{Value: "english", Label: rb.Get("misc.english")},
-			{Value: "spanish", Label: rb.Get("misc.spanish")},
-			{Value: "other", Label: rb.Get("misc.other")},
-		})
+		col2 = fb.SelectDiv("col-md-6", prefix+"Language", rb.Get("misc.primarylang"), languageOptions(rb))
 	} else {
-		col2 = fb.SelectDiv("col-md-6", prefix+"Relationship", rb.Get("misc.relationship"), []ValueLabel{
-			{Value: "child", Label: rb.Get("misc.child")},
-			{Value: "grandchild", Label: rb.Get("misc.grandchild")},
-			{Value: "spouse", Label: rb.Get("misc.spouse")},
-			{Value: "parent", Label: rb.Get("misc.parent")},
-			{Value: "grandparent", Label: rb.Get("misc.grandparent")},
-			{Value: "sibling", Label: rb.Get("misc.sibling")},
-			{Value: "friend", Label: rb.Get("misc.friend")},
-			{Value: "other", Label: rb.Get("misc.other")},
-		})
+		col2 = fb.SelectDiv("col-md-6", prefix+"Relationship", rb.Get("misc.relationship"), relationshipOptions(rb))
 	}
 
 	h = append(h, Div(Attr(a.Class("form-row")),
-		fb.SelectDiv("col-md-6", prefix+"Race", rb.Get("misc.race"), []ValueLabel{
-			{Value: "white", Label: rb.Get("misc.race.white")},
-			{Value: "latino", Label: rb.Get("misc.race.latino")},
-			{Value: "black", Label: rb.Get("misc.race.black")},
-			{Value: "asian", Label: rb.Get("misc.race.asian")},
-			{Value: "other", Label: rb.Get("misc.other")},
-		}),
+		fb.SelectDiv("col-md-6", prefix+"Race", rb.Get("misc.race"), raceOptions(rb)),
 		col2,
 	))
 
+	if headOfHousehold {
+		h = append(h, intakeFields(prefix, form.Scoped(model.IntakeHousehold), fb, rb)...)
+	}
+	h = append(h, intakeFields(prefix, form.Scoped(model.IntakePerson), fb, rb)...)
+
 	return h
 }
+
+// incomeFields asks for the household's income and how often it is received.
+func incomeFields(fb *FormBuilder, rb *ResourceBundle) HTML {
+	return Div_(
+		Div(Attr(a.Class("form-row")),
+			fb.InputDiv("col-md-6", "hohIncome", rb.Get("signup.income")),
+			fb.SelectDiv("col-md-6", "hohIncomePeriod", rb.Get("signup.incomeperiod"), incomePeriodOptions(rb)),
+		),
+		P(Attr(a.Class("form-text text-muted mt-n2")), Text(rb.Get("signup.incomehelp"))),
+	)
+}
+
+// dobField is the date of birth as month, day and year selects. Errors for
+// the date as a whole are keyed prefix+"Dob".
+func dobField(class string, prefix string, fb *FormBuilder, rb *ResourceBundle) HTML {
+	selectClass, _ := fb.GetFormClassAndValidationElem(prefix + "Dob")
+	var errorEl HTML
+	if msg, ok := fb.Errs[prefix+"Dob"]; ok {
+		// the message is below all three selects rather than next to one, so
+		// it has to be shown explicitly
+		errorEl = Div(Attr(a.Class("invalid-feedback d-block")), Text(msg))
+	}
+
+	return Div(Attr(a.Class("form-group "+class)),
+		Label_(Text(rb.Get("misc.dob"))),
+		Div(Attr(a.Class("form-row")),
+			Div(Attr(a.Class("col")),
+				Select(Attr(a.Class(selectClass), a.Name(prefix+"DobMonth")),
+					fb.selectOptions(prefix+"DobMonth", monthValueLabels(rb.Get("misc.month")))...,
+				),
+			),
+			Div(Attr(a.Class("col")),
+				Select(Attr(a.Class(selectClass), a.Name(prefix+"DobDay")),
+					fb.selectOptions(prefix+"DobDay", dayValueLabels(rb.Get("misc.day")))...,
+				),
+			),
+			Div(Attr(a.Class("col")),
+				Select(Attr(a.Class(selectClass), a.Name(prefix+"DobYear")),
+					fb.selectOptions(prefix+"DobYear", yearValueLabels(rb.Get("misc.year")))...,
+				),
+			),
+		),
+		errorEl,
+	)
+}
+
+// languageSwitcher links to the form for site in each available language,
+// each named in its own language.
+func languageSwitcher(site string) HTML {
+	var links []HTML
+	for i, lang := range Languages {
+		if i > 0 {
+			links = append(links, Text(" | "))
+		}
+		query := url.Values{"lang": {lang}}
+		if site != "" {
+			query.Set("site", site)
+		}
+		links = append(links, A(Attr(a.Href("?"+query.Encode()), a.Lang(lang)), Text(resources[lang]["meta.name"])))
+	}
+	return Div(Attr(a.Class("text-center")), links...)
+}
+
+func genderOptions(rb *ResourceBundle) []ValueLabel {
+	return []ValueLabel{
+		{Value: "male", Label: rb.Get("misc.male")},
+		{Value: "female", Label: rb.Get("misc.female")},
+		{Value: "optout", Label: rb.Get("misc.prefernottosay")},
+	}
+}
+
+func languageOptions(rb *ResourceBundle) []ValueLabel {
+	return []ValueLabel{
+		{Value: "english", Label: rb.Get("misc.english")},
+		{Value: "spanish", Label: rb.Get("misc.spanish")},
+		{Value: "vietnamese", Label: rb.Get("misc.vietnamese")},
+		{Value: "arabic", Label: rb.Get("misc.arabic")},
+		{Value: "french", Label: rb.Get("misc.french")},
+		{Value: "nepali", Label: rb.Get("misc.nepali")},
+		{Value: "swahili", Label: rb.Get("misc.swahili")},
+		{Value: "other", Label: rb.Get("misc.other")},
+	}
+}
+
+func incomePeriodOptions(rb *ResourceBundle) []ValueLabel {
+	return []ValueLabel{
+		{Value: "monthly", Label: rb.Get("income.monthly")},
+		{Value: "weekly", Label: rb.Get("income.weekly")},
+		{Value: "biweekly", Label: rb.Get("income.biweekly")},
+		{Value: "yearly", Label: rb.Get("income.yearly")},
+	}
+}
+
+func relationshipOptions(rb *ResourceBundle) []ValueLabel {
+	return []ValueLabel{
+		{Value: "child", Label: rb.Get("misc.child")},
+		{Value: "grandchild", Label: rb.Get("misc.grandchild")},
+		{Value: "spouse", Label: rb.Get("misc.spouse")},
+		{Value: "parent", Label: rb.Get("misc.parent")},
+		{Value: "grandparent", Label: rb.Get("misc.grandparent")},
+		{Value: "sibling", Label: rb.Get("misc.sibling")},
+		{Value: "friend", Label: rb.Get("misc.friend")},
+		{Value: "other", Label: rb.Get("misc.other")},
+	}
+}
+
+func raceOptions(rb *ResourceBundle) []ValueLabel {
+	return []ValueLabel{
+		{Value: "white", Label: rb.Get("misc.race.white")},
+		{Value: "latino", Label: rb.Get("misc.race.latino")},
+		{Value: "black", Label: rb.Get("misc.race.black")},
+		{Value: "asian", Label: rb.Get("misc.race.asian")},
+		{Value: "other", Label: rb.Get("misc.other")},
+	}
+}
+
+// optionLabel returns the label for a stored option value, or the value
+// itself if it is not one of the options.
+func optionLabel(options []ValueLabel, value string) string {
+	for _, o := range options {
+		if o.Value == value {
+			return o.Label
+		}
+	}
+	return value
+}
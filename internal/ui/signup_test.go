@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"foodbank/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestConfirmDetailsKeepsForm(t *testing.T) {
+	form := url.Values{
+		"hohFirstName": {"Ana"}, "hohLastName": {"Lopez"}, "hohPhone": {"802-555-0100"},
+		"hohDobYear": {"1980"}, "hohDobMonth": {"3"}, "hohDobDay": {"4"},
+		"person0FirstName": {"Leo"}, "person0Relationship": {"child"},
+		"lang": {"en"}, "confirm": {"update"}, "match": {"forged"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	html := string(confirmDetails(c, Bundle("en"), "/signup", "h1"))
+	for _, want := range []string{
+		`name="hohPhone" value="802-555-0100"`, `name="person0FirstName" value="Leo"`,
+		`name="match" value="h1"`, "March 4, 1980", "Leo ", "Child",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %s in\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "forged") || strings.Count(html, `name="confirm"`) != 2 {
+		t.Errorf("confirm or match carried over from the form:\n%s", html)
+	}
+}
+
+func TestReturningMatch(t *testing.T) {
+	head := func(first, dob, phone string) model.Person {
+		return model.Person{PersonCommon: model.PersonCommon{FirstName: first, LastName: "Lopez", DOB: dob, Phone: phone}}
+	}
+	stored := model.Household{Id: "h1", Head: head("Ana", "1980-03-04", "802-555-0100")}
+
+	match, signup := returningMatch(model.Household{Head: head("ana", "1980-03-04", "")}, []model.Household{stored})
+	if match == nil || match.Id != "h1" || signup.DuplicateOf != "" {
+		t.Errorf("name and DOB: match %v, DuplicateOf %q", match, signup.DuplicateOf)
+	}
+
+	// someone else with the number must not be offered the household to update
+	match, signup = returningMatch(model.Household{Head: head("Eve", "1990-01-01", "8025550100")}, []model.Household{stored})
+	if match != nil {
+		t.Errorf("phone only: offered %s to update", match.Id)
+	}
+	if signup.DuplicateOf != "h1" {
+		t.Errorf("phone only: DuplicateOf = %q, want h1", signup.DuplicateOf)
+	}
+}
+
+func TestSignupValidate(t *testing.T) {
+	form := url.Values{
+		"hohFirstName": {"Ana"}, "hohLastName": {"Lopez"},
+		"hohDobYear": {"1990"}, "hohDobMonth": {"2"}, "hohDobDay": {"31"},
+		"hohPhone": {"555-01"}, "hohZip": {"0550"}, "hohEmail": {"ana@"},
+		// person0 is empty, so person1 is members.0
+		"person1FirstName": {"Leo"}, "person1DobYear": {"2999"}, "person1DobMonth": {"1"}, "person1DobDay": {"1"},
+		"signatureImage": {"data:image/png;base64,not base64"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	rb := Bundle("es")
+	errs := (&SignupPage{}).validate(c, rb, model.IntakeForm{})
+	want := map[string]string{
+		"hohDob":     rb.Get("validation.invalid_date"),
+		"hohPhone":   rb.Get("validation.invalid_phone"),
+		"hohZip":     rb.Get("validation.invalid_postal_code"),
+		"hohEmail":   rb.Get("validation.invalid_email"),
+		"person1Dob": rb.Get("validation.future_date"),
+		"signature":  rb.Get("validation.invalid_signature"),
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got errors %v, want %v", errs, want)
+	}
+	for field, msg := range want {
+		if errs[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, errs[field], msg)
+		}
+	}
+}
+
+func TestSignupIntakeAnswers(t *testing.T) {
+	intake := model.IntakeForm{Id: "site1", Fields: []model.IntakeField{
+		{Key: "snap", Type: model.IntakeSelect, Scope: model.IntakeHousehold, LabelKey: "intake.snap",
+			Options: []model.IntakeOption{{Value: "yes", LabelKey: "misc.yes"}, {Value: "no", LabelKey: "misc.no"}}},
+		{Key: "diet", Type: model.IntakeMultiSelect, Scope: model.IntakePerson, Label: "Diet",
+			Options: []model.IntakeOption{{Value: "halal", Label: "Halal"}, {Value: "vegetarian", Label: "Vegetarian"}}},
+		{Key: "veteran", Type: model.IntakeCheckbox, Scope: model.IntakePerson, Label: "Veteran", Required: true},
+	}}
+	form := url.Values{
+		"hohFirstName": {"Ana"}, "hohLastName": {"Lopez"},
+		"hohDobYear": {"1980"}, "hohDobMonth": {"3"}, "hohDobDay": {"4"},
+		"hohExtra.snap": {"maybe"}, "hohExtra.diet": {"halal", "vegetarian"}, "hohExtra.veteran": {"on"},
+		"person0FirstName": {"Leo"},
+		"signatureName":    {"Ana Lopez"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	h := toHousehold(c, intake)
+	if h.Head.Extra["diet"] != "halal,vegetarian" || h.Head.Extra["veteran"] != "yes" || h.Extra["snap"] != "maybe" {
+		t.Errorf("got household answers %v and head answers %v", h.Extra, h.Head.Extra)
+	}
+
+	rb := Bundle("en")
+	errs := (&SignupPage{}).validate(c, rb, intake)
+	want := map[string]string{
+		"hohExtra.snap":        rb.Get("validation.invalid_option"),
+		"person0Extra.veteran": rb.Get("validation.field_missing"),
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got errors %v, want %v", errs, want)
+	}
+	for field, msg := range want {
+		if errs[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, errs[field], msg)
+		}
+	}
+}
+
+func TestSignupSignature(t *testing.T) {
+	form := url.Values{
+		"hohFirstName": {"Ana"}, "hohLastName": {"Lopez"},
+		"hohDobYear": {"1980"}, "hohDobMonth": {"3"}, "hohDobDay": {"4"},
+	}
+	newContext := func(form url.Values) echo.Context {
+		req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	rb := Bundle("en")
+	errs := (&SignupPage{}).validate(newContext(form), rb, model.IntakeForm{})
+	if len(errs) != 1 || errs["signature"] != rb.Get("validation.field_missing") {
+		t.Errorf("unsigned: got errors %v", errs)
+	}
+
+	form.Set("signatureImage", "data:image/png;base64,iVBORw0KGgo=")
+	c := newContext(form)
+	if errs := (&SignupPage{}).validate(c, rb, model.IntakeForm{}); len(errs) != 0 {
+		t.Errorf("drawn: got errors %v", errs)
+	}
+	s := toHousehold(c, model.IntakeForm{}).Signature
+	if s == nil || !s.Drawn() || s.Attestation != model.AttestationVersion || s.SignedAt.IsZero() {
+		t.Fatalf("got signature %+v", s)
+	}
+	if html := string(signatureBlock(s, rb)); !strings.Contains(html, `src="data:image/png;base64,iVBORw0KGgo="`) {
+		t.Errorf("signature image not shown:\n%s", html)
+	}
+}
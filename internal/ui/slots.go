@@ -0,0 +1,385 @@
+package ui
+
+import (
+	"fmt"
+	"foodbank/internal/db"
+	"foodbank/internal/model"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+var bookingClasses = map[string]string{
+	model.BookingBooked:    "badge-info",
+	model.BookingVisited:   "badge-success",
+	model.BookingNoShow:    "badge-danger",
+	model.BookingCancelled: "badge-secondary",
+}
+
+func bookingStatus(b model.Booking, rb *ResourceBundle) HTML {
+	return Span(Attr(a.Class("badge "+bookingClasses[b.Status])), Text(rb.Get("booking.status."+b.Status)))
+}
+
+func weekdayName(weekday int, rb *ResourceBundle) string {
+	return rb.Get(fmt.Sprintf("date.weekday.%d", weekday))
+}
+
+func weekdayOptions(rb *ResourceBundle) []ValueLabel {
+	options := make([]ValueLabel, 7)
+	for i := range options {
+		options[i] = ValueLabel{Value: strconv.Itoa(i), Label: weekdayName(i, rb)}
+	}
+	return options
+}
+
+// slotDate writes a "2006-01-02" date the language's way, with its day of the
+// week.
+func slotDate(date string, rb *ResourceBundle) string {
+	t, err := time.ParseInLocation("2006-01-02", date, model.Location())
+	if err != nil {
+		return date
+	}
+	return weekdayName(int(t.Weekday()), rb) + ", " + rb.FormatDate(t)
+}
+
+func slotTimes(start string, end string) string {
+	return start + "–" + end
+}
+
+// slotOptions offers the slots held on date that have places left, saying
+// how many.
+func slotOptions(slots []model.PickupSlot, bookings []model.Booking, date string, rb *ResourceBundle) []ValueLabel {
+	var options []ValueLabel
+	for _, s := range slots {
+		left := s.Remaining(bookings)
+		if !s.On(date) || left == 0 {
+			continue
+		}
+		options = append(options, ValueLabel{Value: s.Id, Label: slotTimes(s.Start, s.End) + " " + rb.Getf("slots.left", Args{"count": left})})
+	}
+	return options
+}
+
+func siteNames(sites []model.FoodBank) map[string]string {
+	names := make(map[string]string, len(sites))
+	for _, s := range sites {
+		names[s.Id] = s.Name
+	}
+	return names
+}
+
+// bookingErrors converts the errors of a booking to form errors, where the
+// site is chosen as "site".
+func bookingErrors(errs model.ValidationErrors, rb *ResourceBundle) ValidationErrors {
+	out := FormErrors(errs, rb)
+	if msg, ok := out["foodBankId"]; ok {
+		out["site"] = msg
+	}
+	return out
+}
+
+// PickupSlotPage lets staff set the weekly pickup slots of each site: the
+// time windows clients can book, and how many households each takes.
+type PickupSlotPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *PickupSlotPage) GET(c echo.Context) error {
+	return p.getPage(c, ValidationErrors{})
+}
+
+// POST adds a slot to the site.
+func (p *PickupSlotPage) POST(c echo.Context) error {
+	weekday, err := strconv.Atoi(c.FormValue("weekday"))
+	if err != nil {
+		weekday = -1
+	}
+	capacity, err := strconv.Atoi(c.FormValue("capacity"))
+	if err != nil {
+		capacity = 0
+	}
+	slot := model.PickupSlot{
+		Id:         ulid.Make().String(),
+		FoodBankId: c.FormValue("site"),
+		Weekday:    weekday,
+		Start:      c.FormValue("start"),
+		End:        c.FormValue("end"),
+		Capacity:   capacity,
+	}
+	if errs := slot.Validate(); errs.HasErrors() {
+		return p.getPage(c, bookingErrors(errs, GetResourceBundle(c)))
+	}
+
+	if err := p.DB.PutPickupSlot(c.Request().Context(), slot); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to save slot: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/slots?"+url.Values{"site": {slot.FoodBankId}}.Encode())
+}
+
+// Delete removes a slot. Bookings already made for it stay on the roster.
+func (p *PickupSlotPage) Delete(c echo.Context) error {
+	if err := p.DB.DeletePickupSlot(c.Request().Context(), c.Param("id")); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to delete slot: %v", err))
+	}
+	return c.Redirect(http.StatusSeeOther, "/admin/slots?"+url.Values{"site": {c.FormValue("site")}}.Encode())
+}
+
+func (p *PickupSlotPage) getPage(c echo.Context, errs ValidationErrors) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	site := c.FormValue("site")
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	var slots []model.PickupSlot
+	if site != "" {
+		if slots, err = p.DB.GetPickupSlots(ctx, site); err != nil {
+			return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load slots: %v", err))
+		}
+	}
+
+	rows := make([]HTML, len(slots))
+	for i, s := range slots {
+		rows[i] = Tr_(
+			Td_(Text(weekdayName(s.Weekday, rb))),
+			Td_(Text(slotTimes(s.Start, s.End))),
+			Td_(Text(s.Capacity)),
+			Td_(Form(Attr(a.Class("d-inline"), a.Action(fmt.Sprintf("/admin/slots/%s/delete", s.Id)), a.Method("POST")),
+				Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(site))),
+				Button(Attr(a.Class("btn btn-sm btn-outline-danger"), a.Type("submit"),
+					confirmClick(rb.Get("slots.confirmdelete"))), Text(rb.Get("misc.delete"))))),
+		)
+	}
+
+	fb := &FormBuilder{Errs: errs, C: c}
+	page := StaffPage(rb, rb.Get("slots.title"),
+		H1_(Text(rb.Get("slots.title"))),
+		P_(Text(rb.Get("slots.intro"))),
+		Form(Attr(a.Action("/admin/slots"), a.Method("GET")),
+			Div(Attr(a.Class("form-row align-items-end")),
+				fb.SelectDiv("col-md-6", "site", rb.Get("reports.site"), siteOptions(sites, rb)),
+				Div(Attr(a.Class("form-group col-md-6")),
+					Button(Attr(a.Class("btn btn-secondary"), a.Type("submit")), Text(rb.Get("slots.show")))),
+			),
+		),
+		Table(Attr(a.Class("table table-striped")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("slots.weekday"))), Th_(Text(rb.Get("slots.time"))),
+				Th_(Text(rb.Get("slots.capacity"))), Th_(),
+			)),
+			Tbody_(rows...)),
+		H2(Attr(a.Class("my-4")), Text(rb.Get("slots.new"))),
+		Form(Attr(a.Action("/admin/slots"), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(site))),
+			Div(Attr(a.Class("form-row")),
+				fb.SelectDiv("col-md-3", "weekday", rb.Get("slots.weekday"), weekdayOptions(rb)),
+				fb.TypedInputDiv("col-md-3", "time", "start", rb.Get("slots.start")),
+				fb.TypedInputDiv("col-md-3", "time", "end", rb.Get("slots.end")),
+				fb.TypedInputDiv("col-md-3", "number", "capacity", rb.Get("slots.capacity")),
+			),
+			Button(Attr(a.Class("btn btn-primary"), a.Type("submit")), Text(rb.Get("misc.save"))),
+		),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
+
+// errorAlert shows an error that belongs to no field of the form, or nothing.
+func errorAlert(msg string) HTML {
+	if msg == "" {
+		return ""
+	}
+	return Div(Attr(a.Class("alert alert-danger")), Text(msg))
+}
+
+// slotPicker is the form that chooses a site and a date to book, shown
+// again with the day's slots once both are chosen.
+func slotPicker(action string, sites []model.FoodBank, fb *FormBuilder, rb *ResourceBundle) HTML {
+	return Form(Attr(a.Action(action), a.Method("GET")),
+		Div(Attr(a.Class("form-row align-items-end")),
+			fb.SelectDiv("col-md-5", "site", rb.Get("reports.site"), siteOptions(sites, rb)),
+			fb.TypedInputDiv("col-md-4", "date", "date", rb.Get("booking.date")),
+			Div(Attr(a.Class("form-group col-md-3")),
+				Button(Attr(a.Class("btn btn-secondary"), a.Type("submit")), Text(rb.Get("booking.showtimes")))),
+		),
+	)
+}
+
+// slotsOn loads the slots of site and the bookings made for them on date,
+// or nothing until both are chosen.
+func slotsOn(c echo.Context, database *db.FirestoreDB, site string, date string) ([]model.PickupSlot, []model.Booking, error) {
+	if site == "" || date == "" {
+		return nil, nil, nil
+	}
+	ctx := c.Request().Context()
+	slots, err := database.GetPickupSlots(ctx, site)
+	if err != nil {
+		return nil, nil, err
+	}
+	bookings, err := database.GetBookings(ctx, site, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slots, bookings, nil
+}
+
+// slotChoice asks for one of the day's open slots, or says there are none.
+func slotChoice(slots []model.PickupSlot, bookings []model.Booking, date string, fb *FormBuilder, rb *ResourceBundle) HTML {
+	options := slotOptions(slots, bookings, date, rb)
+	if len(options) == 0 {
+		return Div(Attr(a.Class("alert alert-warning")), Text(rb.Getf("booking.noslots", Args{"date": slotDate(date, rb)})))
+	}
+	return fb.SelectDiv("", "slot", rb.Getf("booking.slot", Args{"date": slotDate(date, rb)}), options)
+}
+
+// bookingsTable lists a household's bookings, with buttons to cancel those
+// still booked or mark them no-shows.
+func bookingsTable(bookings []model.Booking, sites map[string]string, back string, rb *ResourceBundle) HTML {
+	if len(bookings) == 0 {
+		return P(Attr(a.Class("text-muted")), Text(rb.Get("booking.none")))
+	}
+	rows := make([]HTML, len(bookings))
+	for i, b := range bookings {
+		rows[i] = Tr_(
+			Td_(Text(slotDate(b.Date, rb))),
+			Td_(Text(slotTimes(b.Start, b.End))),
+			Td_(Text(sites[b.FoodBankId])),
+			Td_(bookingStatus(b, rb)),
+			Td_(bookingActions(b, back, rb)),
+		)
+	}
+	return Div_(
+		P_(Text(rb.Getf("booking.noshows", Args{"count": model.NoShows(bookings)}))),
+		Table(Attr(a.Class("table table-sm")),
+			Thead_(Tr_(
+				Th_(Text(rb.Get("booking.date"))), Th_(Text(rb.Get("slots.time"))),
+				Th_(Text(rb.Get("reports.site"))), Th_(Text(rb.Get("booking.status"))), Th_(),
+			)),
+			Tbody_(rows...)),
+	)
+}
+
+// bookingActions are the buttons to cancel a booking or mark it a no-show,
+// returning to back. A no-show can only be marked once the day has come.
+func bookingActions(b model.Booking, back string, rb *ResourceBundle) HTML {
+	today := time.Now().In(model.Location()).Format("2006-01-02")
+	var buttons []HTML
+	if b.CanBecome(model.BookingNoShow) && b.Date <= today {
+		buttons = append(buttons, bookingAction(b, "noshow", back, rb.Get("booking.marknoshow"), "", "btn-outline-danger"))
+	}
+	if b.CanBecome(model.BookingCancelled) {
+		buttons = append(buttons, bookingAction(b, "cancel", back, rb.Get("booking.cancel"), rb.Get("booking.confirmcancel"), "btn-outline-secondary"))
+	}
+	return Span_(buttons...)
+}
+
+func bookingAction(b model.Booking, action string, back string, label string, confirm string, class string) HTML {
+	attrs := []a.Attribute{a.Class("btn btn-sm " + class), a.Type("submit")}
+	if confirm != "" {
+		attrs = append(attrs, confirmClick(confirm))
+	}
+	return Form(Attr(a.Class("d-inline mr-1"), a.Action(fmt.Sprintf("/booking/%s/%s", b.Id, action)), a.Method("POST")),
+		Input(Attr(a.Type("hidden"), a.Name("back"), a.Value(back))),
+		Button(attrs, Text(label)),
+	)
+}
+
+// BookingPage is where staff book a pickup slot for a household and cancel
+// bookings or mark them no-shows.
+type BookingPage struct {
+	DB *db.FirestoreDB
+}
+
+func (p *BookingPage) GET(c echo.Context) error {
+	return p.getPage(c, ValidationErrors{})
+}
+
+// POST books the chosen slot for the household.
+func (p *BookingPage) POST(c echo.Context) error {
+	rb := GetResourceBundle(c)
+	id := c.Param("id")
+	booking := model.Booking{
+		Id:          ulid.Make().String(),
+		SlotId:      c.FormValue("slot"),
+		Date:        c.FormValue("date"),
+		HouseholdId: id,
+		Status:      model.BookingBooked,
+	}
+	if errs := booking.Validate(); errs.HasErrors() {
+		return p.getPage(c, bookingErrors(errs, rb))
+	}
+	errs, err := p.DB.BookPickup(c.Request().Context(), booking)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to book slot: %v", err))
+	}
+	if errs.HasErrors() {
+		return p.getPage(c, bookingErrors(errs, rb))
+	}
+	return c.Redirect(http.StatusSeeOther, "/household/"+id)
+}
+
+// Action cancels a booking or marks it a no-show, then returns to the page
+// it was done from.
+func (p *BookingPage) Action(c echo.Context) error {
+	var status string
+	switch c.Param("action") {
+	case "cancel":
+		status = model.BookingCancelled
+	case "noshow":
+		status = model.BookingNoShow
+	default:
+		return c.HTML(http.StatusBadRequest, "Unknown action")
+	}
+	if err := p.DB.SetBookingStatus(c.Request().Context(), c.Param("id"), status); err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to %s booking: %v", c.Param("action"), err))
+	}
+	back := c.FormValue("back")
+	if !strings.HasPrefix(back, "/") || strings.HasPrefix(back, "//") {
+		back = "/roster"
+	}
+	return c.Redirect(http.StatusSeeOther, back)
+}
+
+func (p *BookingPage) getPage(c echo.Context, errs ValidationErrors) error {
+	ctx := c.Request().Context()
+	rb := GetResourceBundle(c)
+	household, err := p.DB.GetHouseholdByID(ctx, c.Param("id"))
+	if err != nil {
+		return c.HTML(http.StatusNotFound, fmt.Sprintf("Household not found: %v", err))
+	}
+	sites, err := p.DB.GetFoodBanks(ctx)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load sites: %v", err))
+	}
+	site, date := c.FormValue("site"), c.FormValue("date")
+	slots, bookings, err := slotsOn(c, p.DB, site, date)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, fmt.Sprintf("Failed to load slots: %v", err))
+	}
+
+	action := fmt.Sprintf("/household/%s/book", household.Id)
+	fb := &FormBuilder{Errs: errs, C: c}
+	var book HTML
+	if site != "" && date != "" {
+		book = Form(Attr(a.Action(action), a.Method("POST")),
+			Input(Attr(a.Type("hidden"), a.Name("site"), a.Value(site))),
+			Input(Attr(a.Type("hidden"), a.Name("date"), a.Value(date))),
+			slotChoice(slots, bookings, date, fb, rb),
+			Button(Attr(a.Class("btn btn-primary btn-lg"), a.Type("submit")), Text(rb.Get("booking.book"))),
+		)
+	}
+	page := StaffPage(rb, rb.Get("booking.title"),
+		H1_(Text(rb.Getf("booking.heading", Args{"name": household.Head.FirstName + " " + household.Head.LastName}))),
+		slotPicker(action, sites, fb, rb),
+		book,
+		P(Attr(a.Class("mt-4")), A(Attr(a.Href("/household/"+household.Id)), Text(rb.Get("misc.view")))),
+	)
+	return c.HTML(http.StatusOK, string(page))
+}
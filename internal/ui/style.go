@@ -2,9 +2,9 @@ package ui
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/julvo/htmlgo"
+	a "github.com/julvo/htmlgo/attributes"
 )
 
 func FontScalingStyle(scale string) htmlgo.HTML {
@@ -19,11 +19,39 @@ func FontScalingStyle(scale string) htmlgo.HTML {
 	`, scale, scale)))
 }
 
-func FormatDOB(dob string) string {
-	dobTime, err := time.Parse("2006-01-02", dob)
-	fmt.Printf("parsing DOB: dob=%s err=%v out=%v\n", dob, err, dobTime)
-	if err == nil {
-		return dobTime.Format("Jan 2, 2006")
+// StaffPage wraps body in the standard page chrome used by the staff pages.
+func StaffPage(rb *ResourceBundle, title string, body ...htmlgo.HTML) htmlgo.HTML {
+	content := append([]htmlgo.HTML{
+		htmlgo.Img(htmlgo.Attr(a.Src("/static/img/mend-logo.png"), a.Alt("Logo"), a.Width("300"), a.Class("mb-2"))),
+	}, body...)
+	return htmlgo.Html5(pageAttrs(rb),
+		pageHead(rb, title),
+		htmlgo.Body_(
+			FontScalingStyle("1.1rem"),
+			htmlgo.Div(htmlgo.Attr(a.Class("container my-5")), content...),
+		))
+}
+
+// pageAttrs sets the page's language and text direction.
+func pageAttrs(rb *ResourceBundle) []a.Attribute {
+	return htmlgo.Attr(a.Lang(rb.Lang), a.Dir(rb.Dir()))
+}
+
+func pageHead(rb *ResourceBundle, title string) htmlgo.HTML {
+	return htmlgo.Head_(
+		htmlgo.Meta(htmlgo.Attr(a.Charset("UTF-8"))),
+		htmlgo.Meta(htmlgo.Attr(a.Name("viewport"), a.Content("width=device-width, initial-scale=1.0"))),
+		htmlgo.Title_(htmlgo.Text(title)),
+		htmlgo.Link(htmlgo.Attr(a.Rel("stylesheet"), a.Href(bootstrapCSS(rb)))),
+	)
+}
+
+// bootstrapCSS returns the Bootstrap stylesheet for the page's text
+// direction. Bootstrap 4 has no right-to-left support of its own, so right to
+// left pages use the RTLCSS build of Bootstrap 4.5.
+func bootstrapCSS(rb *ResourceBundle) string {
+	if rb.Dir() == "rtl" {
+		return "https://cdn.rtlcss.com/bootstrap/v4.5.3/css/bootstrap.min.css"
 	}
-	return dob
+	return "https://maxcdn.bootstrapcdn.com/bootstrap/4.5.2/css/bootstrap.min.css"
 }
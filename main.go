@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"foodbank/internal/api"
 	"foodbank/internal/db"
+	"foodbank/internal/fieldcrypt"
+	"foodbank/internal/middleware"
+	"foodbank/internal/scheduler"
 	"foodbank/internal/ui"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/labstack/echo/v4"
@@ -25,6 +31,7 @@ func main() {
 	e := echo.New()
 	e.Use(echomid.Logger())
 	e.Use(echomid.Recover())
+	e.Use(middleware.KioskMiddleware("/kiosk"))
 
 	e.Static("/static", "static")
 
@@ -38,6 +45,31 @@ func main() {
 	defer firestoreClient.Close()
 
 	dbInstance := db.NewFirestoreDB(firestoreClient)
+	if path := os.Getenv("FIELD_KEY_FILE"); path != "" {
+		keys, err := fieldcrypt.LoadKeyFile(path)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load field encryption keys")
+		}
+		dbInstance.Cipher = fieldcrypt.New(keys)
+		go func() {
+			n, err := dbInstance.Reencrypt(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to re-encrypt stored data")
+				return
+			}
+			log.Info().Int("count", n).Str("key", keys.CurrentKeyID()).Msg("Re-encrypted stored data")
+		}()
+	} else {
+		log.Warn().Msg("FIELD_KEY_FILE not set, personal data will be stored unencrypted")
+	}
+	go func() {
+		n, err := dbInstance.ReindexHouseholds(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to index households for signup matching")
+			return
+		}
+		log.Info().Int("count", n).Msg("Indexed households for signup matching")
+	}()
 
 	// Define routes
 	e.GET("/", func(c echo.Context) error {
@@ -50,10 +82,155 @@ func main() {
 
 	e.GET("/signup", signupPage.GET)
 	e.POST("/signup", signupPage.POST)
+
+	kioskPage := &ui.KioskPage{
+		SignupPage: ui.SignupPage{DB: dbInstance},
+		Timeout:    time.Duration(envInt("KIOSK_TIMEOUT_SECONDS", 120)) * time.Second,
+		ResetAfter: time.Duration(envInt("KIOSK_RESET_SECONDS", 15)) * time.Second,
+	}
+	e.GET("/kiosk", kioskPage.GET)
+	e.POST("/kiosk", kioskPage.POST)
+	e.GET("/kiosk/start", kioskPage.Start)
+	e.GET("/kiosk/exit", kioskPage.Exit, middleware.AuthMiddleware)
+
 	e.GET("/households", householdListPage.GET)
 	e.GET("/household/:id", householdDetailPage.GET)
+	e.GET("/household/:id/print", householdDetailPage.Print, middleware.AuthMiddleware)
+	e.POST("/household/:id/erase", householdDetailPage.Erase, middleware.AuthMiddleware)
+	e.POST("/household/:id/reviewed", householdDetailPage.Reviewed, middleware.AuthMiddleware)
+
+	checkInPage := &ui.CheckInPage{DB: dbInstance}
+	e.GET("/checkin", checkInPage.GET, middleware.AuthMiddleware)
+	e.GET("/checkin/:id", checkInPage.Household, middleware.AuthMiddleware)
+	e.POST("/checkin/:id", checkInPage.POST, middleware.AuthMiddleware)
+
+	bookingPage := &ui.BookingPage{DB: dbInstance}
+	e.GET("/household/:id/book", bookingPage.GET, middleware.AuthMiddleware)
+	e.POST("/household/:id/book", bookingPage.POST, middleware.AuthMiddleware)
+	e.POST("/booking/:id/:action", bookingPage.Action, middleware.AuthMiddleware)
+	rosterPage := &ui.RosterPage{DB: dbInstance}
+	e.GET("/roster", rosterPage.GET, middleware.AuthMiddleware)
+	queuePage := &ui.QueuePage{DB: dbInstance}
+	e.GET("/queue", queuePage.GET, middleware.AuthMiddleware)
+	e.POST("/queue/next", queuePage.Next, middleware.AuthMiddleware)
+	e.POST("/queue/:id/:action", queuePage.Action, middleware.AuthMiddleware)
+	e.GET("/queue/display", queuePage.Display)
+	publicBookingPage := &ui.PublicBookingPage{DB: dbInstance}
+	e.GET("/book", publicBookingPage.GET)
+	e.POST("/book", publicBookingPage.POST)
+	e.GET("/book/:id", publicBookingPage.Booking)
+	e.POST("/book/:id/cancel", publicBookingPage.Cancel)
+
+	recertificationPage := &ui.RecertificationPage{DB: dbInstance}
+	e.GET("/recertification", recertificationPage.GET, middleware.AuthMiddleware)
+	recertifyPage := &ui.RecertifyPage{DB: dbInstance}
+	e.GET("/household/:id/recertify", recertifyPage.GET, middleware.AuthMiddleware)
+	e.POST("/household/:id/recertify", recertifyPage.POST, middleware.AuthMiddleware)
+
+	purgeAfter := deletedHouseholdRetention()
+	deletedHouseholdsPage := &ui.DeletedHouseholdsPage{DB: dbInstance, PurgeAfter: purgeAfter}
+	e.GET("/households/deleted", deletedHouseholdsPage.GET, middleware.AuthMiddleware)
+	e.POST("/households/deleted/:id/restore", deletedHouseholdsPage.Restore, middleware.AuthMiddleware)
+	go scheduler.Every(db.WithActor(ctx, "retention"), time.Hour, "purge deleted households", func(ctx context.Context) error {
+		purged, err := dbInstance.PurgeDeletedHouseholds(ctx, time.Now().Add(-purgeAfter))
+		if purged > 0 {
+			log.Info().Int("count", purged).Msg("Purged deleted households")
+		}
+		return err
+	})
+
+	exportPage := &ui.ExportPage{DB: dbInstance}
+	e.GET("/export", exportPage.GET, middleware.AuthMiddleware)
+	e.GET("/export/download", exportPage.Download, middleware.AuthMiddleware)
+
+	tefapReportPage := &ui.TEFAPReportPage{DB: dbInstance}
+	e.GET("/reports/tefap", tefapReportPage.GET, middleware.AuthMiddleware)
+	demographicsPage := &ui.DemographicsPage{DB: dbInstance}
+	e.GET("/reports/demographics", demographicsPage.GET, middleware.AuthMiddleware)
+
+	importPage := &ui.ImportPage{DB: dbInstance}
+	e.GET("/import", importPage.GET, middleware.AuthMiddleware)
+	e.POST("/import", importPage.POST, middleware.AuthMiddleware)
+
+	// Admin pages
+	apiTokenPage := &ui.APITokenPage{DB: dbInstance}
+	admin := e.Group("/admin", middleware.AuthMiddleware)
+	admin.GET("/tokens", apiTokenPage.GET)
+	admin.POST("/tokens", apiTokenPage.POST)
+	admin.POST("/tokens/:id/revoke", apiTokenPage.Revoke)
+
+	retentionPage := &ui.RetentionPage{DB: dbInstance, Months: envInt("RETENTION_MONTHS", 24)}
+	admin.GET("/retention", retentionPage.GET)
+	admin.POST("/retention", retentionPage.POST)
+
+	intakeFormPage := &ui.IntakeFormPage{DB: dbInstance}
+	admin.GET("/intake", intakeFormPage.GET)
+	admin.POST("/intake", intakeFormPage.POST)
+
+	povertyGuidelinePage := &ui.PovertyGuidelinePage{DB: dbInstance}
+	admin.GET("/guidelines", povertyGuidelinePage.GET)
+	admin.POST("/guidelines", povertyGuidelinePage.POST)
+	admin.POST("/guidelines/:id/delete", povertyGuidelinePage.Delete)
+
+	pickupSlotPage := &ui.PickupSlotPage{DB: dbInstance}
+	admin.GET("/slots", pickupSlotPage.GET)
+	admin.POST("/slots", pickupSlotPage.POST)
+	admin.POST("/slots/:id/delete", pickupSlotPage.Delete)
+
+	reportScheduler := &scheduler.Scheduler{DB: dbInstance, Sender: reportSender(), Interval: time.Minute}
+	go reportScheduler.Run(ctx)
+	reportSchedulePage := &ui.ReportSchedulePage{DB: dbInstance, Scheduler: reportScheduler}
+	admin.GET("/schedules", reportSchedulePage.GET)
+	admin.POST("/schedules", reportSchedulePage.POST)
+	admin.POST("/schedules/:id/:action", reportSchedulePage.Action)
+
+	// JSON API
+	e.GET("/api/v1/openapi.json", api.ServeOpenAPI)
+	e.GET("/api/docs", func(c echo.Context) error {
+		return c.File("static/api-docs.html")
+	})
+	apiV1 := e.Group("/api/v1", middleware.APIAuthMiddleware(dbInstance, "/api/v1"))
+	(&api.API{DB: dbInstance}).Register(apiV1)
 
 	// Start server
 	log.Info().Msg("Starting server on :8080")
 	e.Logger.Fatal(e.Start(":8080"))
 }
+
+// deletedHouseholdRetention is how long deleted households can be restored,
+// set in days by DELETED_HOUSEHOLD_RETENTION_DAYS (default 30).
+func deletedHouseholdRetention() time.Duration {
+	return time.Duration(envInt("DELETED_HOUSEHOLD_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// envInt reads a positive integer setting from the environment.
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		log.Fatal().Str(name, v).Msg("Setting must be a positive number")
+	}
+	return n
+}
+
+// reportSender delivers scheduled reports by SMTP when REPORT_SMTP_ADDR is
+// set, otherwise into REPORT_DROP_DIR (default tmp/reports) for testing.
+func reportSender() scheduler.Sender {
+	if addr := os.Getenv("REPORT_SMTP_ADDR"); addr != "" {
+		return &scheduler.SMTPSender{
+			Addr:     addr,
+			From:     os.Getenv("REPORT_SMTP_FROM"),
+			Username: os.Getenv("REPORT_SMTP_USER"),
+			Password: os.Getenv("REPORT_SMTP_PASSWORD"),
+		}
+	}
+	dir := os.Getenv("REPORT_DROP_DIR")
+	if dir == "" {
+		dir = "tmp/reports"
+	}
+	log.Info().Str("dir", dir).Msg("REPORT_SMTP_ADDR not set, scheduled reports will be written to a directory")
+	return &scheduler.DirSender{Dir: dir}
+}